@@ -0,0 +1,275 @@
+package openai
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrAliasConflict is returned by SwapAlias when the alias no longer points to
+// the expected target
+var ErrAliasConflict = errors.New("alias changed concurrently")
+
+// AliasStore maps application-level names, e.g. "support-bot-prod", to model
+// IDs, fine-tuned models or assistant IDs, so a deployment flips an alias
+// instead of editing code. Implementations must be safe for concurrent use.
+type AliasStore interface {
+	// Alias returns the target of name, false when the alias does not exist
+	Alias(ctx context.Context, name string) (string, bool, error)
+	SetAlias(ctx context.Context, name, target string) error
+
+	// SwapAlias points name to target only if it still points to old, ""
+	// meaning that the alias must not exist. It fails with ErrAliasConflict
+	// otherwise.
+	SwapAlias(ctx context.Context, name, old, target string) error
+}
+
+// ResolveAlias returns the target of name, or name itself when it is not an
+// alias, so code can take either a model or an alias
+func ResolveAlias(ctx context.Context, store AliasStore, name string) (string, error) {
+	target, ok, err := store.Alias(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("alias lookup failed: %w", err)
+	}
+	if !ok {
+		return name, nil
+	}
+	return target, nil
+}
+
+// MapAliasStore is an in-process AliasStore
+type MapAliasStore struct {
+	mu      sync.RWMutex
+	aliases map[string]string
+}
+
+// NewMapAliasStore returns an empty in-process alias store
+func NewMapAliasStore() *MapAliasStore {
+	return &MapAliasStore{aliases: map[string]string{}}
+}
+
+// Alias returns the target of name
+func (s *MapAliasStore) Alias(_ context.Context, name string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	target, ok := s.aliases[name]
+	return target, ok, nil
+}
+
+// SetAlias points name to target
+func (s *MapAliasStore) SetAlias(_ context.Context, name, target string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.aliases[name] = target
+	return nil
+}
+
+// SwapAlias points name to target if it still points to old
+func (s *MapAliasStore) SwapAlias(_ context.Context, name, old, target string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.aliases[name] != old {
+		return fmt.Errorf("%w: %s", ErrAliasConflict, name)
+	}
+	s.aliases[name] = target
+	return nil
+}
+
+// FileAliasStore keeps aliases in a JSON file mapping names to targets. The
+// file is replaced atomically on each change, so readers never see a partial
+// write. Changes are serialized within the process only.
+type FileAliasStore struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+// Alias returns the target of name
+func (s *FileAliasStore) Alias(_ context.Context, name string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	aliases, err := s.load()
+	if err != nil {
+		return "", false, err
+	}
+	target, ok := aliases[name]
+	return target, ok, nil
+}
+
+// SetAlias points name to target
+func (s *FileAliasStore) SetAlias(_ context.Context, name, target string) error {
+	return s.update(func(aliases map[string]string) error {
+		aliases[name] = target
+		return nil
+	})
+}
+
+// SwapAlias points name to target if it still points to old
+func (s *FileAliasStore) SwapAlias(_ context.Context, name, old, target string) error {
+	return s.update(func(aliases map[string]string) error {
+		if aliases[name] != old {
+			return fmt.Errorf("%w: %s", ErrAliasConflict, name)
+		}
+		aliases[name] = target
+		return nil
+	})
+}
+
+// load reads the aliases, none when the file does not exist
+func (s *FileAliasStore) load() (map[string]string, error) {
+	aliases := map[string]string{}
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("invalid alias file %s: %w", s.Path, err)
+	}
+	return aliases, nil
+}
+
+// update applies edit to the aliases and writes them to a temporary file
+// renamed over the previous one
+func (s *FileAliasStore) update(edit func(map[string]string) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	aliases, err := s.load()
+	if err != nil {
+		return err
+	}
+	if err := edit(aliases); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
+
+// SQLAliasStore keeps aliases in a SQL table, which must exist:
+//
+//	CREATE TABLE model_aliases (name VARCHAR(255) PRIMARY KEY, target VARCHAR(255) NOT NULL)
+//
+// Swaps are a single conditional UPDATE, so they are atomic across processes.
+type SQLAliasStore struct {
+	DB    *sql.DB
+	Table string // "model_aliases" when empty
+
+	// Placeholder is the bind parameter syntax of the driver: "?" (default)
+	// for MySQL and SQLite, "$" for PostgreSQL's $1, $2...
+	Placeholder string
+}
+
+// Alias returns the target of name
+func (s *SQLAliasStore) Alias(ctx context.Context, name string) (string, bool, error) {
+	var target string
+	err := s.DB.QueryRowContext(ctx, s.query("SELECT target FROM %s WHERE name = ?"), name).Scan(&target)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return target, true, nil
+}
+
+// SetAlias points name to target
+func (s *SQLAliasStore) SetAlias(ctx context.Context, name, target string) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, s.query("UPDATE %s SET target = ? WHERE name = ?"), target, name)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		if _, err := tx.ExecContext(ctx, s.query("INSERT INTO %s (name, target) VALUES (?, ?)"), name, target); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SwapAlias points name to target if it still points to old
+func (s *SQLAliasStore) SwapAlias(ctx context.Context, name, old, target string) error {
+	var result sql.Result
+	var err error
+	if old == "" {
+		// The primary key rejects the insert if the alias was created meanwhile
+		result, err = s.DB.ExecContext(ctx, s.query("INSERT INTO %s (name, target) VALUES (?, ?)"), name, target)
+		if err != nil {
+			if _, exists, lookupErr := s.Alias(ctx, name); lookupErr == nil && exists {
+				return fmt.Errorf("%w: %s", ErrAliasConflict, name)
+			}
+			return err
+		}
+	} else {
+		result, err = s.DB.ExecContext(ctx, s.query("UPDATE %s SET target = ? WHERE name = ? AND target = ?"), target, name, old)
+		if err != nil {
+			return err
+		}
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %s", ErrAliasConflict, name)
+	}
+	return nil
+}
+
+// query formats a statement with the table name and the driver's placeholders
+func (s *SQLAliasStore) query(format string) string {
+	table := s.Table
+	if table == "" {
+		table = "model_aliases"
+	}
+	query := fmt.Sprintf(format, table)
+	if s.Placeholder != "$" {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,278 @@
+package openai
+
+import (
+	"context"
+	"io"
+	"iter"
+	"time"
+)
+
+// The service accessors of Client group its methods by REST resource, e.g.
+// client.VectorStores.Files.Create, so related calls are found together as
+// endpoints are added. They call the flat methods of Client, which remain.
+
+// AssistantsAPI calls the /assistants endpoints
+type AssistantsAPI struct{ c *Client }
+
+func (s *AssistantsAPI) List(ctx context.Context, opts ...ListOption) ([]Assistant, error) {
+	return s.c.ListAssistants(ctx, opts...)
+}
+
+func (s *AssistantsAPI) All(ctx context.Context, opts ...ListOption) iter.Seq2[Assistant, error] {
+	return s.c.AllAssistants(ctx, opts...)
+}
+
+func (s *AssistantsAPI) Retrieve(ctx context.Context, assistantID string) (*Assistant, error) {
+	return s.c.RetrieveAssistant(ctx, assistantID)
+}
+
+func (s *AssistantsAPI) Create(ctx context.Context, params *CreateAssistantParams) (*Assistant, error) {
+	return s.c.CreateAssistant(ctx, params)
+}
+
+func (s *AssistantsAPI) Modify(ctx context.Context, assistantID string, params *CreateAssistantParams) error {
+	return s.c.ModifyAssistant(ctx, assistantID, params)
+}
+
+func (s *AssistantsAPI) Ensure(ctx context.Context, params *CreateAssistantParams, matchKey string) (*Assistant, error) {
+	return s.c.EnsureAssistant(ctx, params, matchKey)
+}
+
+func (s *AssistantsAPI) Clone(ctx context.Context, sourceID string, overrides AssistantOverrides) (*Assistant, error) {
+	return s.c.CloneAssistant(ctx, sourceID, overrides)
+}
+
+func (s *AssistantsAPI) Delete(ctx context.Context, assistantID string) error {
+	return s.c.DeleteAssistant(ctx, assistantID)
+}
+
+// ThreadsAPI calls the /threads endpoints
+type ThreadsAPI struct {
+	c *Client
+
+	Messages *MessagesAPI
+	Runs     *RunsAPI
+}
+
+func (s *ThreadsAPI) Create(ctx context.Context, params *CreateThreadParams) (*Thread, error) {
+	return s.c.CreateThread(ctx, params)
+}
+
+func (s *ThreadsAPI) Retrieve(ctx context.Context, threadID string) (*Thread, error) {
+	return s.c.RetrieveThread(ctx, threadID)
+}
+
+func (s *ThreadsAPI) Modify(ctx context.Context, threadID string, params *ModifyThreadParams) (*Thread, error) {
+	return s.c.ModifyThread(ctx, threadID, params)
+}
+
+func (s *ThreadsAPI) Delete(ctx context.Context, threadID string) (*DeletionStatus, error) {
+	return s.c.DeleteThread(ctx, threadID)
+}
+
+// MessagesAPI calls the /threads/{thread_id}/messages endpoints
+type MessagesAPI struct{ c *Client }
+
+func (s *MessagesAPI) Create(ctx context.Context, params *CreateMessageParams) (*Message, error) {
+	return s.c.CreateMessage(ctx, params)
+}
+
+func (s *MessagesAPI) List(ctx context.Context, threadID string, opts ...ListOption) ([]Message, error) {
+	return s.c.ListMessages(ctx, threadID, opts...)
+}
+
+func (s *MessagesAPI) All(ctx context.Context, threadID string, opts ...ListOption) iter.Seq2[Message, error] {
+	return s.c.AllMessages(ctx, threadID, opts...)
+}
+
+// RunsAPI calls the /threads/{thread_id}/runs endpoints
+type RunsAPI struct{ c *Client }
+
+func (s *RunsAPI) Create(ctx context.Context, threadID string, params *CreateRunParams, include []string) (*Run, error) {
+	return s.c.CreateRun(ctx, threadID, params, include)
+}
+
+func (s *RunsAPI) Stream(ctx context.Context, threadID string, params *CreateRunParams, include []string) iter.Seq2[RunStreamEvent, error] {
+	return s.c.StreamRun(ctx, threadID, params, include)
+}
+
+func (s *RunsAPI) Retrieve(ctx context.Context, threadID, runID string) (*Run, error) {
+	return s.c.RetrieveRun(ctx, threadID, runID)
+}
+
+func (s *RunsAPI) List(ctx context.Context, threadID string, opts ...ListOption) ([]Run, error) {
+	return s.c.ListRuns(ctx, threadID, opts...)
+}
+
+func (s *RunsAPI) All(ctx context.Context, threadID string, opts ...ListOption) iter.Seq2[Run, error] {
+	return s.c.AllRuns(ctx, threadID, opts...)
+}
+
+func (s *RunsAPI) SubmitToolOutputs(ctx context.Context, threadID, runID string, outputs []ToolOutput) (*Run, error) {
+	return s.c.SubmitToolOutputs(ctx, threadID, runID, outputs)
+}
+
+func (s *RunsAPI) Wait(ctx context.Context, threadID, runID string, interval time.Duration) (*Run, error) {
+	return s.c.WaitForRun(ctx, threadID, runID, interval)
+}
+
+func (s *RunsAPI) ListSteps(ctx context.Context, threadID, runID string, opts ...ListOption) ([]RunStep, error) {
+	return s.c.ListRunSteps(ctx, threadID, runID, opts...)
+}
+
+// FilesAPI calls the /files endpoints
+type FilesAPI struct{ c *Client }
+
+func (s *FilesAPI) Upload(ctx context.Context, path string) (string, error) {
+	return s.c.UploadFile(ctx, path)
+}
+
+func (s *FilesAPI) UploadContent(ctx context.Context, path string, content []byte) (string, error) {
+	return s.c.UploadContent(ctx, path, content)
+}
+
+func (s *FilesAPI) List(ctx context.Context, opts ...ListOption) ([]File, error) {
+	return s.c.ListFiles(ctx, opts...)
+}
+
+func (s *FilesAPI) All(ctx context.Context, opts ...ListOption) iter.Seq2[File, error] {
+	return s.c.AllFiles(ctx, opts...)
+}
+
+func (s *FilesAPI) Retrieve(ctx context.Context, fileID string) (*File, error) {
+	return s.c.RetrieveFile(ctx, fileID)
+}
+
+func (s *FilesAPI) Download(ctx context.Context, fileID string, w io.Writer) error {
+	return s.c.DownloadFile(ctx, fileID, w)
+}
+
+func (s *FilesAPI) Forward(ctx context.Context, fileID string, uploader Uploader) (*ForwardedFile, error) {
+	return s.c.ForwardFile(ctx, fileID, uploader)
+}
+
+func (s *FilesAPI) Delete(ctx context.Context, fileID string) error {
+	return s.c.DeleteFile(ctx, fileID)
+}
+
+// VectorStoresAPI calls the /vector_stores endpoints
+type VectorStoresAPI struct {
+	c *Client
+
+	Files *VectorStoreFilesAPI
+}
+
+func (s *VectorStoresAPI) Create(ctx context.Context, params *CreateVectorStoreParams) (*VectorStore, error) {
+	return s.c.CreateVectorStore(ctx, params)
+}
+
+func (s *VectorStoresAPI) List(ctx context.Context, opts ...ListOption) ([]VectorStore, error) {
+	return s.c.ListVectorStores(ctx, opts...)
+}
+
+func (s *VectorStoresAPI) All(ctx context.Context, opts ...ListOption) iter.Seq2[VectorStore, error] {
+	return s.c.AllVectorStores(ctx, opts...)
+}
+
+func (s *VectorStoresAPI) Retrieve(ctx context.Context, vectorStoreID string) (*VectorStore, error) {
+	return s.c.RetrieveVectorStore(ctx, vectorStoreID)
+}
+
+func (s *VectorStoresAPI) Modify(ctx context.Context, vectorStoreID string, params *ModifyVectorStoreParams) (*VectorStore, error) {
+	return s.c.ModifyVectorStore(ctx, vectorStoreID, params)
+}
+
+func (s *VectorStoresAPI) Delete(ctx context.Context, vectorStoreID string) error {
+	return s.c.DeleteVectorStore(ctx, vectorStoreID)
+}
+
+func (s *VectorStoresAPI) Search(ctx context.Context, vectorStoreID string, params *SearchVectorStoreParams) ([]VectorStoreSearchResult, error) {
+	return s.c.SearchVectorStore(ctx, vectorStoreID, params)
+}
+
+// VectorStoreFilesAPI calls the /vector_stores/{vector_store_id}/files endpoints
+type VectorStoreFilesAPI struct{ c *Client }
+
+func (s *VectorStoreFilesAPI) Create(ctx context.Context, vectorStoreID, fileID string, chunkingStrategy map[string]interface{}) (*VectorStoreFile, error) {
+	return s.c.CreateVectorStoreFile(ctx, vectorStoreID, fileID, chunkingStrategy)
+}
+
+func (s *VectorStoreFilesAPI) List(ctx context.Context, vectorStoreID string, opts ...ListOption) ([]VectorStoreFile, error) {
+	return s.c.ListVectorStoreFiles(ctx, vectorStoreID, opts...)
+}
+
+func (s *VectorStoreFilesAPI) All(ctx context.Context, vectorStoreID string, opts ...ListOption) iter.Seq2[VectorStoreFile, error] {
+	return s.c.AllVectorStoreFiles(ctx, vectorStoreID, opts...)
+}
+
+func (s *VectorStoreFilesAPI) Retrieve(ctx context.Context, vectorStoreID, fileID string) (*VectorStoreFile, error) {
+	return s.c.RetrieveVectorStoreFile(ctx, vectorStoreID, fileID)
+}
+
+func (s *VectorStoreFilesAPI) Delete(ctx context.Context, vectorStoreID, fileID string) error {
+	return s.c.DeleteVectorStoreFile(ctx, vectorStoreID, fileID)
+}
+
+// BatchesAPI calls the /batches endpoints
+type BatchesAPI struct{ c *Client }
+
+func (s *BatchesAPI) Submit(ctx context.Context, name string, requests []byte, endpoint string, metadata map[string]string) (*Batch, error) {
+	return s.c.SubmitBatch(ctx, name, requests, endpoint, metadata)
+}
+
+func (s *BatchesAPI) Create(ctx context.Context, inputFileID, endpoint string, metadata map[string]string) (*Batch, error) {
+	return s.c.CreateBatch(ctx, inputFileID, endpoint, metadata)
+}
+
+func (s *BatchesAPI) Retrieve(ctx context.Context, batchID string) (*Batch, error) {
+	return s.c.RetrieveBatch(ctx, batchID)
+}
+
+func (s *BatchesAPI) Results(ctx context.Context, batch *Batch) ([]BatchResult, error) {
+	return s.c.BatchResults(ctx, batch)
+}
+
+// ModelsAPI calls the /models endpoints
+type ModelsAPI struct{ c *Client }
+
+func (s *ModelsAPI) Retrieve(ctx context.Context, model string) (*Model, error) {
+	return s.c.RetrieveModel(ctx, model)
+}
+
+func (s *ModelsAPI) Delete(ctx context.Context, model string) error {
+	return s.c.DeleteModel(ctx, model)
+}
+
+// ImagesAPI calls the /images endpoints
+type ImagesAPI struct{ c *Client }
+
+func (s *ImagesAPI) Generate(ctx context.Context, params *ImageParams) ([]Image, error) {
+	return s.c.GenerateImage(ctx, params)
+}
+
+// AudioAPI calls the /audio endpoints
+type AudioAPI struct{ c *Client }
+
+func (s *AudioAPI) Transcribe(ctx context.Context, path string, config *TranscribeConfig) (*Transcript, error) {
+	return s.c.TranscribeAudio(ctx, path, config)
+}
+
+// ModerationsAPI calls the /moderations endpoint
+type ModerationsAPI struct{ c *Client }
+
+func (s *ModerationsAPI) Create(ctx context.Context, model string, inputs ...string) ([]ModerationResult, error) {
+	return s.c.Moderate(ctx, model, inputs...)
+}
+
+// initServices sets the service accessors of the client
+func (c *Client) initServices() {
+	c.Assistants = &AssistantsAPI{c}
+	c.Threads = &ThreadsAPI{c: c, Messages: &MessagesAPI{c}, Runs: &RunsAPI{c}}
+	c.Files = &FilesAPI{c}
+	c.VectorStores = &VectorStoresAPI{c: c, Files: &VectorStoreFilesAPI{c}}
+	c.Batches = &BatchesAPI{c}
+	c.Models = &ModelsAPI{c}
+	c.Images = &ImagesAPI{c}
+	c.Audio = &AudioAPI{c}
+	c.Moderations = &ModerationsAPI{c}
+}
@@ -0,0 +1,148 @@
+package openai
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// Bounds of what walkArchive expands in memory, so a small crafted archive
+// cannot exhaust it
+const (
+	maxArchiveEntryBytes = 512 << 20 // Size of a single entry
+	maxArchiveBytes      = 1 << 30   // Total size of the entries
+	maxArchiveEntries    = 10000     // Number of entries
+)
+
+// isArchive reports whether the path names an archive walkArchive can expand
+func isArchive(p string) bool {
+	lower := strings.ToLower(p)
+	return strings.HasSuffix(lower, ".zip") || strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz")
+}
+
+// walkArchive calls fn with the slash-separated path and the content of every
+// regular file of a .zip, .tar.gz or .tgz archive. Hidden files and entries
+// escaping the archive root are skipped. It fails on archives expanding past
+// the limits above.
+func walkArchive(archivePath string, fn func(name string, content []byte) error) error {
+	budget := &archiveBudget{}
+	if strings.HasSuffix(strings.ToLower(archivePath), ".zip") {
+		return walkZip(archivePath, budget, fn)
+	}
+	return walkTarGz(archivePath, budget, fn)
+}
+
+func walkZip(archivePath string, budget *archiveBudget, fn func(name string, content []byte) error) error {
+	r, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		name, ok := archiveEntryName(f.Name)
+		if !ok || !f.Mode().IsRegular() {
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+		content, err := budget.read(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.Name, err)
+		}
+		if err := fn(name, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkTarGz(archivePath string, budget *archiveBudget, fn func(name string, content []byte) error) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name, ok := archiveEntryName(hdr.Name)
+		if !ok || hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := budget.read(tr)
+		if err != nil {
+			return fmt.Errorf("%s: %w", hdr.Name, err)
+		}
+		if err := fn(name, content); err != nil {
+			return err
+		}
+	}
+}
+
+// archiveEntryName cleans an entry name, rejecting hidden files and paths
+// leaving the archive root
+func archiveEntryName(name string) (string, bool) {
+	name = path.Clean(strings.TrimPrefix(strings.ReplaceAll(name, "\\", "/"), "/"))
+	if name == "." || name == ".." || strings.HasPrefix(name, "../") {
+		return "", false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return "", false
+		}
+	}
+	return name, true
+}
+
+// archiveBudget counts the entries expanded from an archive and their bytes
+type archiveBudget struct {
+	entries int
+	bytes   int64
+}
+
+// read reads an entry, failing on entries too large to upload and once the
+// archive expands past its limits
+func (b *archiveBudget) read(r io.Reader) ([]byte, error) {
+	if b.entries >= maxArchiveEntries {
+		return nil, fmt.Errorf("archive has more than %d files", maxArchiveEntries)
+	}
+	limit := min(maxArchiveEntryBytes, maxArchiveBytes-b.bytes)
+	content, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > limit {
+		if limit == maxArchiveEntryBytes {
+			return nil, fmt.Errorf("entry larger than %d bytes", maxArchiveEntryBytes)
+		}
+		return nil, fmt.Errorf("archive expands to more than %d bytes", maxArchiveBytes)
+	}
+	b.entries++
+	b.bytes += int64(len(content))
+	return content, nil
+}
@@ -1,54 +1,70 @@
 package openai
 
 import (
-	"bytes"
-	"encoding/json"
+	"cmp"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"slices"
 )
 
 // Assistant represents an individual assistant's information
 type Assistant struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Model       string `json:"model"`
-	CreatedAt   int64  `json:"created_at"`
-	Status      string `json:"status"`
-	Description string `json:"description"`
-}
-
-// ListAssistants retrieves a list of all assistants
-func ListAssistants() ([]Assistant, error) {
-	url := "https://api.openai.com/v1/assistants"
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
+	ID           string    `json:"id"`
+	Object       string    `json:"object"`
+	Name         string    `json:"name"`
+	Model        string    `json:"model"`
+	CreatedAt    Timestamp `json:"created_at"`
+	Status       string    `json:"status"`
+	Description  string    `json:"description"`
+	Instructions string    `json:"instructions"`
+	Tools        []Tool    `json:"tools"`
+	Temperature  *float64  `json:"temperature,omitempty"`
+	TopP         *float64  `json:"top_p,omitempty"`
+	Metadata     Metadata  `json:"metadata,omitempty"`
+
+	// ResponseFormat is "auto" or an object such as {"type": "json_object"},
+	// decoded as a map
+	ResponseFormat interface{} `json:"response_format,omitempty"`
+
+	ToolResources *ToolResources `json:"tool_resources,omitempty"`
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
+// ToolResources lists the files and vector stores made available to the tools
+// of an assistant
+type ToolResources struct {
+	CodeInterpreter *CodeInterpreterResources `json:"code_interpreter,omitempty"`
+	FileSearch      *FileSearchResources      `json:"file_search,omitempty"`
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("retrieving assistants failed with status %s: %s", resp.Status, string(body))
-	}
+// CodeInterpreterResources lists the files available to the code_interpreter tool
+type CodeInterpreterResources struct {
+	FileIDs []string `json:"file_ids,omitempty"`
+}
 
-	// Parse the response
-	var response struct {
-		Data []Assistant `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+// FileSearchResources lists the vector stores searched by the file_search tool
+type FileSearchResources struct {
+	VectorStoreIDs []string `json:"vector_store_ids,omitempty"`
+}
+
+// ListAssistants retrieves a page of assistants, e.g.
+// ListAssistants(ctx, WithLimit(100), WithOrder("asc")). The API returns the 20
+// most recent assistants when no limit is given; see AllAssistants to get them
+// all.
+func (c *Client) ListAssistants(ctx context.Context, opts ...ListOption) ([]Assistant, error) {
+	page, err := c.ListAssistantsPage(ctx, opts...)
+	if err != nil {
+		return nil, err
 	}
+	return page.Data, nil
+}
 
-	return response.Data, nil
+// RetrieveAssistant retrieves an assistant by its ID
+func (c *Client) RetrieveAssistant(ctx context.Context, assistantID string) (*Assistant, error) {
+	var assistant Assistant
+	if err := c.do(ctx, "GET", "/assistants/"+assistantID, nil, &assistant); err != nil {
+		return nil, fmt.Errorf("retrieve assistant failed: %w", err)
+	}
+	return &assistant, nil
 }
 
 type CreateAssistantParams struct {
@@ -61,13 +77,14 @@ type CreateAssistantParams struct {
 	Temperature    *float64               `json:"temperature,omitempty"`
 	TopP           *float64               `json:"top_p,omitempty"`
 	ResponseFormat interface{}            `json:"response_format,omitempty"`
-	Metadata       map[string]string      `json:"metadata,omitempty"`
+	Metadata       Metadata               `json:"metadata,omitempty"`
 }
 
 type Tool struct {
 	Type            string                 `json:"type"`
 	FileSearch      *FileSearchConfig      `json:"file_search,omitempty"`
 	CodeInterpreter *CodeInterpreterConfig `json:"code_interpreter,omitempty"`
+	Function        *FunctionDefinition    `json:"function,omitempty"` // See FunctionToolFromStruct
 }
 
 type FileSearchConfig struct {
@@ -85,101 +102,145 @@ type CodeInterpreterConfig struct {
 }
 
 // CreateAssistant creates an assistant with the provided configuration
-func CreateAssistant(params *CreateAssistantParams) (string, error) {
-	payloadBytes, err := json.Marshal(params)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal assistant payload: %w", err)
+func (c *Client) CreateAssistant(ctx context.Context, params *CreateAssistantParams) (*Assistant, error) {
+	var assistant Assistant
+	if err := c.do(ctx, "POST", "/assistants", params, &assistant); err != nil {
+		return nil, fmt.Errorf("assistant creation failed: %w", err)
 	}
 
-	url := "https://api.openai.com/v1/assistants"
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return "", fmt.Errorf("failed to create assistant request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
+	c.logger.InfoContext(ctx, "assistant created", "assistant_id", assistant.ID)
+	return &assistant, nil
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("assistant request failed: %w", err)
+// Modify the assistant
+func (c *Client) ModifyAssistant(ctx context.Context, assistantID string, params *CreateAssistantParams) error {
+	if err := c.do(ctx, "POST", "/assistants/"+assistantID, params, nil); err != nil {
+		return fmt.Errorf("assistant modification failed: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return "", fmt.Errorf("assistant creation failed with status %s: %s", resp.Status, string(body))
-	}
+	c.logger.InfoContext(ctx, "assistant modified", "assistant_id", assistantID)
+	return nil
+}
 
-	var response map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("failed to decode assistant response: %w", err)
+// DeleteAssistant deletes an assistant by its ID
+func (c *Client) DeleteAssistant(ctx context.Context, assistantID string) error {
+	if err := c.do(ctx, "DELETE", "/assistants/"+assistantID, nil, nil); err != nil {
+		return fmt.Errorf("assistant deletion failed: %w", err)
 	}
-	assistantID, _ := response["id"].(string)
-	fmt.Printf("Assistant created successfully with ID: %s\n", assistantID)
-	return assistantID, nil
+
+	c.logger.InfoContext(ctx, "assistant deleted", "assistant_id", assistantID)
+	return nil
 }
 
-// Modify the assistant
-func ModifyAssistant(assistantID string, params *CreateAssistantParams) error {
-	payloadBytes, err := json.Marshal(params)
-	if err != nil {
-		return fmt.Errorf("failed to marshal assistant payload: %w", err)
-	}
+// EnsureAssistant updates the assistant matching params, or creates it when
+// none does, so deploy scripts run again do not multiply assistants. With an
+// empty matchKey, assistants match by name; otherwise matchKey is a metadata
+// key, e.g. "app", and assistants match by its value in params.Metadata. It
+// fails when several assistants match.
+func (c *Client) EnsureAssistant(ctx context.Context, params *CreateAssistantParams, matchKey string) (*Assistant, error) {
+	value := params.Name
+	if matchKey != "" {
+		value = params.Metadata[matchKey]
+	}
+	if value == "" {
+		return nil, fmt.Errorf("no %s to match assistants on", cmp.Or(matchKey, "name"))
+	}
+
+	var existing *Assistant
+	for assistant, err := range c.AllAssistants(ctx, WithLimit(100)) {
+		if err != nil {
+			return nil, err
+		}
+		if (matchKey == "" && assistant.Name != value) || (matchKey != "" && assistant.Metadata[matchKey] != value) {
+			continue
+		}
+		if existing != nil {
+			return nil, fmt.Errorf("several assistants match %s %q: %s and %s", cmp.Or(matchKey, "name"), value, existing.ID, assistant.ID)
+		}
+		existing = &assistant
+	}
+
+	if existing == nil {
+		return c.CreateAssistant(ctx, params)
+	}
+	if err := c.ModifyAssistant(ctx, existing.ID, params); err != nil {
+		return nil, err
+	}
+	return c.RetrieveAssistant(ctx, existing.ID)
+}
 
-	url := fmt.Sprintf("https://api.openai.com/v1/assistants/%s", assistantID)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return fmt.Errorf("failed to create assistant request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
+// AssistantOverrides changes the configuration CloneAssistant copies. Zero
+// fields keep the value of the source assistant.
+type AssistantOverrides struct {
+	Name           string // The source name followed by " (copy)" when empty, if the source has one
+	Description    string
+	Model          string
+	Instructions   string
+	Tools          []Tool
+	Temperature    *float64
+	TopP           *float64
+	ResponseFormat interface{}
+	Metadata       Metadata // Merged over the metadata of the source
+
+	// VectorStoreIDs replaces the vector stores searched by file_search, and
+	// FileIDs the files of code_interpreter, when not nil
+	VectorStoreIDs []string
+	FileIDs        []string
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// CloneAssistant creates a new assistant with the configuration of the source
+// assistant, changed by overrides, e.g. to A/B test new instructions or
+// another model against the same files
+func (c *Client) CloneAssistant(ctx context.Context, sourceID string, overrides AssistantOverrides) (*Assistant, error) {
+	source, err := c.RetrieveAssistant(ctx, sourceID)
 	if err != nil {
-		return fmt.Errorf("assistant request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("assistant creation failed with status %s: %s", resp.Status, string(body))
+	name := overrides.Name
+	if name == "" && source.Name != "" {
+		name = source.Name + " (copy)"
 	}
 
-	var response map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return fmt.Errorf("failed to decode assistant response: %w", err)
+	// The stack ownership keys are not copied, so stacks keep managing the
+	// source only
+	params := &CreateAssistantParams{
+		Name:           name,
+		Description:    cmp.Or(overrides.Description, source.Description),
+		Model:          cmp.Or(overrides.Model, source.Model),
+		Instructions:   cmp.Or(overrides.Instructions, source.Instructions),
+		Tools:          source.Tools,
+		Temperature:    cmp.Or(overrides.Temperature, source.Temperature),
+		TopP:           cmp.Or(overrides.TopP, source.TopP),
+		ResponseFormat: source.ResponseFormat,
+		Metadata:       copyStringMap(userMetadata(source.Metadata)),
 	}
-
-	fmt.Printf("Assistant modified successfully with ID: %s\n", assistantID)
-	return nil
-}
-
-// DeleteAssistant deletes an assistant by its ID
-func DeleteAssistant(assistantID string) error {
-	url := fmt.Sprintf("https://api.openai.com/v1/assistants/%s", assistantID)
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create delete request: %w", err)
+	if overrides.Tools != nil {
+		params.Tools = overrides.Tools
 	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("OpenAI-Beta", "assistants=v2") // Extra header for beta features
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("delete request failed: %w", err)
+	if overrides.ResponseFormat != nil {
+		params.ResponseFormat = overrides.ResponseFormat
+	}
+	for k, v := range overrides.Metadata {
+		params.Metadata[k] = v
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("assistant deletion failed with status %s: %s", resp.Status, string(body))
+	resources := copyToolResources(source.ToolResources)
+	if overrides.VectorStoreIDs != nil {
+		resources.FileSearch = &FileSearchResources{VectorStoreIDs: overrides.VectorStoreIDs}
+		if len(overrides.VectorStoreIDs) > 0 && !slices.ContainsFunc(params.Tools, func(t Tool) bool { return t.Type == "file_search" }) {
+			params.Tools = append(append([]Tool(nil), params.Tools...), Tool{Type: "file_search"})
+		}
+	}
+	if overrides.FileIDs != nil {
+		resources.CodeInterpreter = &CodeInterpreterResources{FileIDs: overrides.FileIDs}
 	}
+	params.ToolResources = toolResourcesPayload(resources)
 
-	fmt.Printf("Assistant with ID %s deleted successfully.\n", assistantID)
-	return nil
+	clone, err := c.CreateAssistant(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.InfoContext(ctx, "assistant cloned", "source_id", sourceID, "assistant_id", clone.ID)
+	return clone, nil
 }
@@ -0,0 +1,215 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// AssistantSpec is the configuration of an assistant, exported by
+// ExportAssistant and applied by ApplyAssistantSpec, so assistants can be
+// kept in version control and promoted across environments. It is encoded as
+// JSON, or as YAML with a library honoring json tags such as
+// sigs.k8s.io/yaml.
+//
+// Unlike a StackConfig, a spec only references the vector stores and files of
+// the assistant: they must exist in the environment it is applied to.
+type AssistantSpec struct {
+	Name           string      `json:"name"` // Identifies the assistant when the spec is applied
+	Description    string      `json:"description,omitempty"`
+	Model          string      `json:"model"`
+	Instructions   string      `json:"instructions,omitempty"`
+	Tools          []Tool      `json:"tools,omitempty"`
+	Temperature    *float64    `json:"temperature,omitempty"`
+	TopP           *float64    `json:"top_p,omitempty"`
+	ResponseFormat interface{} `json:"response_format,omitempty"`
+	Metadata       Metadata    `json:"metadata,omitempty"`
+
+	VectorStores         []ResourceRef `json:"vector_stores,omitempty"`          // Searched by the file_search tool
+	CodeInterpreterFiles []ResourceRef `json:"code_interpreter_files,omitempty"` // Available to the code_interpreter tool
+}
+
+// ResourceRef references a vector store or a file. The ID is used when it
+// exists in the environment the spec is applied to, the name otherwise.
+type ResourceRef struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+// LoadAssistantSpec reads an assistant spec from a JSON file
+func LoadAssistantSpec(path string) (*AssistantSpec, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read assistant spec %s: %w", path, err)
+	}
+
+	var spec AssistantSpec
+	if err := json.Unmarshal(content, &spec); err != nil {
+		return nil, fmt.Errorf("failed to decode assistant spec %s: %w", path, err)
+	}
+	return &spec, nil
+}
+
+// ExportAssistant returns the configuration of an assistant, with the names
+// of its vector stores and files so the spec can be applied elsewhere
+func (c *Client) ExportAssistant(ctx context.Context, assistantID string) (*AssistantSpec, error) {
+	assistant, err := c.RetrieveAssistant(ctx, assistantID)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := &AssistantSpec{
+		Name:           assistant.Name,
+		Description:    assistant.Description,
+		Model:          assistant.Model,
+		Instructions:   assistant.Instructions,
+		Tools:          assistant.Tools,
+		Temperature:    assistant.Temperature,
+		TopP:           assistant.TopP,
+		ResponseFormat: assistant.ResponseFormat,
+		Metadata:       assistant.Metadata,
+	}
+	if spec.Name == "" {
+		spec.Name = assistant.ID
+	}
+
+	resources := copyToolResources(assistant.ToolResources)
+	if resources.FileSearch != nil {
+		for _, id := range resources.FileSearch.VectorStoreIDs {
+			vs, err := c.RetrieveVectorStore(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			spec.VectorStores = append(spec.VectorStores, ResourceRef{ID: id, Name: vs.Name})
+		}
+	}
+	if resources.CodeInterpreter != nil {
+		for _, id := range resources.CodeInterpreter.FileIDs {
+			file, err := c.RetrieveFile(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			spec.CodeInterpreterFiles = append(spec.CodeInterpreterFiles, ResourceRef{ID: id, Name: file.FileName})
+		}
+	}
+	return spec, nil
+}
+
+// ApplyAssistantSpec makes the assistant named spec.Name match the spec,
+// creating it when no assistant has that name, like EnsureAssistant. It fails
+// when several assistants have the name, or when a referenced vector store or
+// file cannot be found.
+func (c *Client) ApplyAssistantSpec(ctx context.Context, spec *AssistantSpec) (*Assistant, error) {
+	if spec.Name == "" || spec.Model == "" {
+		return nil, fmt.Errorf("assistant name and model are required")
+	}
+
+	params := &CreateAssistantParams{
+		Name:           spec.Name,
+		Description:    spec.Description,
+		Model:          spec.Model,
+		Instructions:   spec.Instructions,
+		Tools:          spec.Tools,
+		Temperature:    spec.Temperature,
+		TopP:           spec.TopP,
+		ResponseFormat: spec.ResponseFormat,
+		Metadata:       spec.Metadata,
+	}
+	// Both resources are always sent so links removed from the spec are
+	// removed from the assistant
+	vectorStoreIDs, err := c.resolveVectorStores(ctx, spec.VectorStores)
+	if err != nil {
+		return nil, err
+	}
+	fileIDs, err := c.resolveFiles(ctx, spec.CodeInterpreterFiles)
+	if err != nil {
+		return nil, err
+	}
+	params.ToolResources = toolResourcesPayload(&ToolResources{
+		FileSearch:      &FileSearchResources{VectorStoreIDs: vectorStoreIDs},
+		CodeInterpreter: &CodeInterpreterResources{FileIDs: fileIDs},
+	})
+	if len(vectorStoreIDs) > 0 && !slices.ContainsFunc(params.Tools, func(t Tool) bool { return t.Type == "file_search" }) {
+		params.Tools = append(append([]Tool(nil), params.Tools...), Tool{Type: "file_search"})
+	}
+
+	return c.EnsureAssistant(ctx, params, "")
+}
+
+// resolveVectorStores returns the IDs of the referenced vector stores
+func (c *Client) resolveVectorStores(ctx context.Context, refs []ResourceRef) ([]string, error) {
+	var byName map[string][]string
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.ID != "" {
+			if _, err := c.RetrieveVectorStore(ctx, ref.ID); err == nil {
+				ids = append(ids, ref.ID)
+				continue
+			} else if !IsNotFound(err) || ref.Name == "" {
+				return nil, err
+			}
+		}
+
+		if byName == nil {
+			byName = map[string][]string{}
+			for vs, err := range c.AllVectorStores(ctx, WithLimit(100)) {
+				if err != nil {
+					return nil, err
+				}
+				byName[vs.Name] = append(byName[vs.Name], vs.ID)
+			}
+		}
+		id, err := resolveByName("vector store", ref.Name, byName)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// resolveFiles returns the IDs of the referenced files
+func (c *Client) resolveFiles(ctx context.Context, refs []ResourceRef) ([]string, error) {
+	var byName map[string][]string
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.ID != "" {
+			if _, err := c.RetrieveFile(ctx, ref.ID); err == nil {
+				ids = append(ids, ref.ID)
+				continue
+			} else if !IsNotFound(err) || ref.Name == "" {
+				return nil, err
+			}
+		}
+
+		if byName == nil {
+			byName = map[string][]string{}
+			for file, err := range c.AllFiles(ctx) {
+				if err != nil {
+					return nil, err
+				}
+				byName[file.FileName] = append(byName[file.FileName], file.ID)
+			}
+		}
+		id, err := resolveByName("file", ref.Name, byName)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// resolveByName returns the single ID of a named resource
+func resolveByName(kind, name string, byName map[string][]string) (string, error) {
+	switch ids := byName[name]; len(ids) {
+	case 0:
+		return "", fmt.Errorf("%s %q not found", kind, name)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("several %ss are named %q", kind, name)
+	}
+}
@@ -0,0 +1,190 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// defaultProbeSeconds is the length of audio sent to detect the language
+const defaultProbeSeconds = 15
+
+// probeMP3Bytes approximates 15 seconds of a 128 kbps MP3. MP3 frames are
+// self-contained, so a prefix of the file is still valid audio.
+const probeMP3Bytes = 240 << 10
+
+// whisperLanguages maps the language names returned by verbose transcriptions
+// to the ISO-639-1 codes accepted as input
+var whisperLanguages = map[string]string{
+	"afrikaans": "af", "arabic": "ar", "armenian": "hy", "azerbaijani": "az", "belarusian": "be",
+	"bosnian": "bs", "bulgarian": "bg", "catalan": "ca", "chinese": "zh", "croatian": "hr",
+	"czech": "cs", "danish": "da", "dutch": "nl", "english": "en", "estonian": "et",
+	"finnish": "fi", "french": "fr", "galician": "gl", "german": "de", "greek": "el",
+	"hebrew": "he", "hindi": "hi", "hungarian": "hu", "icelandic": "is", "indonesian": "id",
+	"italian": "it", "japanese": "ja", "kannada": "kn", "kazakh": "kk", "korean": "ko",
+	"latvian": "lv", "lithuanian": "lt", "macedonian": "mk", "malay": "ms", "marathi": "mr",
+	"maori": "mi", "nepali": "ne", "norwegian": "no", "persian": "fa", "polish": "pl",
+	"portuguese": "pt", "romanian": "ro", "russian": "ru", "serbian": "sr", "slovak": "sk",
+	"slovenian": "sl", "spanish": "es", "swahili": "sw", "swedish": "sv", "tagalog": "tl",
+	"tamil": "ta", "thai": "th", "turkish": "tr", "ukrainian": "uk", "urdu": "ur",
+	"vietnamese": "vi", "welsh": "cy",
+}
+
+// TranscribeConfig configures TranscribeAudio
+type TranscribeConfig struct {
+	// Languages lists the ISO-639-1 codes, e.g. "fr", transcribed in their
+	// language. Audio in other languages is translated to English. Empty
+	// transcribes every language.
+	Languages []string
+
+	// Prompts guides the model, e.g. with the spelling of names and jargon, by
+	// language code. Translations use the "en" prompt since they are in English.
+	Prompts map[string]string
+
+	ProbeSeconds int // Length of audio used to detect the language, 15 when 0
+}
+
+// Transcript is the text of an audio file
+type Transcript struct {
+	Text       string
+	Language   string // Detected language, as an ISO-639-1 code when known
+	Translated bool   // Whether Text was translated to English
+}
+
+// TranscribeAudio detects the language spoken in the first seconds of an audio
+// file, then transcribes it in that language or translates it to English
+// according to config, which may be nil. The language is detected on a clip of
+// WAV and MP3 files; other formats are sent whole.
+func (c *Client) TranscribeAudio(ctx context.Context, path string, config *TranscribeConfig) (*Transcript, error) {
+	if config == nil {
+		config = &TranscribeConfig{}
+	}
+	probeSeconds := config.ProbeSeconds
+	if probeSeconds <= 0 {
+		probeSeconds = defaultProbeSeconds
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	language, err := c.detectLanguage(ctx, filepath.Base(path), audioProbe(path, content, probeSeconds))
+	if err != nil {
+		return nil, err
+	}
+
+	request := openai.AudioRequest{
+		Model:    openai.Whisper1,
+		FilePath: filepath.Base(path),
+		Reader:   bytes.NewReader(content),
+		Format:   openai.AudioResponseFormatJSON,
+	}
+	transcript := &Transcript{Language: language}
+	client := c.sdkClient()
+
+	var resp openai.AudioResponse
+	if len(config.Languages) == 0 || slices.Contains(config.Languages, language) {
+		if len(language) == 2 {
+			request.Language = language
+		}
+		request.Prompt = config.Prompts[language]
+		resp, err = client.CreateTranscription(ctx, request)
+	} else {
+		request.Prompt = config.Prompts["en"]
+		transcript.Translated = true
+		resp, err = client.CreateTranslation(ctx, request)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error transcribing %s: %w", path, sdkError(err))
+	}
+
+	c.logger.InfoContext(ctx, "audio transcribed", "path", path, "language", language, "translated", transcript.Translated)
+	transcript.Text = resp.Text
+	return transcript, nil
+}
+
+// detectLanguage transcribes the clip and returns the language of the text
+func (c *Client) detectLanguage(ctx context.Context, name string, clip []byte) (string, error) {
+	resp, err := c.sdkClient().CreateTranscription(ctx, openai.AudioRequest{
+		Model:    openai.Whisper1,
+		FilePath: name,
+		Reader:   bytes.NewReader(clip),
+		Format:   openai.AudioResponseFormatVerboseJSON,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error detecting language of %s: %w", name, sdkError(err))
+	}
+
+	language := strings.ToLower(resp.Language)
+	if code, ok := whisperLanguages[language]; ok {
+		return code, nil
+	}
+	return language, nil
+}
+
+// audioProbe returns the first seconds of the audio when the format can be cut,
+// or the whole content
+func audioProbe(path string, content []byte, seconds int) []byte {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".wav":
+		if clip, ok := trimWAV(content, seconds); ok {
+			return clip
+		}
+	case ".mp3":
+		if len(content) > probeMP3Bytes*seconds/defaultProbeSeconds {
+			return content[:probeMP3Bytes*seconds/defaultProbeSeconds]
+		}
+	}
+	return content
+}
+
+// trimWAV keeps the first seconds of a WAV file, rewriting the RIFF sizes. It
+// reports false when the file cannot be parsed.
+func trimWAV(content []byte, seconds int) ([]byte, bool) {
+	if len(content) < 12 || string(content[:4]) != "RIFF" || string(content[8:12]) != "WAVE" {
+		return nil, false
+	}
+
+	var byteRate, blockAlign int
+	out := append([]byte{}, content[:12]...)
+	for offset := 12; offset+8 <= len(content); {
+		id := string(content[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(content[offset+4 : offset+8]))
+		start := offset + 8
+		end := min(start+size, len(content))
+
+		switch id {
+		case "fmt ":
+			if end-start < 16 {
+				return nil, false
+			}
+			byteRate = int(binary.LittleEndian.Uint32(content[start+8 : start+12]))
+			blockAlign = int(binary.LittleEndian.Uint16(content[start+12 : start+14]))
+		case "data":
+			if byteRate == 0 || blockAlign == 0 {
+				return nil, false
+			}
+			n := min(end-start, byteRate*seconds)
+			n -= n % blockAlign
+			out = append(out, "data"...)
+			out = binary.LittleEndian.AppendUint32(out, uint32(n))
+			out = append(out, content[start:start+n]...)
+			binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+			return out, true
+		}
+
+		// Chunks are padded to an even size
+		next := min(end+size%2, len(content))
+		out = append(out, content[offset:next]...)
+		offset = next
+	}
+	return nil, false
+}
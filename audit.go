@@ -0,0 +1,287 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Kinds of AuditRecord
+const (
+	AuditRequest    = "request"
+	AuditUsage      = "usage"
+	AuditTranscript = "transcript"
+)
+
+// AuditRecord describes a call to the API. Request records are written for
+// every HTTP request, retries included; usage records are written when the
+// token usage of a chat, embeddings or run call is known; transcript records
+// are written when a stream ends, see WithStreamTranscripts.
+type AuditRecord struct {
+	Time        time.Time         `json:"time"`
+	Kind        string            `json:"kind"` // AuditRequest, AuditUsage or AuditTranscript
+	Method      string            `json:"method,omitempty"`
+	Path        string            `json:"path,omitempty"` // Path relative to the base URL, e.g. "/threads/thread_abc/runs"
+	ResourceIDs []string          `json:"resource_ids,omitempty"`
+	Model       string            `json:"model,omitempty"`
+	BytesSent   int64             `json:"bytes_sent,omitempty"`
+	StatusCode  int               `json:"status_code,omitempty"`
+	RequestID   string            `json:"request_id,omitempty"`
+	Duration    time.Duration     `json:"duration,omitempty"`
+	Error       string            `json:"error,omitempty"`
+	Usage       *Usage            `json:"usage,omitempty"`
+	Transcript  *StreamTranscript `json:"transcript,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+
+	CorrelationID string `json:"correlation_id,omitempty"` // See WithCorrelationID
+}
+
+// AuditSink stores audit records
+type AuditSink interface {
+	WriteAudit(ctx context.Context, record AuditRecord) error
+}
+
+// AuditSinkFunc adapts a function to the AuditSink interface, e.g. to insert
+// records in a SQL table
+type AuditSinkFunc func(ctx context.Context, record AuditRecord) error
+
+func (f AuditSinkFunc) WriteAudit(ctx context.Context, record AuditRecord) error {
+	return f(ctx, record)
+}
+
+// WithAuditSink records every call of the client in sink. Records are written
+// synchronously; a failing sink is logged and does not fail the call.
+func WithAuditSink(sink AuditSink) Option {
+	return func(c *Client) {
+		c.auditSink = sink
+		WithUsageHook(func(ctx context.Context, usage Usage) {
+			c.audit(ctx, AuditRecord{Time: c.clock.Now(), Kind: AuditUsage, Model: usage.Model, Usage: &usage})
+		})(c)
+	}
+}
+
+// WithStreamTranscripts writes a transcript record to the audit sink when a
+// chat stream ends, holding the text assembled from its chunks, so streamed
+// answers are recorded as completely as the others. Streams cut short, e.g.
+// by a client disconnecting, record the text received so far with the error.
+func WithStreamTranscripts() Option {
+	return func(c *Client) {
+		c.streamTranscripts = true
+	}
+}
+
+// StreamTranscript is the outcome of a stream, see WithStreamTranscripts
+type StreamTranscript struct {
+	Choices          []string      `json:"choices"` // Text of each choice, by index
+	FinishReasons    []string      `json:"finish_reasons,omitempty"`
+	Chunks           int           `json:"chunks"`
+	TimeToFirstChunk time.Duration `json:"time_to_first_chunk,omitempty"`
+}
+
+// transcriptRecorder assembles the transcript of a chat stream
+type transcriptRecorder struct {
+	c       *Client
+	start   time.Time
+	model   string
+	texts   []strings.Builder
+	reasons []string
+	chunks  int
+	first   time.Duration
+}
+
+func (c *Client) newTranscriptRecorder(model string) *transcriptRecorder {
+	if c.auditSink == nil || !c.streamTranscripts {
+		return nil
+	}
+	return &transcriptRecorder{c: c, start: c.clock.Now(), model: model}
+}
+
+func (r *transcriptRecorder) add(chunk openai.ChatCompletionStreamResponse) {
+	if r == nil {
+		return
+	}
+	if r.chunks == 0 {
+		r.first = r.c.clock.Now().Sub(r.start)
+	}
+	r.chunks++
+	if chunk.Model != "" {
+		r.model = chunk.Model
+	}
+	for _, choice := range chunk.Choices {
+		for len(r.texts) <= choice.Index {
+			r.texts = append(r.texts, strings.Builder{})
+			r.reasons = append(r.reasons, "")
+		}
+		r.texts[choice.Index].WriteString(choice.Delta.Content)
+		if choice.FinishReason != "" {
+			r.reasons[choice.Index] = string(choice.FinishReason)
+		}
+	}
+}
+
+// finish writes the transcript to the audit sink
+func (r *transcriptRecorder) finish(ctx context.Context, err error) {
+	if r == nil {
+		return
+	}
+	transcript := &StreamTranscript{Chunks: r.chunks, TimeToFirstChunk: r.first, Choices: make([]string, len(r.texts))}
+	for i := range r.texts {
+		transcript.Choices[i] = r.texts[i].String()
+	}
+	if slices.ContainsFunc(r.reasons, func(reason string) bool { return reason != "" }) {
+		transcript.FinishReasons = r.reasons
+	}
+	record := AuditRecord{
+		Time:       r.start,
+		Kind:       AuditTranscript,
+		Model:      r.model,
+		Duration:   r.c.clock.Now().Sub(r.start),
+		Transcript: transcript,
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	r.c.audit(context.WithoutCancel(ctx), record)
+}
+
+// WithAuditTag adds a tag to the audit records of the calls made with the
+// context, e.g. the user or job on whose behalf the call is made. See
+// WithRequestOptions.
+func WithAuditTag(key, value string) RequestOption {
+	return func(config *requestConfig) {
+		if config.auditTags == nil {
+			config.auditTags = map[string]string{}
+		}
+		config.auditTags[key] = value
+	}
+}
+
+// JSONLAuditSink writes records as JSON lines, e.g. to a file
+type JSONLAuditSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLAuditSink returns a sink writing to w
+func NewJSONLAuditSink(w io.Writer) *JSONLAuditSink {
+	return &JSONLAuditSink{w: w}
+}
+
+func (s *JSONLAuditSink) WriteAudit(_ context.Context, record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// Flush flushes the writer if it has a Flush method, e.g. a *bufio.Writer
+func (s *JSONLAuditSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if flusher, ok := s.w.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+	return nil
+}
+
+// WebhookAuditSink posts each record as JSON to a URL
+type WebhookAuditSink struct {
+	URL        string
+	HTTPClient *http.Client // http.DefaultClient when nil
+}
+
+func (s *WebhookAuditSink) WriteAudit(ctx context.Context, record AuditRecord) error {
+	payload, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), "POST", s.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	httpClient := s.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("audit webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// resourceID matches the IDs of API resources, e.g. "thread_abc" or "file-abc"
+var resourceID = regexp.MustCompile(`^(asst|thread|msg|run|step|vs|vsfb|file|batch)[_-][A-Za-z0-9]+$`)
+
+// auditRequest records a request once it completed
+func (c *Client) auditRequest(req *http.Request, start time.Time, resp *http.Response, err error) {
+	record := AuditRecord{
+		Time:      start,
+		Kind:      AuditRequest,
+		Method:    req.Method,
+		Path:      c.relativePath(req),
+		BytesSent: req.ContentLength,
+		Duration:  c.clock.Now().Sub(start),
+	}
+	for _, segment := range strings.Split(record.Path, "/") {
+		if resourceID.MatchString(segment) {
+			record.ResourceIDs = append(record.ResourceIDs, segment)
+		}
+	}
+
+	// The model is read from JSON bodies, which can be replayed
+	if req.GetBody != nil && strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		if body, bodyErr := req.GetBody(); bodyErr == nil {
+			var payload struct {
+				Model string `json:"model"`
+			}
+			json.NewDecoder(body).Decode(&payload)
+			body.Close()
+			record.Model = payload.Model
+		}
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		record.StatusCode = resp.StatusCode
+		record.RequestID = resp.Header.Get("X-Request-Id")
+	}
+	c.audit(req.Context(), record)
+}
+
+// audit writes a record to the sink with the tags of the context
+func (c *Client) audit(ctx context.Context, record AuditRecord) {
+	if c.auditSink == nil {
+		return
+	}
+	if config, ok := ctx.Value(requestOptionsKey{}).(*requestConfig); ok {
+		record.Tags = config.auditTags
+		record.CorrelationID = config.correlationID
+	}
+	if err := c.auditSink.WriteAudit(ctx, record); err != nil {
+		c.logger.ErrorContext(ctx, "failed to write audit record", "error", err)
+	}
+}
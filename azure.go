@@ -0,0 +1,79 @@
+package openai
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultAzureAPIVersion is the Azure OpenAI API version used unless
+// AzureConfig.APIVersion is set. Assistants and vector stores need a preview
+// version.
+const DefaultAzureAPIVersion = "2024-05-01-preview"
+
+// AzureConfig describes an Azure OpenAI resource
+type AzureConfig struct {
+	Endpoint   string // Resource endpoint, e.g. "https://my-resource.openai.azure.com"
+	APIVersion string // Value of the api-version query parameter
+
+	// Deployment maps a model name to the name of its deployment, for the
+	// endpoints addressed by deployment (embeddings, chat). Models are used as
+	// deployment names when nil.
+	Deployment func(model string) string
+}
+
+// WithAzure sends the requests to an Azure OpenAI resource: the key is sent in
+// the api-key header, every request carries the api-version query parameter,
+// and embeddings and chat requests go to the deployment of their model.
+// Assistants, threads, files and vector stores use the same paths as OpenAI.
+func WithAzure(config AzureConfig) Option {
+	return func(c *Client) {
+		if config.APIVersion == "" {
+			config.APIVersion = DefaultAzureAPIVersion
+		}
+		config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+		c.azure = &config
+		c.baseURL = config.Endpoint + "/openai"
+	}
+}
+
+// deployment returns the Azure deployment serving the model
+func (config *AzureConfig) deployment(model string) string {
+	if config.Deployment == nil {
+		return model
+	}
+	return config.Deployment(model)
+}
+
+// authorize sets the credentials of the request, along with the api-version
+// parameter for Azure and the headers expected by compatible servers
+func (c *Client) authorize(req *http.Request) {
+	if c.compat != nil {
+		req.Header.Del("Authorization")
+		if c.apiKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+		return
+	}
+	if c.azure == nil {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		return
+	}
+
+	req.Header.Del("Authorization")
+	req.Header.Set("api-key", c.apiKey)
+	query := req.URL.Query()
+	if query.Get("api-version") == "" {
+		query.Set("api-version", c.azure.APIVersion)
+		req.URL.RawQuery = query.Encode()
+	}
+}
+
+// modelPath returns the path of an endpoint addressed by model, such as
+// "/embeddings", which Azure serves under the deployment of the model
+func (c *Client) modelPath(model, path string) string {
+	if c.azure == nil {
+		return path
+	}
+	return "/deployments/" + url.PathEscape(c.azure.deployment(model)) + path
+}
@@ -0,0 +1,246 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// BatchDiscount is the fraction of the realtime price charged for batch
+// requests
+const BatchDiscount = 0.5
+
+// Batch is a job running many requests asynchronously within 24 hours
+type Batch struct {
+	ID               string            `json:"id"`
+	Endpoint         string            `json:"endpoint"` // e.g. "/v1/chat/completions"
+	Status           string            `json:"status"`   // e.g. "validating", "in_progress", "completed" or "failed"
+	InputFileID      string            `json:"input_file_id"`
+	OutputFileID     string            `json:"output_file_id,omitempty"`
+	ErrorFileID      string            `json:"error_file_id,omitempty"`
+	CompletionWindow string            `json:"completion_window"`
+	CreatedAt        Timestamp         `json:"created_at"`
+	InProgressAt     Timestamp         `json:"in_progress_at,omitempty"`
+	CompletedAt      Timestamp         `json:"completed_at,omitempty"`
+	RequestCounts    BatchRequestCount `json:"request_counts"`
+	Metadata         Metadata          `json:"metadata,omitempty"`
+}
+
+// BatchRequestCount counts the requests of a batch by outcome
+type BatchRequestCount struct {
+	Total     int `json:"total"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+}
+
+// Progress returns the fraction of requests processed, from 0 to 1
+func (b *Batch) Progress() float64 {
+	if b.RequestCounts.Total == 0 {
+		return 0
+	}
+	return float64(b.RequestCounts.Completed+b.RequestCounts.Failed) / float64(b.RequestCounts.Total)
+}
+
+// ETA estimates the time left before the batch finishes from the rate at which
+// requests were processed so far. It returns false until the batch is in
+// progress with some requests processed.
+func (b *Batch) ETA(now time.Time) (time.Duration, bool) {
+	done := b.RequestCounts.Completed + b.RequestCounts.Failed
+	if b.InProgressAt.IsZero() || done == 0 {
+		return 0, false
+	}
+	if done >= b.RequestCounts.Total {
+		return 0, true
+	}
+
+	elapsed := now.Sub(b.InProgressAt.Time)
+	perRequest := elapsed / time.Duration(done)
+	return perRequest * time.Duration(b.RequestCounts.Total-done), true
+}
+
+// SubmitBatch uploads the requests of a batch, as JSON lines, and creates the
+// batch for endpoint, e.g. "/v1/chat/completions". See EstimateBatch to decide
+// between a batch and realtime calls first.
+func (c *Client) SubmitBatch(ctx context.Context, name string, requests []byte, endpoint string, metadata map[string]string) (*Batch, error) {
+	fileID, err := c.uploadContent(ctx, name, requests, "batch")
+	if err != nil {
+		return nil, err
+	}
+	c.emitProgress(ProgressEvent{Event: "batch_file_uploaded", Name: name, ID: fileID})
+	return c.CreateBatch(ctx, fileID, endpoint, metadata)
+}
+
+// CreateBatch creates a batch from an uploaded file of requests
+func (c *Client) CreateBatch(ctx context.Context, inputFileID, endpoint string, metadata map[string]string) (*Batch, error) {
+	body := map[string]interface{}{
+		"input_file_id":     inputFileID,
+		"endpoint":          endpoint,
+		"completion_window": "24h",
+	}
+	if metadata != nil {
+		body["metadata"] = metadata
+	}
+
+	var batch Batch
+	if err := c.do(ctx, "POST", "/batches", body, &batch); err != nil {
+		return nil, fmt.Errorf("batch creation failed: %w", err)
+	}
+	c.logger.InfoContext(ctx, "batch created", "batch_id", batch.ID, "input_file_id", inputFileID)
+	c.emitProgress(ProgressEvent{Event: "batch_submitted", ID: batch.ID, Status: batch.Status})
+	return &batch, nil
+}
+
+// RetrieveBatch fetches a batch, e.g. to follow its progress
+func (c *Client) RetrieveBatch(ctx context.Context, batchID string) (*Batch, error) {
+	var batch Batch
+	if err := c.do(ctx, "GET", "/batches/"+batchID, nil, &batch); err != nil {
+		return nil, fmt.Errorf("batch retrieval failed: %w", err)
+	}
+	return &batch, nil
+}
+
+// BatchEstimate is the projected size and cost of a batch
+type BatchEstimate struct {
+	Requests     int
+	PromptTokens int64
+
+	// MaxCompletionTokens sums the max_tokens or max_completion_tokens of the
+	// requests. Requests without a cap are not counted.
+	MaxCompletionTokens int64
+
+	// Costs in dollars of the prompts, and of the prompts plus the maximum
+	// completions, at batch prices. Realtime calls cost 1/BatchDiscount times
+	// more.
+	PromptDollars float64
+	MaxDollars    float64
+
+	// UnpricedModels lists the models without a price in the pricing table
+	UnpricedModels []string
+}
+
+// RealtimeDollars returns the cost of the prompts and maximum completions if
+// the requests were sent as realtime calls
+func (e *BatchEstimate) RealtimeDollars() float64 {
+	return e.MaxDollars / BatchDiscount
+}
+
+// TokenCounter returns the number of tokens of text for model, e.g.
+// tokenizer.Counter
+type TokenCounter func(model, text string) int
+
+// EstimateBatch counts the tokens of the requests of a batch, as JSON lines of
+// chat completions or embeddings, and prices them with the batch discount.
+// pricing defaults to DefaultPricing. Tokens are counted with count, or
+// approximated from the length of the text when nil.
+func EstimateBatch(r io.Reader, pricing Pricing, count TokenCounter) (*BatchEstimate, error) {
+	if pricing == nil {
+		pricing = DefaultPricing
+	}
+	if count == nil {
+		count = approximateTokens
+	}
+
+	estimate := &BatchEstimate{}
+	unpriced := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var request batchRequest
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			return nil, fmt.Errorf("invalid batch request on line %d: %w", line, err)
+		}
+
+		prompt := request.Body.promptTokens(count)
+		completion := max(request.Body.MaxTokens, request.Body.MaxCompletionTokens)
+		estimate.Requests++
+		estimate.PromptTokens += int64(prompt)
+		estimate.MaxCompletionTokens += int64(completion)
+
+		promptCost, ok := pricing.Cost(Usage{Model: request.Body.Model, PromptTokens: prompt})
+		if !ok {
+			unpriced[request.Body.Model] = true
+			continue
+		}
+		maxCost, _ := pricing.Cost(Usage{Model: request.Body.Model, PromptTokens: prompt, CompletionTokens: completion})
+		estimate.PromptDollars += promptCost * BatchDiscount
+		estimate.MaxDollars += maxCost * BatchDiscount
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch requests: %w", err)
+	}
+
+	for model := range unpriced {
+		estimate.UnpricedModels = append(estimate.UnpricedModels, model)
+	}
+	slices.Sort(estimate.UnpricedModels)
+	return estimate, nil
+}
+
+// batchRequest is a line of a batch input file
+type batchRequest struct {
+	CustomID string           `json:"custom_id"`
+	Method   string           `json:"method"`
+	URL      string           `json:"url"`
+	Body     batchRequestBody `json:"body"`
+}
+
+// batchRequestBody holds the fields of chat and embeddings requests needed to
+// estimate their tokens
+type batchRequestBody struct {
+	Model               string          `json:"model"`
+	Messages            []batchMessage  `json:"messages"`
+	Input               json.RawMessage `json:"input"` // A string or a list of strings
+	MaxTokens           int             `json:"max_tokens"`
+	MaxCompletionTokens int             `json:"max_completion_tokens"`
+}
+
+type batchMessage struct {
+	Content json.RawMessage `json:"content"` // A string or a list of parts
+}
+
+// promptTokens counts the tokens of the prompt of the request
+func (b *batchRequestBody) promptTokens(count TokenCounter) int {
+	tokens := 0
+	for _, msg := range b.Messages {
+		// Each message carries a few tokens of formatting
+		tokens += 4 + count(b.Model, rawText(msg.Content))
+	}
+	var inputs []string
+	var input string
+	if json.Unmarshal(b.Input, &inputs) == nil {
+		for _, text := range inputs {
+			tokens += count(b.Model, text)
+		}
+	} else if json.Unmarshal(b.Input, &input) == nil {
+		tokens += count(b.Model, input)
+	}
+	return tokens
+}
+
+// rawText returns the text of a message content, a string or a list of parts
+func rawText(content json.RawMessage) string {
+	var text string
+	if json.Unmarshal(content, &text) == nil {
+		return text
+	}
+	var parts []struct {
+		Text string `json:"text"`
+	}
+	json.Unmarshal(content, &parts)
+	for _, part := range parts {
+		text += part.Text
+	}
+	return text
+}
+
+// approximateTokens approximates the tokens of text, about 4 bytes per token
+func approximateTokens(_, text string) int {
+	return (len(text) + 3) / 4
+}
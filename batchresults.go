@@ -0,0 +1,190 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Kinds of batch request failures
+const (
+	BatchFailureRateLimit      = "rate_limit"
+	BatchFailureInvalidRequest = "invalid_request"
+	BatchFailureContentFilter  = "content_filter"
+	BatchFailureServer         = "server_error"
+	BatchFailureExpired        = "expired" // Not run within the completion window
+	BatchFailureOther          = "other"
+)
+
+// BatchResult is the outcome of a request of a batch
+type BatchResult struct {
+	CustomID   string
+	StatusCode int             // 0 when the request failed without a response
+	Body       json.RawMessage // Response body
+	Error      *BatchError
+}
+
+// BatchError is the error of a failed batch request
+type BatchError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Failed reports whether the request failed
+func (r *BatchResult) Failed() bool {
+	return r.Error != nil || r.StatusCode < 200 || r.StatusCode >= 300
+}
+
+// Failure classifies the failure of the request as one of the BatchFailure
+// kinds, or returns "" when the request succeeded
+func (r *BatchResult) Failure() string {
+	if !r.Failed() {
+		return ""
+	}
+
+	code := ""
+	if r.Error != nil {
+		code = r.Error.Code
+	}
+	// Errors of failed responses are in the body
+	var body ErrorResponse
+	if json.Unmarshal(r.Body, &body) == nil && body.Error.Code != "" {
+		code = body.Error.Code
+	}
+
+	switch {
+	case code == "batch_expired":
+		return BatchFailureExpired
+	case r.StatusCode == http.StatusTooManyRequests || strings.Contains(code, "rate_limit"):
+		return BatchFailureRateLimit
+	case code == "content_filter" || code == "content_policy_violation":
+		return BatchFailureContentFilter
+	case r.StatusCode >= 500:
+		return BatchFailureServer
+	case r.StatusCode >= 400 || code == "invalid_request" || code == "invalid_json" || code == "model_not_found":
+		return BatchFailureInvalidRequest
+	default:
+		return BatchFailureOther
+	}
+}
+
+// batchOutputLine is a line of a batch output or error file
+type batchOutputLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *BatchError `json:"error"`
+}
+
+// ParseBatchResults reads the results of a batch from its output or error file
+func ParseBatchResults(r io.Reader) ([]BatchResult, error) {
+	var results []BatchResult
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var out batchOutputLine
+		if err := json.Unmarshal(scanner.Bytes(), &out); err != nil {
+			return nil, fmt.Errorf("invalid batch result on line %d: %w", line, err)
+		}
+
+		result := BatchResult{CustomID: out.CustomID, Error: out.Error}
+		if out.Response != nil {
+			result.StatusCode = out.Response.StatusCode
+			result.Body = out.Response.Body
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch results: %w", err)
+	}
+	return results, nil
+}
+
+// BatchResults downloads the output and error files of a finished batch and
+// returns the results of all its requests
+func (c *Client) BatchResults(ctx context.Context, batch *Batch) ([]BatchResult, error) {
+	var results []BatchResult
+	for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+		var content bytes.Buffer
+		if err := c.DownloadFile(ctx, fileID, &content); err != nil {
+			return nil, err
+		}
+		fileResults, err := ParseBatchResults(&content)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, fileResults...)
+		c.emitProgress(ProgressEvent{Event: "batch_file_downloaded", ID: fileID, Done: len(results), Total: batch.RequestCounts.Total})
+	}
+	c.emitProgress(ProgressEvent{Event: "batch_results_collected", ID: batch.ID, Done: len(results), Total: batch.RequestCounts.Total})
+	return results, nil
+}
+
+// RetryBatchRequests returns the lines of the batch input whose requests
+// failed, to submit as a new batch. kinds restricts the retried failures, e.g.
+// to BatchFailureRateLimit and BatchFailureServer; all failures are retried
+// when empty.
+func RetryBatchRequests(input io.Reader, results []BatchResult, kinds ...string) ([]byte, error) {
+	failed := map[string]bool{}
+	for _, result := range results {
+		if failure := result.Failure(); failure != "" && (len(kinds) == 0 || slices.Contains(kinds, failure)) {
+			failed[result.CustomID] = true
+		}
+	}
+
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, 64<<10), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var request batchRequest
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+				continue
+			}
+			return nil, fmt.Errorf("invalid batch request on line %d: %w", line, err)
+		}
+		if failed[request.CustomID] {
+			out.Write(scanner.Bytes())
+			out.WriteByte('\n')
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch requests: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// MergeBatchResults combines the results of successive attempts of a batch,
+// oldest first: the result of a request is the first success, or its last
+// failure. Requests keep the order in which they first appear.
+func MergeBatchResults(attempts ...[]BatchResult) []BatchResult {
+	var merged []BatchResult
+	index := map[string]int{}
+	for _, results := range attempts {
+		for _, result := range results {
+			i, seen := index[result.CustomID]
+			switch {
+			case !seen:
+				index[result.CustomID] = len(merged)
+				merged = append(merged, result)
+			case merged[i].Failed():
+				merged[i] = result
+			}
+		}
+	}
+	return merged
+}
@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// BetaFeature is the OpenAI-Beta value required by the endpoints under a path
+type BetaFeature struct {
+	PathPrefix string // Path relative to the base URL, e.g. "/assistants"
+	Value      string // e.g. "assistants=v2"
+}
+
+// DefaultBetaFeatures lists the beta surfaces used by this package. The
+// values of every feature matching a request are sent, comma separated.
+var DefaultBetaFeatures = []BetaFeature{
+	{PathPrefix: "/assistants", Value: "assistants=v2"},
+	{PathPrefix: "/threads", Value: "assistants=v2"},
+	{PathPrefix: "/vector_stores", Value: "assistants=v2"},
+}
+
+// WithBetaFeature sends an OpenAI-Beta value with the requests to the
+// endpoints under pathPrefix, on top of DefaultBetaFeatures, e.g. to try a
+// new beta surface without waiting for a release of this package
+func WithBetaFeature(pathPrefix, value string) Option {
+	return func(c *Client) {
+		c.betaFeatures = append(c.betaFeatures, BetaFeature{PathPrefix: pathPrefix, Value: value})
+	}
+}
+
+// WithBetaHeader replaces the OpenAI-Beta header of the requests, e.g. to pin
+// another version of a beta surface for a call. An empty value sends none.
+// See WithRequestOptions.
+func WithBetaHeader(value string) RequestOption {
+	return func(config *requestConfig) {
+		config.beta = &value
+	}
+}
+
+// applyBeta sets the OpenAI-Beta header expected by the endpoint of the
+// request. Compatible servers get none.
+func (c *Client) applyBeta(req *http.Request) {
+	if c.compat != nil {
+		return
+	}
+	if config, ok := req.Context().Value(requestOptionsKey{}).(*requestConfig); ok && config.beta != nil {
+		if *config.beta != "" {
+			req.Header.Set("OpenAI-Beta", *config.beta)
+		}
+		return
+	}
+
+	path := c.relativePath(req)
+	var values []string
+	for _, features := range [][]BetaFeature{DefaultBetaFeatures, c.betaFeatures} {
+		for _, feature := range features {
+			prefix := strings.TrimRight(feature.PathPrefix, "/")
+			if (path == prefix || strings.HasPrefix(path, prefix+"/")) && !slices.Contains(values, feature.Value) {
+				values = append(values, feature.Value)
+			}
+		}
+	}
+	if len(values) > 0 {
+		req.Header.Set("OpenAI-Beta", strings.Join(values, ","))
+	}
+}
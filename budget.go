@@ -0,0 +1,179 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Budget periods
+const (
+	BudgetDaily   = "daily"
+	BudgetMonthly = "monthly"
+)
+
+// BudgetLimits caps the spend of a period. A zero field means no cap.
+type BudgetLimits struct {
+	Tokens  int64
+	Dollars float64
+}
+
+// BudgetConfig configures a BudgetGuard. Periods follow UTC calendar days and
+// months.
+type BudgetConfig struct {
+	Daily   BudgetLimits
+	Monthly BudgetLimits
+
+	// SoftRatio is the fraction of a limit at which OnSoftLimit is called,
+	// once per period and limit. It defaults to 0.8.
+	SoftRatio   float64
+	OnSoftLimit func(status BudgetStatus)
+
+	Pricing Pricing // Prices used for the dollar caps, DefaultPricing when nil
+}
+
+// BudgetStatus is the spend of a period
+type BudgetStatus struct {
+	Period  string // BudgetDaily or BudgetMonthly
+	Start   time.Time
+	Tokens  int64
+	Dollars float64
+	Limits  BudgetLimits
+}
+
+// BudgetExceededError is returned for calls made once a hard limit is reached
+type BudgetExceededError struct {
+	BudgetStatus
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s budget exceeded: %d tokens and $%.2f spent since %s (limits: %d tokens, $%.2f)",
+		e.Period, e.Tokens, e.Dollars, e.Start.Format("2006-01-02"), e.Limits.Tokens, e.Limits.Dollars)
+}
+
+// BudgetGuard refuses requests once the daily or monthly spend reaches its hard
+// limits, and warns at soft thresholds. Spend is computed from the usage of
+// chat, embeddings and run calls (see WithUsageHook) and the pricing table;
+// usage of models without a price counts toward the token caps only. A guard
+// can be shared by several clients.
+type BudgetGuard struct {
+	config BudgetConfig
+	now    func() time.Time // Reads the clock of the first client at call time
+
+	mu        sync.Mutex
+	periods   [2]*BudgetStatus
+	softFired map[string]bool
+}
+
+// NewBudgetGuard returns a guard enforcing the config
+func NewBudgetGuard(config BudgetConfig) *BudgetGuard {
+	if config.SoftRatio <= 0 || config.SoftRatio > 1 {
+		config.SoftRatio = 0.8
+	}
+	if config.Pricing == nil {
+		config.Pricing = DefaultPricing
+	}
+	return &BudgetGuard{
+		config: config,
+		periods: [2]*BudgetStatus{
+			{Period: BudgetDaily, Limits: config.Daily},
+			{Period: BudgetMonthly, Limits: config.Monthly},
+		},
+		softFired: map[string]bool{},
+	}
+}
+
+// WithBudgetGuard checks the guard before every request of the client and
+// records the client's usage in it. The guard uses the clock of the first
+// client it is installed on, as set by WithClock whatever the order of the
+// options.
+func WithBudgetGuard(guard *BudgetGuard) Option {
+	return func(c *Client) {
+		guard.mu.Lock()
+		if guard.now == nil {
+			guard.now = func() time.Time { return c.clock.Now() }
+		}
+		guard.mu.Unlock()
+
+		withRequestGuard(guard.check)(c)
+		WithUsageHook(guard.record)(c)
+	}
+}
+
+// Status returns the daily and monthly spend
+func (g *BudgetGuard) Status() (daily, monthly BudgetStatus) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.rollLocked()
+	return *g.periods[0], *g.periods[1]
+}
+
+func (g *BudgetGuard) check(*http.Request) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.rollLocked()
+	for _, status := range g.periods {
+		limits := status.Limits
+		if limits.Tokens > 0 && status.Tokens >= limits.Tokens || limits.Dollars > 0 && status.Dollars >= limits.Dollars {
+			return &BudgetExceededError{BudgetStatus: *status}
+		}
+	}
+	return nil
+}
+
+func (g *BudgetGuard) record(_ context.Context, usage Usage) {
+	cost, _ := g.config.Pricing.Cost(usage)
+
+	g.mu.Lock()
+	g.rollLocked()
+	var soft []BudgetStatus
+	for _, status := range g.periods {
+		status.Tokens += int64(usage.TotalTokens())
+		status.Dollars += cost
+
+		// Fire the soft callback once per period and kind of limit
+		ratio := g.config.SoftRatio
+		key := status.Period + status.Start.Format(time.DateOnly)
+		tokensSoft := status.Limits.Tokens > 0 && float64(status.Tokens) >= ratio*float64(status.Limits.Tokens)
+		dollarsSoft := status.Limits.Dollars > 0 && status.Dollars >= ratio*status.Limits.Dollars
+		if tokensSoft && !g.softFired[key+"tokens"] || dollarsSoft && !g.softFired[key+"dollars"] {
+			g.softFired[key+"tokens"] = g.softFired[key+"tokens"] || tokensSoft
+			g.softFired[key+"dollars"] = g.softFired[key+"dollars"] || dollarsSoft
+			soft = append(soft, *status)
+		}
+	}
+	g.mu.Unlock()
+
+	// Callbacks run outside the lock so they can call Status
+	if g.config.OnSoftLimit != nil {
+		for _, status := range soft {
+			g.config.OnSoftLimit(status)
+		}
+	}
+}
+
+// rollLocked starts new periods when the current ones are over, forgetting the
+// soft limits fired in the previous ones
+func (g *BudgetGuard) rollLocked() {
+	clock := systemClock{}.Now
+	if g.now != nil {
+		clock = g.now
+	}
+	now := clock().UTC()
+
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i, start := range []time.Time{day, month} {
+		status := g.periods[i]
+		if !status.Start.Equal(start) {
+			prefix := status.Period + status.Start.Format(time.DateOnly)
+			delete(g.softFired, prefix+"tokens")
+			delete(g.softFired, prefix+"dollars")
+			*status = BudgetStatus{Period: status.Period, Start: start, Limits: status.Limits}
+		}
+	}
+}
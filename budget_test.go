@@ -0,0 +1,54 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	openai "github.com/bhirbec/go-openai"
+	"github.com/bhirbec/go-openai/openaitest"
+)
+
+func TestBudgetGuardUsesClockSetAfterIt(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"model": "gpt-4o-mini", "choices": [{"message": {"role": "assistant", "content": "{\"queries\": [\"q\"]}"}}],
+			"usage": {"prompt_tokens": 1000, "completion_tokens": 0, "total_tokens": 1000}}`)
+	}))
+	defer srv.Close()
+
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := openaitest.NewFakeClock(start)
+	var soft []openai.BudgetStatus
+	guard := openai.NewBudgetGuard(openai.BudgetConfig{
+		Daily:       openai.BudgetLimits{Tokens: 1000},
+		OnSoftLimit: func(status openai.BudgetStatus) { soft = append(soft, status) },
+	})
+	// The guard comes before the clock on purpose
+	client := openai.NewClient("test", openai.WithBaseURL(srv.URL), openai.WithBudgetGuard(guard), openai.WithClock(clock))
+	ctx := context.Background()
+
+	if _, err := client.ExpandQuery(ctx, "refund policy", 1); err != nil {
+		t.Fatal(err)
+	}
+	daily, _ := guard.Status()
+	if want := start.Truncate(24 * time.Hour); !daily.Start.Equal(want) || daily.Tokens != 1000 {
+		t.Fatalf("daily status %+v, want 1000 tokens since %v", daily, want)
+	}
+	var exceeded *openai.BudgetExceededError
+	if _, err := client.ExpandQuery(ctx, "refund policy", 1); !errors.As(err, &exceeded) {
+		t.Fatalf("got error %v, want a BudgetExceededError", err)
+	}
+
+	clock.Advance(24 * time.Hour)
+	if _, err := client.ExpandQuery(ctx, "refund policy", 1); err != nil {
+		t.Fatalf("call on the next day failed: %v", err)
+	}
+	if len(soft) != 2 || soft[0].Start.Equal(soft[1].Start) {
+		t.Errorf("soft limit fired for %v, want once per day", soft)
+	}
+}
@@ -0,0 +1,78 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+)
+
+// defaultBundleBytes is the size of bundles when none is given
+const defaultBundleBytes = 1 << 20
+
+// TextBundle is a text document concatenating several small documents
+type TextBundle struct {
+	Name      string   // File name used for the upload, e.g. "docs.bundle001.txt"
+	Documents []string // Names of the documents in the bundle, in order
+	Content   []byte
+}
+
+// BundleDocuments concatenates small text documents into bundles of at most
+// maxBytes, each document starting with a header line naming it, so retrieved
+// chunks can still be traced back to their source. A document larger than
+// maxBytes gets a bundle of its own.
+//
+// The Files API neither accepts compressed uploads nor expands archives, so
+// bundling is how a corpus of thousands of tiny files is uploaded in a few
+// requests. Citations then point to the bundle rather than to the document.
+func BundleDocuments(prefix string, docs []Document, maxBytes int) []TextBundle {
+	if maxBytes <= 0 {
+		maxBytes = defaultBundleBytes
+	}
+
+	var (
+		bundles []TextBundle
+		current TextBundle
+		buf     bytes.Buffer
+	)
+	flush := func() {
+		if len(current.Documents) == 0 {
+			return
+		}
+		current.Name = fmt.Sprintf("%s.bundle%03d.txt", prefix, len(bundles)+1)
+		current.Content = bytes.Clone(buf.Bytes())
+		bundles = append(bundles, current)
+		current = TextBundle{}
+		buf.Reset()
+	}
+
+	for _, doc := range docs {
+		header := fmt.Sprintf("===== Document: %s =====\n", doc.Name)
+		size := len(header) + len(doc.Content) + 2
+		if buf.Len() > 0 && buf.Len()+size > maxBytes {
+			flush()
+		}
+
+		buf.WriteString(header)
+		buf.Write(doc.Content)
+		buf.WriteString("\n\n")
+		current.Documents = append(current.Documents, doc.Name)
+	}
+	flush()
+	return bundles
+}
+
+// UploadBundled bundles the documents with BundleDocuments and uploads every
+// bundle. It returns the IDs of the uploaded bundle files in order.
+func (c *Client) UploadBundled(ctx context.Context, prefix string, docs []Document, maxBytes int) ([]string, error) {
+	bundles := BundleDocuments(prefix, docs, maxBytes)
+
+	fileIDs := make([]string, 0, len(bundles))
+	for _, bundle := range bundles {
+		fileID, err := c.UploadContent(ctx, bundle.Name, bundle.Content)
+		if err != nil {
+			return fileIDs, fmt.Errorf("failed to upload bundle %s: %w", bundle.Name, err)
+		}
+		fileIDs = append(fileIDs, fileID)
+	}
+	return fileIDs, nil
+}
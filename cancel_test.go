@@ -0,0 +1,179 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	openai "github.com/bhirbec/go-openai"
+)
+
+// cancelDeadline is how long a cancelled call may take to return
+const cancelDeadline = time.Second
+
+// newCancelClient starts a server running handler and returns a client with
+// its own transport, so the connections it opens can be closed by cleanup
+// before checking for leaked goroutines. The server is only closed after the
+// check, so a request leaked in flight fails the test instead of hanging it.
+func newCancelClient(t *testing.T, handler http.HandlerFunc) *openai.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	goroutines := runtime.NumGoroutine()
+
+	transport := &http.Transport{}
+	t.Cleanup(func() {
+		transport.CloseIdleConnections()
+		checkGoroutines(t, goroutines)
+		srv.CloseClientConnections()
+		srv.Close()
+	})
+	return openai.NewClient("test", openai.WithBaseURL(srv.URL), openai.WithTransport(transport))
+}
+
+// checkGoroutines fails the test when more goroutines than want are still
+// running once they had time to exit
+func checkGoroutines(t *testing.T, want int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > want {
+		if time.Now().After(deadline) {
+			buf := make([]byte, 1<<16)
+			t.Errorf("%d goroutines running, want at most %d:\n%s", runtime.NumGoroutine(), want, buf[:runtime.Stack(buf, true)])
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// checkCancelled fails the test unless err is context.Canceled, returned
+// within cancelDeadline of cancelledAt
+func checkCancelled(t *testing.T, err error, cancelledAt time.Time) {
+	t.Helper()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("got error %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(cancelledAt); elapsed > cancelDeadline {
+		t.Errorf("returned %v after cancellation, want at most %v", elapsed, cancelDeadline)
+	}
+}
+
+// signalWriter closes started on its first write
+type signalWriter struct {
+	once    sync.Once
+	started chan struct{}
+}
+
+func (w *signalWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	return len(p), nil
+}
+
+func TestDownloadFileCancelledMidStream(t *testing.T) {
+	client := newCancelClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		io.WriteString(w, "first chunk")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	w := &signalWriter{started: make(chan struct{})}
+	result := make(chan error, 1)
+	go func() {
+		result <- client.DownloadFile(ctx, "file-1", w)
+	}()
+
+	<-w.started
+	cancelledAt := time.Now()
+	cancel()
+	checkCancelled(t, <-result, cancelledAt)
+}
+
+// runHandler answers run retrievals with an in-progress run, signalling
+// polled on each request. With block, it holds the request until the client
+// goes away.
+func runHandler(polled chan<- struct{}, block bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case polled <- struct{}{}:
+		default:
+		}
+		if block {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id": "run_1", "thread_id": "thread_1", "status": "in_progress"}`)
+	}
+}
+
+func TestWaitForRunCancelled(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		block bool
+	}{
+		{"request in flight", true},
+		{"between polls", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			polled := make(chan struct{}, 1)
+			client := newCancelClient(t, runHandler(polled, tc.block))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			result := make(chan error, 1)
+			go func() {
+				_, err := client.WaitForRun(ctx, "thread_1", "run_1", time.Minute)
+				result <- err
+			}()
+
+			<-polled
+			if !tc.block {
+				// Let the response arrive so the cancellation hits the wait
+				time.Sleep(50 * time.Millisecond)
+			}
+			cancelledAt := time.Now()
+			cancel()
+			checkCancelled(t, <-result, cancelledAt)
+		})
+	}
+}
+
+func TestPollerCancelled(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		block bool
+	}{
+		{"request in flight", true},
+		{"between polls", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			polled := make(chan struct{}, 1)
+			client := newCancelClient(t, runHandler(polled, tc.block))
+			poller := openai.NewPoller(client, time.Minute, 0)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			result := make(chan error, 1)
+			go func() {
+				_, err := poller.WaitForRun(ctx, "thread_1", "run_1")
+				result <- err
+			}()
+
+			<-polled
+			if !tc.block {
+				time.Sleep(50 * time.Millisecond)
+			}
+			cancelledAt := time.Now()
+			cancel()
+			checkCancelled(t, <-result, cancelledAt)
+		})
+	}
+}
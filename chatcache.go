@@ -0,0 +1,164 @@
+package openai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// ChatCacheStore stores the responses cached by a ChatCache, e.g. in memory
+// or in Redis. Values are JSON-encoded responses.
+type ChatCacheStore interface {
+	// Get returns the value of key, and false when it is missing or expired
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// ChatCache replays the responses of identical chat completion requests, e.g.
+// for batch enrichment jobs run over the same documents again. Only the calls
+// made with WithZeroTemperature are cached; they are keyed by base URL, model,
+// messages and every other parameter.
+//
+// go-openai omits a zero Temperature, which the API treats as 1, so requests
+// leaving Temperature unset are sampled and never cached. Cached responses do
+// not count as usage.
+type ChatCache struct {
+	store ChatCacheStore
+	ttl   time.Duration
+}
+
+// NewChatCache returns a cache keeping responses for ttl in store, or in an
+// unbounded MemoryChatStore when store is nil
+func NewChatCache(ttl time.Duration, store ChatCacheStore) *ChatCache {
+	if store == nil {
+		store = NewMemoryChatStore(0)
+	}
+	return &ChatCache{store: store, ttl: ttl}
+}
+
+// WithChatCache serves the chat completions of the client from the cache when
+// possible
+func WithChatCache(cache *ChatCache) Option {
+	return func(c *Client) {
+		c.chatCache = cache
+	}
+}
+
+// WithZeroTemperature sends chat completions with an explicit temperature of
+// 0, which go-openai omits when Temperature is 0, making their responses
+// cacheable by the ChatCache
+func WithZeroTemperature() RequestOption {
+	return WithExtraBody("temperature", 0)
+}
+
+// chatCacheKey identifies a request. It returns false when the request is not
+// explicitly sent with a zero temperature or cannot be encoded.
+func (c *Client) chatCacheKey(ctx context.Context, req openai.ChatCompletionRequest) (string, bool) {
+	if req.Temperature != 0 || req.N > 1 || req.Stream {
+		return "", false
+	}
+	// The temperature is sent with WithExtraBody, not visible in the request
+	config, ok := ctx.Value(requestOptionsKey{}).(*requestConfig)
+	if !ok || compactJSON(config.extraBody["temperature"]) != "0" {
+		return "", false
+	}
+
+	encoded, err := json.Marshal(struct {
+		Request   openai.ChatCompletionRequest
+		ExtraBody map[string]interface{}
+	}{req, config.extraBody})
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(append([]byte(c.baseURL+"\x00"), encoded...))
+	return "chat:" + hex.EncodeToString(sum[:]), true
+}
+
+func (cc *ChatCache) get(ctx context.Context, key string) (openai.ChatCompletionResponse, bool, error) {
+	var resp openai.ChatCompletionResponse
+	value, ok, err := cc.store.Get(ctx, key)
+	if err != nil || !ok {
+		return resp, false, err
+	}
+	if err := json.Unmarshal(value, &resp); err != nil {
+		return resp, false, err
+	}
+	return resp, true, nil
+}
+
+func (cc *ChatCache) put(ctx context.Context, key string, resp openai.ChatCompletionResponse) error {
+	value, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return cc.store.Set(ctx, key, value, cc.ttl)
+}
+
+// MemoryChatStore is a ChatCacheStore keeping values in memory. It is safe for
+// concurrent use.
+type MemoryChatStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]memoryChatEntry
+}
+
+type memoryChatEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryChatStore returns a store holding at most maxEntries values (0 for
+// no limit)
+func NewMemoryChatStore(maxEntries int) *MemoryChatStore {
+	return &MemoryChatStore{maxEntries: maxEntries, entries: map[string]memoryChatEntry{}}
+}
+
+func (s *MemoryChatStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !time.Now().Before(entry.expiresAt) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (s *MemoryChatStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.maxEntries > 0 && len(s.entries) >= s.maxEntries {
+		s.evictLocked(now)
+	}
+	s.entries[key] = memoryChatEntry{value: value, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// evictLocked drops the expired entries, or the entry expiring first when none is
+func (s *MemoryChatStore) evictLocked(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, entry.expiresAt
+		}
+	}
+	if len(s.entries) >= s.maxEntries {
+		delete(s.entries, oldestKey)
+	}
+}
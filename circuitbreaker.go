@@ -0,0 +1,121 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is wrapped by the errors of requests rejected without being
+// sent because the circuit breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreaker stops sending requests after consecutive failures, so a long
+// ingestion job fails fast instead of hammering a degraded API. Server errors
+// (5xx), timeouts and network errors count as failures; other responses close
+// the circuit again. After the cool-down, a single request is let through to
+// probe the API. It is safe for concurrent use and can be shared by several
+// clients.
+type CircuitBreaker struct {
+	threshold int
+	coolDown  time.Duration
+
+	mu       sync.Mutex
+	failures int       // Consecutive failures
+	openedAt time.Time // Zero while closed
+	probing  bool      // Whether a probe request is in flight
+}
+
+// NewCircuitBreaker returns a breaker opening after threshold consecutive
+// failures and staying open for coolDown
+func NewCircuitBreaker(threshold int, coolDown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{threshold: max(threshold, 1), coolDown: coolDown}
+}
+
+// WithCircuitBreaker makes the client check the breaker before every request,
+// retries included
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(c *Client) {
+		c.circuitBreaker = breaker
+	}
+}
+
+// Open reports whether requests are rejected at the given time
+func (b *CircuitBreaker) Open(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.openedAt.IsZero() && (now.Sub(b.openedAt) < b.coolDown || b.probing)
+}
+
+// allow returns an error wrapping ErrCircuitOpen when the request must not be
+// sent. Once the cool-down is over, the first caller becomes the probe, which
+// must be reported to record.
+func (b *CircuitBreaker) allow(now time.Time) (probe bool, err error) {
+	if b == nil {
+		return false, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return false, nil
+	}
+	if remaining := b.coolDown - now.Sub(b.openedAt); remaining > 0 {
+		return false, fmt.Errorf("%w, retry in %s", ErrCircuitOpen, remaining.Round(time.Millisecond))
+	}
+	if b.probing {
+		return false, fmt.Errorf("%w, probing the API", ErrCircuitOpen)
+	}
+	b.probing = true
+	return true, nil
+}
+
+// record updates the breaker with the outcome of a request and reports whether
+// it just opened. While the circuit is open, only the outcome of the probe
+// counts: requests sent before it opened neither close it nor push the
+// cool-down back.
+func (b *CircuitBreaker) record(now time.Time, failed, probe bool) bool {
+	if b == nil {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+		if failed {
+			b.failures++
+			b.openedAt = now
+			return true
+		}
+		b.failures = 0
+		b.openedAt = time.Time{}
+		return false
+	}
+	if !b.openedAt.IsZero() {
+		return false
+	}
+
+	if !failed {
+		b.failures = 0
+		return false
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = now
+		return true
+	}
+	return false
+}
+
+// breakerFailure reports whether the outcome of an attempt counts as a failure.
+// Requests cancelled by the caller do not.
+func breakerFailure(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil || errors.Is(err, ErrTimeout)
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
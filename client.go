@@ -0,0 +1,216 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// DefaultBaseURL is the root of the OpenAI API used unless WithBaseURL is given
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// Client calls the OpenAI API with its own API key and configuration. Several
+// clients can be used side by side in the same process.
+type Client struct {
+	// Service accessors, e.g. client.VectorStores.Files.Create
+	Assistants   *AssistantsAPI
+	Threads      *ThreadsAPI
+	Files        *FilesAPI
+	VectorStores *VectorStoresAPI
+	Batches      *BatchesAPI
+	Models       *ModelsAPI
+	Images       *ImagesAPI
+	Audio        *AudioAPI
+	Moderations  *ModerationsAPI
+
+	apiKey         string
+	baseURL        string
+	httpClient     *http.Client
+	clock          Clock
+	retryPolicy    RetryPolicy
+	rateLimiter    *RateLimiter
+	circuitBreaker *CircuitBreaker
+	timeouts       Timeouts
+	modelRouter    ModelRouter
+	logger         Logger
+	azure          *AzureConfig
+	compat         *CompatProfile
+	searchCache    *SearchCache
+	chatCache      *ChatCache
+	semanticCache  *SemanticCache
+	tenant         string // Name of the tenant of a TenantManager client
+	auditSink      AuditSink
+	ingestion      IngestionObserver
+	progress       *progressWriter
+	metrics        Metrics
+
+	noIdempotencyKeys bool
+	streamTranscripts bool
+	gzip              bool
+	gzipMinBytes      int
+	headers           http.Header
+	defaultMetadata   map[string]string
+	metadataPolicy    MetadataPolicy
+	usageHooks        []func(context.Context, Usage)
+	costs             *costTracker
+	recordedRuns      runSet
+	requestGuards     []func(*http.Request) error
+	betaFeatures      []BetaFeature
+
+	onRateLimitInfo func(RateLimitInfo)
+	onRateLimited   func(context.Context, *RateLimitError)
+	safetyPolicy    func(context.Context, *SafetyReport) error
+	toolOutputLimit ToolOutputLimit
+	downloadPolicy  *DownloadPolicy
+
+	life *lifecycle
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// NewClient returns a client authenticating with the given API key
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
+		apiKey:      apiKey,
+		baseURL:     DefaultBaseURL,
+		httpClient:  &http.Client{Transport: sharedTransport},
+		clock:       systemClock{},
+		retryPolicy: DefaultRetryPolicy,
+		logger:      nopLogger{},
+		costs:       newCostTracker(),
+		life:        newLifecycle(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.logger = correlatedLogger{c.logger}
+	c.initServices()
+	c.applyConnectTimeout()
+	return c
+}
+
+// WithBaseURL sets the root URL of the API, e.g. to go through a proxy or
+// gateway, or to reach a mock server. Paths such as "/threads" are appended to it.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient makes the client send all its requests with httpClient, e.g. to
+// share a connection pool or add instrumentation. A nil httpClient means
+// http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient == nil {
+			httpClient = http.DefaultClient
+		}
+		c.httpClient = httpClient
+	}
+}
+
+// WithTransport sends all requests through the given RoundTripper, e.g. for
+// custom TLS settings or a corporate proxy
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Client) {
+		httpClient := *c.httpClient
+		httpClient.Transport = transport
+		c.httpClient = &httpClient
+	}
+}
+
+// BaseURL returns the root URL of the API used by the client
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// send authenticates the request and executes it, retrying transient failures
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	ctx, done, err := c.track(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := c.sendTracked(req)
+	if err != nil {
+		done()
+		return nil, err
+	}
+	resp.Body = &trackedBody{ReadCloser: resp.Body, done: done}
+	return resp, nil
+}
+
+// sendTracked prepares the request and executes it
+func (c *Client) sendTracked(req *http.Request) (*http.Response, error) {
+	if err := c.checkGuards(req); err != nil {
+		return nil, err
+	}
+	c.authorize(req)
+	if err := c.applyDefaults(req); err != nil {
+		return nil, err
+	}
+	if err := applyRequestOptions(req); err != nil {
+		return nil, err
+	}
+	if err := c.applyCorrelationID(req); err != nil {
+		return nil, err
+	}
+	c.setIdempotencyKey(req)
+	resp, err := c.sendWithRetries(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.onRateLimitInfo != nil {
+		if info, ok := parseRateLimitInfo(resp.Header); ok {
+			c.onRateLimitInfo(info)
+		}
+	}
+	return resp, nil
+}
+
+// relativePath returns the path of the request relative to the base URL, e.g.
+// "/threads/thread_abc/runs"
+func (c *Client) relativePath(req *http.Request) string {
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return req.URL.Path
+	}
+	return strings.TrimPrefix(req.URL.Path, strings.TrimRight(base.Path, "/"))
+}
+
+// sdkClient returns a go-openai client sharing this client's configuration, used
+// for the endpoints this package does not implement itself (chat, embeddings)
+func (c *Client) sdkClient() *openai.Client {
+	config := openai.DefaultConfig(c.apiKey)
+	config.BaseURL = c.baseURL
+	if c.azure != nil {
+		config = openai.DefaultAzureConfig(c.apiKey, c.azure.Endpoint)
+		config.APIVersion = c.azure.APIVersion
+		config.AzureModelMapperFunc = c.azure.deployment
+	}
+	config.HTTPClient = sdkDoer{c}
+	return openai.NewClientWithConfig(config)
+}
+
+// sdkDoer routes the requests of the go-openai client through send so they get
+// the same retries and hooks as the other requests
+type sdkDoer struct {
+	client *Client
+}
+
+// Do returns 429 responses as a RateLimitError, since the go-openai client drops
+// the headers carrying the delay
+func (d sdkDoer) Do(req *http.Request) (*http.Response, error) {
+	resp, err := d.client.send(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		return resp, err
+	}
+	defer resp.Body.Close()
+	return nil, d.client.responseError(resp)
+}
@@ -0,0 +1,36 @@
+package openai_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	openai "github.com/bhirbec/go-openai"
+	"github.com/bhirbec/go-openai/openaitest"
+)
+
+// countingTransport counts the requests it forwards to the default transport
+type countingTransport struct{ requests atomic.Int32 }
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestWithTransportAfterNilHTTPClient(t *testing.T) {
+	srv := openaitest.NewServer()
+	defer srv.Close()
+	transport := &countingTransport{}
+	client := openai.NewClient("test", openai.WithBaseURL(srv.URL), openai.WithHTTPClient(nil), openai.WithTransport(transport))
+
+	if _, err := client.ListAssistants(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := transport.requests.Load(); got != 1 {
+		t.Errorf("%d requests went through the transport, want 1", got)
+	}
+	if http.DefaultClient.Transport != nil {
+		t.Error("WithTransport changed http.DefaultClient")
+	}
+}
@@ -0,0 +1,42 @@
+package openai
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time used by the client when polling, retrying and rate
+// limiting. Tests can inject a fake implementation with WithClock so waits return
+// instantly and deterministically.
+type Clock interface {
+	Now() time.Time
+
+	// Sleep pauses for d, returning ctx.Err() early if ctx is done
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// systemClock is the Clock backed by the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WithClock replaces the clock used for polling, retries and rate limiting
+func WithClock(clock Clock) Option {
+	return func(c *Client) {
+		c.clock = clock
+	}
+}
@@ -0,0 +1,32 @@
+package openai
+
+// CompatProfile adapts the client to a self-hosted server implementing the
+// OpenAI API, such as Ollama, vLLM or LM Studio
+type CompatProfile struct {
+	// Models maps the OpenAI models used by this package, such as
+	// "gpt-4o-mini" or "text-embedding-3-small", to models served by the
+	// backend. Unmapped models are sent unchanged.
+	Models map[string]string
+}
+
+// WithCompatProfile sends the requests to an OpenAI-compatible server at
+// baseURL. The OpenAI-Beta header is not sent, the Authorization header is
+// only sent when the client has an API key, models are renamed per the
+// profile, and response fields such as the index of embeddings may be missing.
+func WithCompatProfile(baseURL string, profile CompatProfile) Option {
+	return func(c *Client) {
+		WithBaseURL(baseURL)(c)
+		c.compat = &profile
+	}
+}
+
+// mapModel returns the model to request from the backend
+func (c *Client) mapModel(model string) string {
+	if c.compat == nil {
+		return model
+	}
+	if mapped, ok := c.compat.Models[model]; ok {
+		return mapped
+	}
+	return model
+}
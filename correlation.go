@@ -0,0 +1,110 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+)
+
+// CorrelationMetadataKey is the metadata key holding the correlation ID of the
+// threads, messages and runs created with one, see WithCorrelationID
+const CorrelationMetadataKey = "correlation_id"
+
+// WithCorrelationID ties the calls made with the context to an ID chosen by the
+// caller, e.g. a support ticket or the request ID of the user's session, so a
+// complaint can be traced to the exact run. The ID is:
+//   - stored in the metadata of the threads, messages and runs created, under
+//     CorrelationMetadataKey, unless the caller sets that key
+//   - sent as the X-Client-Request-Id header, which OpenAI keeps in its logs
+//   - added to the logs and audit records of the calls
+//
+// See WithRequestOptions and FindAuditRecords.
+func WithCorrelationID(id string) RequestOption {
+	return func(config *requestConfig) {
+		config.correlationID = id
+	}
+}
+
+// CorrelationID returns the correlation ID carried by the context, if any
+func CorrelationID(ctx context.Context) string {
+	if config, ok := ctx.Value(requestOptionsKey{}).(*requestConfig); ok {
+		return config.correlationID
+	}
+	return ""
+}
+
+// correlatedPath matches the paths of the POST endpoints creating threads,
+// messages and runs
+var correlatedPath = regexp.MustCompile(`^/threads(/runs|/[^/]+/(messages|runs))?$`)
+
+// applyCorrelationID propagates the correlation ID of the request context
+func (c *Client) applyCorrelationID(req *http.Request) error {
+	id := CorrelationID(req.Context())
+	if id == "" {
+		return nil
+	}
+
+	if req.Header.Get("X-Client-Request-Id") == "" {
+		req.Header.Set("X-Client-Request-Id", id)
+	}
+	if req.Method != http.MethodPost || !correlatedPath.MatchString(c.relativePath(req)) {
+		return nil
+	}
+	return addMetadata(req, map[string]string{CorrelationMetadataKey: id}, true)
+}
+
+// correlatedLogger adds the correlation ID of the context to the logs
+type correlatedLogger struct {
+	Logger
+}
+
+func (l correlatedLogger) DebugContext(ctx context.Context, msg string, args ...any) {
+	l.Logger.DebugContext(ctx, msg, correlatedArgs(ctx, args)...)
+}
+
+func (l correlatedLogger) InfoContext(ctx context.Context, msg string, args ...any) {
+	l.Logger.InfoContext(ctx, msg, correlatedArgs(ctx, args)...)
+}
+
+func (l correlatedLogger) WarnContext(ctx context.Context, msg string, args ...any) {
+	l.Logger.WarnContext(ctx, msg, correlatedArgs(ctx, args)...)
+}
+
+func (l correlatedLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.Logger.ErrorContext(ctx, msg, correlatedArgs(ctx, args)...)
+}
+
+func correlatedArgs(ctx context.Context, args []any) []any {
+	if id := CorrelationID(ctx); id != "" {
+		return append(args[:len(args):len(args)], "correlation_id", id)
+	}
+	return args
+}
+
+// FindAuditRecords reads the audit records written by a JSONLAuditSink, e.g.
+// from the log file, and returns those of the calls made with a correlation ID
+func FindAuditRecords(r io.Reader, correlationID string) ([]AuditRecord, error) {
+	var records []AuditRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to decode audit record on line %d: %w", line, err)
+		}
+		if record.CorrelationID == correlationID {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit records: %w", err)
+	}
+	return records, nil
+}
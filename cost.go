@@ -0,0 +1,103 @@
+package openai
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// CostTotals is the usage and cost of a group of requests
+type CostTotals struct {
+	Requests         int
+	PromptTokens     int64
+	CompletionTokens int64
+	Dollars          float64
+}
+
+// add counts a usage costing dollars
+func (t *CostTotals) add(usage Usage, dollars float64) {
+	t.Requests++
+	t.PromptTokens += int64(usage.PromptTokens)
+	t.CompletionTokens += int64(usage.CompletionTokens)
+	t.Dollars += dollars
+}
+
+// CostSummary is the usage and cost of the calls made by a client since it was
+// created
+type CostSummary struct {
+	CostTotals
+	ByModel    map[string]CostTotals
+	ByEndpoint map[string]CostTotals // By EndpointChat, EndpointRun or EndpointEmbeddings
+
+	// UnpricedModels lists the models used without a price in the pricing
+	// table. Their tokens are counted but cost nothing.
+	UnpricedModels []string
+}
+
+// WithPricing sets the prices used by CostSummary, DefaultPricing by default
+func WithPricing(pricing Pricing) Option {
+	return func(c *Client) {
+		c.costs.pricing = pricing
+	}
+}
+
+// CostSummary returns the usage and cost of the chat, embeddings and run calls
+// of the client, as reported to the usage hooks
+func (c *Client) CostSummary() CostSummary {
+	return c.costs.summary()
+}
+
+// costTracker accumulates the usage of a client
+type costTracker struct {
+	pricing Pricing
+
+	mu         sync.Mutex
+	total      CostTotals
+	byModel    map[string]CostTotals
+	byEndpoint map[string]CostTotals
+	unpriced   map[string]bool
+}
+
+func newCostTracker() *costTracker {
+	return &costTracker{
+		pricing:    DefaultPricing,
+		byModel:    map[string]CostTotals{},
+		byEndpoint: map[string]CostTotals{},
+		unpriced:   map[string]bool{},
+	}
+}
+
+// record counts the usage of a request
+func (t *costTracker) record(usage Usage) {
+	dollars, ok := t.pricing.Cost(usage)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !ok {
+		t.unpriced[usage.Model] = true
+	}
+	t.total.add(usage, dollars)
+
+	model := t.byModel[usage.Model]
+	model.add(usage, dollars)
+	t.byModel[usage.Model] = model
+
+	endpoint := t.byEndpoint[usage.Endpoint]
+	endpoint.add(usage, dollars)
+	t.byEndpoint[usage.Endpoint] = endpoint
+}
+
+func (t *costTracker) summary() CostSummary {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	summary := CostSummary{
+		CostTotals: t.total,
+		ByModel:    maps.Clone(t.byModel),
+		ByEndpoint: maps.Clone(t.byEndpoint),
+	}
+	for model := range t.unpriced {
+		summary.UnpricedModels = append(summary.UnpricedModels, model)
+	}
+	slices.Sort(summary.UnpricedModels)
+	return summary
+}
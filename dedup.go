@@ -0,0 +1,81 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Document is a named piece of content to be attached to a vector store
+type Document struct {
+	Name    string
+	Content []byte
+}
+
+// DuplicateCluster groups the documents that were found to be near-duplicates
+// of a kept document
+type DuplicateCluster struct {
+	Kept       string             // Name of the document that was kept
+	Duplicates []string           // Names of the skipped documents
+	Similarity map[string]float64 // Cosine similarity of each duplicate to the kept document
+}
+
+// DedupResult is the outcome of DedupDocuments
+type DedupResult struct {
+	Kept     []Document
+	Skipped  []Document
+	Clusters []DuplicateCluster
+}
+
+// DedupDocuments embeds the documents and drops near-duplicates: a document whose
+// cosine similarity to an already kept document is at least threshold is skipped
+// and reported in the cluster of that document. Documents are considered in order,
+// so the first copy of a boilerplate text is the one kept.
+func (c *Client) DedupDocuments(ctx context.Context, docs []Document, threshold float64) (*DedupResult, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("threshold must be in (0, 1], got %v", threshold)
+	}
+
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = string(doc.Content)
+	}
+	vectors, err := c.embedTexts(ctx, texts, openai.SmallEmbedding3)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DedupResult{}
+	var keptVectors [][]float32
+	clusterOf := map[int]int{} // index in result.Kept -> index in result.Clusters
+
+	for i, doc := range docs {
+		best, bestScore := -1, 0.0
+		for j, kept := range keptVectors {
+			if score := cosineSimilarity(vectors[i], kept); score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+
+		if best < 0 || bestScore < threshold {
+			result.Kept = append(result.Kept, doc)
+			keptVectors = append(keptVectors, vectors[i])
+			continue
+		}
+
+		cluster, ok := clusterOf[best]
+		if !ok {
+			cluster = len(result.Clusters)
+			clusterOf[best] = cluster
+			result.Clusters = append(result.Clusters, DuplicateCluster{
+				Kept:       result.Kept[best].Name,
+				Similarity: map[string]float64{},
+			})
+		}
+		result.Clusters[cluster].Duplicates = append(result.Clusters[cluster].Duplicates, doc.Name)
+		result.Clusters[cluster].Similarity[doc.Name] = bestScore
+		result.Skipped = append(result.Skipped, doc)
+	}
+	return result, nil
+}
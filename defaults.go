@@ -0,0 +1,97 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// WithHeader sets a header on every request of the client, e.g.
+// WithHeader("OpenAI-Project", projectID)
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		c.headers.Set(key, value)
+	}
+}
+
+// WithDefaultMetadata adds metadata to the assistants, threads, messages, runs
+// and vector stores created by the client, e.g. to tag resources with a tenant
+// or environment. Keys set by the caller take precedence. Updates get the
+// defaults only when they set metadata, as it replaces the existing metadata
+// as a whole.
+func WithDefaultMetadata(metadata map[string]string) Option {
+	return func(c *Client) {
+		if c.defaultMetadata == nil {
+			c.defaultMetadata = map[string]string{}
+		}
+		for k, v := range metadata {
+			c.defaultMetadata[k] = v
+		}
+	}
+}
+
+// createPath matches the paths of the POST endpoints creating objects with
+// metadata
+var createPath = regexp.MustCompile(`^/(assistants|threads(/runs|/[^/]+/(messages|runs))?|vector_stores)$`)
+
+// modifyPath matches the paths of the POST endpoints modifying objects with
+// metadata
+var modifyPath = regexp.MustCompile(`^/(assistants/[^/]+|threads/[^/]+(/(messages|runs)/[^/]+)?|vector_stores/[^/]+)$`)
+
+// applyDefaults sets the User-Agent, OpenAI-Beta, client headers and default
+// metadata on the request, then applies the metadata policy. Headers given
+// with WithHeader replace the default ones.
+func (c *Client) applyDefaults(req *http.Request) error {
+	req.Header.Set("User-Agent", userAgent)
+	c.applyBeta(req)
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+
+	if req.Method != http.MethodPost {
+		return nil
+	}
+	path := c.relativePath(req)
+	create := createPath.MatchString(path)
+	if !create && !modifyPath.MatchString(path) {
+		return nil
+	}
+	if len(c.defaultMetadata) > 0 {
+		if err := addMetadata(req, c.defaultMetadata, create); err != nil {
+			return err
+		}
+	}
+	return c.checkMetadata(req)
+}
+
+// addMetadata adds the keys of metadata missing from the metadata of a JSON
+// request body. Unless create is set, a body without metadata is left alone:
+// sending metadata in an update would erase the keys of the object.
+func addMetadata(req *http.Request, metadata map[string]string, create bool) error {
+	return rewriteJSONBody(req, func(fields map[string]json.RawMessage) error {
+		merged := map[string]string{}
+		raw, ok := fields["metadata"]
+		if !ok || string(raw) == "null" {
+			if !create {
+				return nil
+			}
+		} else if err := json.Unmarshal(raw, &merged); err != nil {
+			return fmt.Errorf("failed to decode metadata: %w", err)
+		}
+		for k, v := range metadata {
+			if _, ok := merged[k]; !ok {
+				merged[k] = v
+			}
+		}
+		raw, err := json.Marshal(merged)
+		if err != nil {
+			return err
+		}
+		fields["metadata"] = raw
+		return nil
+	})
+}
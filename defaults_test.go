@@ -0,0 +1,44 @@
+package openai_test
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	openai "github.com/bhirbec/go-openai"
+	"github.com/bhirbec/go-openai/openaitest"
+)
+
+func TestDefaultMetadataKeptOnToolResourcesUpdate(t *testing.T) {
+	srv := openaitest.NewServer()
+	defer srv.Close()
+	client := openai.NewClient("test", openai.WithBaseURL(srv.URL), openai.WithDefaultMetadata(map[string]string{"env": "test"}))
+	ctx := context.Background()
+
+	assistant, err := client.CreateAssistant(ctx, &openai.CreateAssistantParams{Model: "gpt-4o-mini", Metadata: openai.Metadata{"owner": "search"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"env": "test", "owner": "search"}
+	if got := map[string]string(assistant.Metadata); !maps.Equal(got, want) {
+		t.Fatalf("created assistant has metadata %v, want %v", got, want)
+	}
+
+	store, err := client.CreateVectorStore(ctx, &openai.CreateVectorStoreParams{Name: "docs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.AttachVectorStore(ctx, assistant.ID, store.ID); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := client.RetrieveAssistant(ctx, assistant.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := map[string]string(updated.Metadata); !maps.Equal(got, want) {
+		t.Errorf("updated assistant has metadata %v, want %v", got, want)
+	}
+	if updated.ToolResources == nil || updated.ToolResources.FileSearch == nil || len(updated.ToolResources.FileSearch.VectorStoreIDs) != 1 {
+		t.Errorf("vector store not attached: %+v", updated.ToolResources)
+	}
+}
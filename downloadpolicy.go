@@ -0,0 +1,231 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Errors wrapped by a DownloadError
+var (
+	ErrContentTypeNotAllowed = errors.New("content type not allowed")
+	ErrFileTooLarge          = errors.New("file too large")
+)
+
+// DownloadPolicy restricts the files returned by DownloadFile and ForwardFile,
+// e.g. before forwarding files generated by the model to end users
+type DownloadPolicy struct {
+	// AllowedTypes lists the media types accepted, e.g. "image/png", or
+	// "image/*" for a whole family. All types are accepted when empty.
+	AllowedTypes []string
+
+	// MaxBytes is the largest content accepted, 0 for no limit
+	MaxBytes int64
+
+	// AllowExecutables accepts ELF, PE and Mach-O binaries, which are
+	// rejected whatever their declared type
+	AllowExecutables bool
+}
+
+// WithDownloadPolicy checks the files downloaded by DownloadFile and
+// ForwardFile against policy. The content type checked is the one of the
+// response or, when the API answers with application/octet-stream, guessed
+// from the file name then sniffed from the content. The content is also
+// sniffed for executables.
+func WithDownloadPolicy(policy DownloadPolicy) Option {
+	return func(c *Client) {
+		c.downloadPolicy = &policy
+	}
+}
+
+// DownloadError is returned when a downloaded file breaks the download
+// policy. It wraps ErrContentTypeNotAllowed or ErrFileTooLarge.
+type DownloadError struct {
+	FileID      string
+	ContentType string
+	Size        int64 // Bytes announced or read when the file was rejected, -1 when unknown
+	Err         error
+}
+
+func (e *DownloadError) Error() string {
+	return fmt.Sprintf("file %s rejected: %v (%s, %d bytes)", e.FileID, e.Err, e.ContentType, e.Size)
+}
+
+func (e *DownloadError) Unwrap() error {
+	return e.Err
+}
+
+// fileContent is the body of a file download, checked against the download
+// policy as it is read
+type fileContent struct {
+	io.Reader
+	body        io.Closer
+	ContentType string
+	Size        int64 // -1 when unknown
+}
+
+func (f *fileContent) Close() error {
+	return f.body.Close()
+}
+
+// openFileContent requests the content of a file, leaving the caller to read
+// and close it. fileName, when known, refines a generic content type. The
+// content type and announced size are checked before returning; content
+// outgrowing MaxBytes fails the read that crosses the limit.
+func (c *Client) openFileContent(ctx context.Context, fileID, fileName string) (*fileContent, error) {
+	req, err := c.newRequest(ctx, "GET", "/files/"+fileID+"/content", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("file download failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("file download failed: %w", c.responseError(resp))
+	}
+
+	content := &fileContent{
+		Reader:      resp.Body,
+		body:        resp.Body,
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+	}
+	if fileName == "" {
+		if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+			fileName = params["filename"]
+		}
+	}
+	if isGenericType(content.ContentType) {
+		if byName := mime.TypeByExtension(path.Ext(fileName)); byName != "" {
+			content.ContentType = byName
+		}
+	}
+
+	policy := c.downloadPolicy
+	if policy == nil {
+		return content, nil
+	}
+	reject := func(err error) (*fileContent, error) {
+		resp.Body.Close()
+		c.logger.WarnContext(ctx, "file download rejected", "file_id", fileID, "content_type", content.ContentType, "bytes", content.Size, "error", err)
+		return nil, &DownloadError{FileID: fileID, ContentType: content.ContentType, Size: content.Size, Err: err}
+	}
+
+	buffered := bufio.NewReaderSize(resp.Body, 512)
+	head, err := buffered.Peek(512)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		resp.Body.Close()
+		return nil, fmt.Errorf("file download failed: %w", err)
+	}
+	content.Reader = buffered
+	if isGenericType(content.ContentType) {
+		content.ContentType = http.DetectContentType(head)
+	}
+
+	if policy.MaxBytes > 0 && content.Size > policy.MaxBytes {
+		return reject(ErrFileTooLarge)
+	}
+	if executable := executableType(head); executable != "" && !policy.AllowExecutables {
+		content.ContentType = executable
+		return reject(ErrContentTypeNotAllowed)
+	}
+	if !policy.allows(content.ContentType) {
+		return reject(ErrContentTypeNotAllowed)
+	}
+	if policy.MaxBytes > 0 {
+		content.Reader = &cappedReader{r: content.Reader, remaining: policy.MaxBytes, rejected: func(read int64) error {
+			c.logger.WarnContext(ctx, "file download rejected", "file_id", fileID, "content_type", content.ContentType, "bytes", read, "error", ErrFileTooLarge)
+			return &DownloadError{FileID: fileID, ContentType: content.ContentType, Size: read, Err: ErrFileTooLarge}
+		}}
+	}
+	return content, nil
+}
+
+// isGenericType reports whether contentType says nothing of the content
+func isGenericType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "" || mediaType == "application/octet-stream"
+}
+
+// allows reports whether the media type of contentType is in the allowlist
+func (p *DownloadPolicy) allows(contentType string) bool {
+	if len(p.AllowedTypes) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range p.AllowedTypes {
+		allowed = strings.ToLower(allowed)
+		if allowed == mediaType {
+			return true
+		}
+		if family, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(mediaType, family+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// executableType returns the media type of the executable starting with head,
+// or "" when head is not the start of a known executable format
+func executableType(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("\x7fELF")):
+		return "application/x-executable"
+	case isPortableExecutable(head):
+		return "application/vnd.microsoft.portable-executable"
+	case bytes.HasPrefix(head, []byte{0xfe, 0xed, 0xfa, 0xce}),
+		bytes.HasPrefix(head, []byte{0xfe, 0xed, 0xfa, 0xcf}),
+		bytes.HasPrefix(head, []byte{0xce, 0xfa, 0xed, 0xfe}),
+		bytes.HasPrefix(head, []byte{0xcf, 0xfa, 0xed, 0xfe}):
+		return "application/x-mach-binary"
+	}
+	return ""
+}
+
+// isPortableExecutable reports whether head starts with an MZ header pointing
+// to a PE signature, so text merely starting with "MZ" is not mistaken for one
+func isPortableExecutable(head []byte) bool {
+	if len(head) < 0x40 || !bytes.HasPrefix(head, []byte("MZ")) {
+		return false
+	}
+	offset := int64(binary.LittleEndian.Uint32(head[0x3c:]))
+	return offset+4 <= int64(len(head)) && bytes.Equal(head[offset:offset+4], []byte("PE\x00\x00"))
+}
+
+// cappedReader fails once more than remaining bytes were read
+type cappedReader struct {
+	r         io.Reader
+	remaining int64
+	read      int64
+	rejected  func(read int64) error
+	err       error
+}
+
+func (r *cappedReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.r.Read(p)
+	r.read += int64(n)
+	if r.remaining -= int64(n); r.remaining < 0 {
+		r.err = r.rejected(r.read)
+		return 0, r.err
+	}
+	return n, err
+}
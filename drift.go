@@ -0,0 +1,141 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// EmbeddedDocument is a document along with the embedding stored for it, e.g.
+// in a local index built with the current embedding model
+type EmbeddedDocument struct {
+	Document
+	Embedding []float32
+}
+
+// DriftReport compares the nearest neighbors of documents under the stored
+// embeddings and under a new embedding model. Vectors of different models
+// cannot be compared directly, so the report measures whether each document
+// keeps the same neighbors among the sampled documents.
+type DriftReport struct {
+	Model   string // The new embedding model
+	Sampled int    // Number of documents re-embedded
+	K       int    // Number of neighbors compared per document
+
+	// MeanOverlap is the average fraction of the k nearest neighbors shared by
+	// both models, from 0 (none) to 1 (same neighbors)
+	MeanOverlap float64
+
+	// Top1Agreement is the fraction of documents whose nearest neighbor is the
+	// same under both models
+	Top1Agreement float64
+
+	// Documents lists the drift of each sampled document, most drifted first
+	Documents []DocumentDrift
+}
+
+// DocumentDrift is the drift of a single document
+type DocumentDrift struct {
+	Name    string
+	Overlap float64 // Fraction of the k nearest neighbors shared by both models
+}
+
+// MeasureEmbeddingDrift re-embeds a sample of sampleSize documents with model
+// and reports how much their top-k neighborhoods change, to assess a planned
+// migration, e.g. from text-embedding-ada-002 to text-embedding-3-small, before
+// re-embedding the whole index. The sample is spread evenly over docs so the
+// result is reproducible. sampleSize 0 samples every document.
+func (c *Client) MeasureEmbeddingDrift(ctx context.Context, docs []EmbeddedDocument, model string, sampleSize, k int) (*DriftReport, error) {
+	sample := sampleDocuments(docs, sampleSize)
+	if len(sample) < 2 {
+		return nil, fmt.Errorf("at least 2 documents are needed to compare neighbors, got %d", len(sample))
+	}
+	if k <= 0 || k >= len(sample) {
+		return nil, fmt.Errorf("k must be between 1 and %d, got %d", len(sample)-1, k)
+	}
+
+	texts := make([]string, len(sample))
+	oldVectors := make([][]float32, len(sample))
+	for i, doc := range sample {
+		if len(doc.Embedding) == 0 {
+			return nil, fmt.Errorf("document %q has no stored embedding", doc.Name)
+		}
+		texts[i] = string(doc.Content)
+		oldVectors[i] = doc.Embedding
+	}
+	newVectors, err := c.embedTexts(ctx, texts, openai.EmbeddingModel(model))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DriftReport{Model: model, Sampled: len(sample), K: k}
+	var totalOverlap float64
+	var top1 int
+	for i, doc := range sample {
+		oldNeighbors := nearestNeighbors(oldVectors, i, k)
+		newNeighbors := nearestNeighbors(newVectors, i, k)
+
+		shared := 0
+		inOld := map[int]bool{}
+		for _, j := range oldNeighbors {
+			inOld[j] = true
+		}
+		for _, j := range newNeighbors {
+			if inOld[j] {
+				shared++
+			}
+		}
+		if oldNeighbors[0] == newNeighbors[0] {
+			top1++
+		}
+
+		overlap := float64(shared) / float64(k)
+		totalOverlap += overlap
+		report.Documents = append(report.Documents, DocumentDrift{Name: doc.Name, Overlap: overlap})
+	}
+	report.MeanOverlap = totalOverlap / float64(len(sample))
+	report.Top1Agreement = float64(top1) / float64(len(sample))
+
+	sort.SliceStable(report.Documents, func(i, j int) bool {
+		return report.Documents[i].Overlap < report.Documents[j].Overlap
+	})
+	return report, nil
+}
+
+// sampleDocuments picks n documents spread evenly over docs, or all of them
+func sampleDocuments(docs []EmbeddedDocument, n int) []EmbeddedDocument {
+	if n <= 0 || n >= len(docs) {
+		return docs
+	}
+	sample := make([]EmbeddedDocument, n)
+	for i := range sample {
+		sample[i] = docs[i*len(docs)/n]
+	}
+	return sample
+}
+
+// nearestNeighbors returns the indexes of the k vectors most similar to
+// vectors[i], excluding i, most similar first
+func nearestNeighbors(vectors [][]float32, i, k int) []int {
+	type neighbor struct {
+		index int
+		score float64
+	}
+	neighbors := make([]neighbor, 0, len(vectors)-1)
+	for j, v := range vectors {
+		if j != i {
+			neighbors = append(neighbors, neighbor{j, cosineSimilarity(vectors[i], v)})
+		}
+	}
+	sort.SliceStable(neighbors, func(a, b int) bool {
+		return neighbors[a].score > neighbors[b].score
+	})
+
+	out := make([]int, k)
+	for n := range out {
+		out[n] = neighbors[n].index
+	}
+	return out
+}
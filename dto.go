@@ -0,0 +1,135 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Field name styles of DTOs
+const (
+	DTOSnakeCase = "snake_case" // As sent by the API, e.g. "created_at"
+	DTOCamelCase = "camelCase"  // e.g. "createdAt"
+)
+
+// DTOOptions controls the shape of the DTOs returned by ToDTO
+type DTOOptions struct {
+	Case     string         // DTOSnakeCase or DTOCamelCase, DTOSnakeCase when empty
+	Location *time.Location // Time zone of the times, UTC when nil
+}
+
+// dtoOpaqueKeys holds the fields whose keys are data rather than field names,
+// and are kept as is
+var dtoOpaqueKeys = map[string]bool{
+	"metadata":   true,
+	"parameters": true, // JSON Schema of function tools
+	"schema":     true, // JSON Schema of response formats
+}
+
+// ToDTO converts a value of this package, e.g. a *Run or a []Message, to the
+// generic JSON shape a frontend expects: field names in the requested case
+// and Unix timestamps, the fields ending in "_at", as RFC 3339 strings. The
+// keys of metadata and JSON schemas are left untouched. The result is made of
+// maps, slices and JSON scalars, ready for json.Marshal.
+func ToDTO(v interface{}, opts DTOOptions) (interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	location := opts.Location
+	if location == nil {
+		location = time.UTC
+	}
+	return convertDTO(generic, opts.Case == DTOCamelCase, location), nil
+}
+
+// MarshalDTO returns the JSON encoding of the DTO of v, see ToDTO
+func MarshalDTO(v interface{}, opts DTOOptions) ([]byte, error) {
+	dto, err := ToDTO(v, opts)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(dto)
+}
+
+func convertDTO(v interface{}, camel bool, location *time.Location) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			name := key
+			if camel {
+				name = camelCase(key)
+			}
+			switch {
+			case dtoOpaqueKeys[key]:
+				out[name] = value
+			case strings.HasSuffix(key, "_at"):
+				out[name] = dtoTime(value, location)
+			default:
+				out[name] = convertDTO(value, camel, location)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = convertDTO(value, camel, location)
+		}
+		return out
+	}
+	return v
+}
+
+// dtoTime returns a Unix timestamp as an RFC 3339 string. Zero and null
+// timestamps, e.g. of runs not completed yet, become null.
+func dtoTime(v interface{}, location *time.Location) interface{} {
+	number, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	seconds, err := number.Int64()
+	if err != nil || seconds == 0 {
+		return nil
+	}
+	return time.Unix(seconds, 0).In(location).Format(time.RFC3339)
+}
+
+// camelCase converts a snake_case name, e.g. "vector_store_ids" becomes
+// "vectorStoreIds". The names of untagged Go fields, e.g. "FileName", get a
+// lowercase initial.
+func camelCase(name string) string {
+	parts := strings.Split(name, "_")
+	for i, part := range parts {
+		if part == "" {
+			continue
+		}
+		if i == 0 {
+			parts[i] = lowerInitial(part)
+		} else {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// lowerInitial lowercases the leading capitals of name, keeping the one
+// starting the next word, e.g. "ID" becomes "id" and "URLPath" "urlPath"
+func lowerInitial(name string) string {
+	upper := 0
+	for upper < len(name) && name[upper] >= 'A' && name[upper] <= 'Z' {
+		upper++
+	}
+	if upper > 1 && upper < len(name) {
+		upper--
+	}
+	return strings.ToLower(name[:upper]) + name[upper:]
+}
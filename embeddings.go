@@ -1,20 +1,19 @@
 package openai
 
 import (
-	"bytes"
 	"context"
 	"crypto/sha1"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"net/http"
+	"math"
+	"unicode/utf8"
 
 	openai "github.com/sashabaranov/go-openai"
 )
 
 // / createEmbedding reads the content of a file, uploads it to OpenAI embeddings, and returns a generated ID for the embedding
-func CreateEmbedding(filePath string) (string, error) {
+func (c *Client) CreateEmbedding(ctx context.Context, filePath string) (string, error) {
 	// Read the file content
 	content, err := ioutil.ReadFile(filePath)
 	if err != nil {
@@ -22,21 +21,22 @@ func CreateEmbedding(filePath string) (string, error) {
 	}
 
 	// Initialize OpenAI client
-	client := openai.NewClient(openaiAPIKey)
-	ctx := context.Background()
+	client := c.sdkClient()
 
 	// Create embedding request
 	embeddingReq := openai.EmbeddingRequest{
-		Model: openai.AdaEmbeddingV2,     // Model for generating embeddings
-		Input: []string{string(content)}, // Convert content to string and pass as array
+		Model: openai.EmbeddingModel(c.mapModel(string(openai.AdaEmbeddingV2))), // Model for generating embeddings
+		Input: []string{string(content)},                                        // Convert content to string and pass as array
 	}
 
 	// Generate embedding using OpenAI client
 	resp, err := client.CreateEmbeddings(ctx, embeddingReq)
 	if err != nil {
-		return "", fmt.Errorf("error creating embedding: %w", err)
+		return "", fmt.Errorf("error creating embedding: %w", sdkError(err))
 	}
 
+	c.recordUsage(ctx, Usage{Endpoint: EndpointEmbeddings, Model: string(embeddingReq.Model), PromptTokens: resp.Usage.PromptTokens})
+
 	if len(resp.Data) == 0 || len(resp.Data[0].Embedding) == 0 {
 		return "", fmt.Errorf("no embedding data returned")
 	}
@@ -59,7 +59,7 @@ type EmbeddingResponse struct {
 }
 
 // CreateVectorForFile generates an embedding for the file content and returns a unique ID based on the embedding
-func CreateVectorForFile(filePath string) (string, error) {
+func (c *Client) CreateVectorForFile(ctx context.Context, filePath string) (string, error) {
 	// Read the file content
 	content, err := ioutil.ReadFile(filePath)
 	if err != nil {
@@ -68,39 +68,14 @@ func CreateVectorForFile(filePath string) (string, error) {
 
 	// Prepare payload for embedding request
 	payload := map[string]interface{}{
-		"input": string(content),          // Convert content to string for embedding input
-		"model": "text-embedding-ada-002", // Embedding model
-	}
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal embedding payload: %w", err)
+		"input": string(content),                      // Convert content to string for embedding input
+		"model": c.mapModel("text-embedding-ada-002"), // Embedding model
 	}
 
 	// Send request to embeddings API
-	url := "https://api.openai.com/v1/embeddings"
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return "", fmt.Errorf("failed to create embedding request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("embedding request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return "", fmt.Errorf("embedding creation failed with status %s: %s", resp.Status, string(body))
-	}
-
-	// Decode response to get embedding data
 	var embeddingResp EmbeddingResponse
-	if err := json.NewDecoder(resp.Body).Decode(&embeddingResp); err != nil {
-		return "", fmt.Errorf("failed to decode embedding response: %w", err)
+	if err := c.do(ctx, "POST", c.modelPath("text-embedding-ada-002", "/embeddings"), payload, &embeddingResp); err != nil {
+		return "", fmt.Errorf("embedding creation failed: %w", err)
 	}
 
 	if len(embeddingResp.Embedding) == 0 {
@@ -112,6 +87,83 @@ func CreateVectorForFile(filePath string) (string, error) {
 	hash.Write([]byte(content))
 	embeddingID := hex.EncodeToString(hash.Sum(nil))
 
-	fmt.Printf("Embedding created successfully for %s with ID: %s\n", filePath, embeddingID)
+	c.logger.InfoContext(ctx, "embedding created", "path", filePath, "embedding_id", embeddingID)
 	return embeddingID, nil
 }
+
+// embeddingBatchSize is the number of inputs sent per embeddings request
+const embeddingBatchSize = 100
+
+// maxEmbeddingInputBytes keeps inputs safely under the 8K token limit of the embedding models
+const maxEmbeddingInputBytes = 24000
+
+// embedTexts returns one embedding per text, batching the requests. Texts longer
+// than maxEmbeddingInputBytes are truncated.
+func (c *Client) embedTexts(ctx context.Context, texts []string, model openai.EmbeddingModel) ([][]float32, error) {
+	// Initialize OpenAI client
+	client := c.sdkClient()
+
+	vectors := make([][]float32, len(texts))
+	for start := 0; start < len(texts); start += embeddingBatchSize {
+		end := min(start+embeddingBatchSize, len(texts))
+
+		inputs := make([]string, 0, end-start)
+		for _, text := range texts[start:end] {
+			inputs = append(inputs, truncateUTF8(text, maxEmbeddingInputBytes))
+		}
+
+		resp, err := client.CreateEmbeddings(ctx, openai.EmbeddingRequest{Model: openai.EmbeddingModel(c.mapModel(string(model))), Input: inputs})
+		if err != nil {
+			return nil, fmt.Errorf("error creating embeddings: %w", sdkError(err))
+		}
+		c.recordUsage(ctx, Usage{Endpoint: EndpointEmbeddings, Model: string(model), PromptTokens: resp.Usage.PromptTokens})
+		if len(resp.Data) != len(inputs) {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", len(inputs), len(resp.Data))
+		}
+		for i, data := range resp.Data {
+			// Compatible servers may leave out the index, in which case the
+			// embeddings are in input order
+			if c.compat != nil {
+				data.Index = i
+			}
+			if data.Index < 0 || data.Index >= len(inputs) {
+				return nil, fmt.Errorf("embedding index %d out of range for a batch of %d inputs", data.Index, len(inputs))
+			}
+			if vectors[start+data.Index] != nil {
+				return nil, fmt.Errorf("embedding index %d returned twice", data.Index)
+			}
+			vectors[start+data.Index] = data.Embedding
+		}
+	}
+	return vectors, nil
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a rune
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors, or 0 when
+// their lengths differ or one of them is null
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,29 @@
+package openai_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openai "github.com/bhirbec/go-openai"
+)
+
+func TestEmbeddingIndexOutOfRange(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"object": "list", "model": "text-embedding-3-small",
+			"data": [{"object": "embedding", "index": 0, "embedding": [1, 0]}, {"object": "embedding", "index": 5, "embedding": [0, 1]}],
+			"usage": {"prompt_tokens": 2, "total_tokens": 2}}`)
+	}))
+	defer srv.Close()
+
+	client := openai.NewClient("test", openai.WithBaseURL(srv.URL))
+	docs := []openai.Document{{Name: "a", Content: []byte("a")}, {Name: "b", Content: []byte("b")}}
+	_, err := client.DedupDocuments(context.Background(), docs, 0.9)
+	if err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Fatalf("got error %v, want an out of range index error", err)
+	}
+}
@@ -0,0 +1,154 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Errors matching the APIError of well-known error codes with errors.Is, e.g.
+// errors.Is(err, openai.ErrInsufficientQuota)
+var (
+	ErrInvalidAPIKey     = errors.New("invalid API key")
+	ErrInsufficientQuota = errors.New("insufficient quota")
+	ErrModelNotFound     = errors.New("model not found")
+	ErrUnsupportedFile   = errors.New("unsupported file")
+)
+
+// errorCodes maps the codes of the error envelope to their sentinel error
+var errorCodes = map[string]error{
+	"invalid_api_key":    ErrInvalidAPIKey,
+	"insufficient_quota": ErrInsufficientQuota,
+	"model_not_found":    ErrModelNotFound,
+	"unsupported_file":   ErrUnsupportedFile,
+}
+
+// APIError is returned when the API answers with an error status. It carries
+// the fields of the OpenAI error envelope; use errors.As to inspect it.
+type APIError struct {
+	StatusCode int
+	Type       string
+	Code       string
+	Param      string
+	Message    string
+	RequestID  string // Value of the x-request-id header, to quote when contacting support
+}
+
+func (e *APIError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "openai: status %d", e.StatusCode)
+	if e.Code != "" {
+		fmt.Fprintf(&b, " (%s)", e.Code)
+	}
+	if e.Message != "" {
+		b.WriteString(": ")
+		b.WriteString(e.Message)
+	}
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, " [request %s]", e.RequestID)
+	}
+	return b.String()
+}
+
+// Is reports whether target is the sentinel error of the error code
+func (e *APIError) Is(target error) bool {
+	sentinel, ok := errorCodes[e.Code]
+	return ok && sentinel == target
+}
+
+// newAPIError builds an APIError from an error response. The body is used as
+// the message when it is not an OpenAI error envelope.
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	var errorResp ErrorResponse
+	if err := json.Unmarshal(body, &errorResp); err == nil && errorResp.Error.Message != "" {
+		apiErr.Type = errorResp.Error.Type
+		apiErr.Code = errorResp.Error.Code
+		apiErr.Param = errorResp.Error.Param
+		apiErr.Message = errorResp.Error.Message
+	} else {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	return apiErr
+}
+
+// sdkError converts the errors of the go-openai client, used for chat and
+// embeddings, to APIError so callers handle a single error type
+func sdkError(err error) error {
+	var sdkAPIErr *openai.APIError
+	if errors.As(err, &sdkAPIErr) {
+		apiErr := &APIError{
+			StatusCode: sdkAPIErr.HTTPStatusCode,
+			Type:       sdkAPIErr.Type,
+			Message:    sdkAPIErr.Message,
+		}
+		if sdkAPIErr.Code != nil {
+			apiErr.Code = fmt.Sprint(sdkAPIErr.Code)
+		}
+		if sdkAPIErr.Param != nil {
+			apiErr.Param = *sdkAPIErr.Param
+		}
+		return apiErr
+	}
+
+	var sdkReqErr *openai.RequestError
+	if errors.As(err, &sdkReqErr) {
+		return &APIError{
+			StatusCode: sdkReqErr.HTTPStatusCode,
+			Message:    strings.TrimSpace(string(sdkReqErr.Body)),
+		}
+	}
+	return err
+}
+
+// hasStatus reports whether err is an APIError with one of the given statuses
+func hasStatus(err error, codes ...int) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, code := range codes {
+		if apiErr.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNotFound reports whether err is an APIError for a missing resource
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is an APIError for an invalid API key or
+// insufficient permissions
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized, http.StatusForbidden)
+}
+
+// IsRateLimited reports whether err is an APIError for a rate limit or an
+// exhausted quota
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsBadRequest reports whether err is an APIError for an invalid request
+func IsBadRequest(err error) bool {
+	return hasStatus(err, http.StatusBadRequest)
+}
+
+// IsServerError reports whether err is an APIError for a failure on the API side
+func IsServerError(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode >= 500
+}
@@ -0,0 +1,437 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// EvalSuite is a set of scripted conversations to run against an assistant
+type EvalSuite struct {
+	Name  string     `json:"name"`
+	Cases []EvalCase `json:"cases"`
+}
+
+// EvalCase is a conversation played on a fresh thread
+type EvalCase struct {
+	Name  string     `json:"name"`
+	Turns []EvalTurn `json:"turns"`
+}
+
+// EvalTurn is a user message and the expectations on the assistant's reply
+type EvalTurn struct {
+	User string `json:"user"`
+
+	// ToolOutputs holds the output returned to the run for each function name.
+	// Functions without an entry get "{}".
+	ToolOutputs map[string]string `json:"tool_outputs,omitempty"`
+
+	// ExpectToolCalls lists the function names, or the tool types for built-in
+	// tools ("file_search", "code_interpreter"), that must be called
+	ExpectToolCalls []string `json:"expect_tool_calls,omitempty"`
+
+	// ExpectMatch is a regular expression the answer must match
+	ExpectMatch string `json:"expect_match,omitempty"`
+
+	// ExpectJudge describes what a correct answer looks like; an LLM judge
+	// decides whether the answer meets it
+	ExpectJudge string `json:"expect_judge,omitempty"`
+}
+
+// LoadEvalSuite reads an EvalSuite from a JSON file
+func LoadEvalSuite(path string) (*EvalSuite, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read eval suite %s: %w", path, err)
+	}
+
+	var suite EvalSuite
+	if err := json.Unmarshal(content, &suite); err != nil {
+		return nil, fmt.Errorf("failed to decode eval suite %s: %w", path, err)
+	}
+	return &suite, nil
+}
+
+// EvalRunner plays eval suites against an assistant
+type EvalRunner struct {
+	Client       *Client
+	AssistantID  string
+	JudgeModel   string        // Model used for ExpectJudge, defaults to gpt-4o-mini
+	PollInterval time.Duration // Interval between run status checks, defaults to one second
+
+	// Progress receives a JSON line per ProgressEvent as the suite runs:
+	// suite_started, case_started, turn_completed, case_completed and
+	// suite_completed. The writer set with WithProgress is used when nil.
+	Progress io.Writer
+}
+
+// EvalReport is the outcome of an eval suite
+type EvalReport struct {
+	Suite    string           `json:"suite"`
+	Passed   int              `json:"passed"`
+	Failed   int              `json:"failed"`
+	Duration time.Duration    `json:"duration"`
+	Cases    []EvalCaseResult `json:"cases"`
+}
+
+// EvalCaseResult is the outcome of one conversation
+type EvalCaseResult struct {
+	Name     string           `json:"name"`
+	ThreadID string           `json:"thread_id,omitempty"`
+	Passed   bool             `json:"passed"`
+	Error    string           `json:"error,omitempty"` // Set when the conversation could not be played
+	Duration time.Duration    `json:"duration"`
+	Turns    []EvalTurnResult `json:"turns"`
+}
+
+// EvalTurnResult is the outcome of one turn
+type EvalTurnResult struct {
+	User      string   `json:"user"`
+	RunID     string   `json:"run_id,omitempty"`
+	Answer    string   `json:"answer"`
+	ToolCalls []string `json:"tool_calls,omitempty"`
+	Failures  []string `json:"failures,omitempty"`
+}
+
+// Run plays every case of the suite on its own thread. Errors talking to the API
+// are reported on the failing case rather than aborting the suite.
+func (r *EvalRunner) Run(ctx context.Context, suite *EvalSuite) *EvalReport {
+	clock := r.Client.clock
+	start := clock.Now()
+	report := &EvalReport{Suite: suite.Name}
+	progress := r.Client.progress
+	if r.Progress != nil {
+		progress = newProgressWriter(r.Progress, clock.Now)
+	}
+	progress.emit(ProgressEvent{Event: "suite_started", Name: suite.Name, Total: len(suite.Cases)})
+
+	for i, c := range suite.Cases {
+		progress.emit(ProgressEvent{Event: "case_started", Name: c.Name, Done: i, Total: len(suite.Cases)})
+
+		result := r.runCase(ctx, c, progress)
+		status := "passed"
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+			status = "failed"
+		}
+		report.Cases = append(report.Cases, result)
+
+		progress.emit(ProgressEvent{
+			Event:  "case_completed",
+			Name:   c.Name,
+			ID:     result.ThreadID,
+			Done:   i + 1,
+			Total:  len(suite.Cases),
+			Status: status,
+			Error:  result.Error,
+		})
+	}
+
+	report.Duration = clock.Now().Sub(start)
+	progress.emit(ProgressEvent{
+		Event:  "suite_completed",
+		Name:   suite.Name,
+		Done:   len(suite.Cases),
+		Total:  len(suite.Cases),
+		Status: fmt.Sprintf("%d passed, %d failed", report.Passed, report.Failed),
+	})
+	return report
+}
+
+func (r *EvalRunner) runCase(ctx context.Context, c EvalCase, progress *progressWriter) (result EvalCaseResult) {
+	start := r.Client.clock.Now()
+	result = EvalCaseResult{Name: c.Name, Passed: true}
+	defer func() { result.Duration = r.Client.clock.Now().Sub(start) }()
+
+	thread, err := r.Client.CreateThread(ctx, &CreateThreadParams{})
+	if err != nil {
+		result.Passed = false
+		result.Error = err.Error()
+		return result
+	}
+	result.ThreadID = thread.ID
+
+	for i, turn := range c.Turns {
+		turnResult, err := r.runTurn(ctx, thread.ID, turn)
+		result.Turns = append(result.Turns, turnResult)
+
+		event := ProgressEvent{Event: "turn_completed", Name: c.Name, ID: turnResult.RunID, Done: i + 1, Total: len(c.Turns), Status: "passed"}
+		if err != nil {
+			event.Status, event.Error = "error", err.Error()
+		} else if len(turnResult.Failures) > 0 {
+			event.Status, event.Error = "failed", strings.Join(turnResult.Failures, "; ")
+		}
+		progress.emit(event)
+
+		if err != nil {
+			result.Passed = false
+			result.Error = err.Error()
+			return result
+		}
+		if len(turnResult.Failures) > 0 {
+			result.Passed = false
+		}
+	}
+	return result
+}
+
+func (r *EvalRunner) runTurn(ctx context.Context, threadID string, turn EvalTurn) (EvalTurnResult, error) {
+	result := EvalTurnResult{User: turn.User}
+
+	if _, err := r.Client.CreateMessage(ctx, &CreateMessageParams{ThreadID: threadID, Role: "user", Content: turn.User}); err != nil {
+		return result, err
+	}
+
+	run, err := r.Client.CreateRun(ctx, threadID, &CreateRunParams{AssistantID: r.AssistantID}, nil)
+	if err != nil {
+		return result, err
+	}
+	result.RunID = run.ID
+
+	for {
+		run, err = r.Client.WaitForRun(ctx, threadID, run.ID, r.PollInterval)
+		if err != nil {
+			return result, err
+		}
+		if run.Status != "requires_action" || run.RequiredAction == nil {
+			break
+		}
+
+		var outputs []ToolOutput
+		for _, call := range run.RequiredAction.SubmitToolOutputs.ToolCalls {
+			output, ok := turn.ToolOutputs[call.Function.Name]
+			if !ok {
+				output = "{}"
+			}
+			outputs = append(outputs, ToolOutput{ToolCallID: call.ID, Output: output})
+		}
+		if _, err := r.Client.SubmitToolOutputs(ctx, threadID, run.ID, outputs); err != nil {
+			return result, err
+		}
+	}
+	if run.Status != "completed" {
+		failure := fmt.Sprintf("run ended with status %s", run.Status)
+		if run.LastError != nil {
+			failure += ": " + run.LastError.Message
+		}
+		result.Failures = append(result.Failures, failure)
+		return result, nil
+	}
+
+	steps, err := r.Client.ListRunSteps(ctx, threadID, run.ID)
+	if err != nil {
+		return result, err
+	}
+	for _, step := range steps {
+		for _, call := range step.StepDetails.ToolCalls {
+			if call.Function != nil {
+				result.ToolCalls = append(result.ToolCalls, call.Function.Name)
+			} else {
+				result.ToolCalls = append(result.ToolCalls, call.Type)
+			}
+		}
+	}
+
+	messages, err := r.Client.ListMessages(ctx, threadID, WithLimit(100), WithOrder("asc"), WithRunID(run.ID))
+	if err != nil {
+		return result, err
+	}
+	var answer []string
+	for _, msg := range messages {
+		if msg.Role != "assistant" {
+			continue
+		}
+		for _, content := range msg.Content {
+			if content.Type == "text" {
+				answer = append(answer, content.Text.Value)
+			}
+		}
+	}
+	result.Answer = strings.Join(answer, "\n")
+
+	result.Failures = append(result.Failures, r.check(ctx, turn, result)...)
+	return result, nil
+}
+
+// check evaluates the expectations of a turn against its result
+func (r *EvalRunner) check(ctx context.Context, turn EvalTurn, result EvalTurnResult) []string {
+	var failures []string
+
+	called := map[string]bool{}
+	for _, name := range result.ToolCalls {
+		called[name] = true
+	}
+	for _, name := range turn.ExpectToolCalls {
+		if !called[name] {
+			failures = append(failures, fmt.Sprintf("expected tool call %s, got %v", name, result.ToolCalls))
+		}
+	}
+
+	if turn.ExpectMatch != "" {
+		re, err := regexp.Compile(turn.ExpectMatch)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("invalid expect_match %q: %v", turn.ExpectMatch, err))
+		} else if !re.MatchString(result.Answer) {
+			failures = append(failures, fmt.Sprintf("answer does not match %q", turn.ExpectMatch))
+		}
+	}
+
+	if turn.ExpectJudge != "" {
+		pass, reason, err := r.judge(ctx, turn.User, result.Answer, turn.ExpectJudge)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("judge failed: %v", err))
+		} else if !pass {
+			failures = append(failures, "judge rejected the answer: "+reason)
+		}
+	}
+	return failures
+}
+
+// evalJudgeSchema constrains the judge to a verdict and its reason
+var evalJudgeSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"pass": {"type": "boolean"},
+		"reason": {"type": "string"}
+	},
+	"required": ["pass", "reason"],
+	"additionalProperties": false
+}`)
+
+// judge asks a model whether the answer meets the criteria
+func (r *EvalRunner) judge(ctx context.Context, question, answer, criteria string) (bool, string, error) {
+	model := r.JudgeModel
+	if model == "" {
+		model = openai.GPT4oMini
+	}
+
+	resp, err := r.Client.createChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: model,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role: openai.ChatMessageRoleSystem,
+				Content: "You grade answers given by an assistant. Decide whether the answer meets the criteria. " +
+					"Be strict: pass only if every part of the criteria is met.",
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: fmt.Sprintf("Question:\n%s\n\nAnswer:\n%s\n\nCriteria:\n%s", question, answer, criteria),
+			},
+		},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   "verdict",
+				Schema: evalJudgeSchema,
+				Strict: true,
+			},
+		},
+	})
+	if err != nil {
+		return false, "", sdkError(err)
+	}
+	if len(resp.Choices) == 0 {
+		return false, "", fmt.Errorf("no verdict returned")
+	}
+
+	var verdict struct {
+		Pass   bool   `json:"pass"`
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &verdict); err != nil {
+		return false, "", fmt.Errorf("failed to decode verdict: %w", err)
+	}
+	return verdict.Pass, verdict.Reason, nil
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *EvalReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report in the JUnit XML format understood by CI systems
+func (r *EvalReport) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:  r.Suite,
+		Tests: len(r.Cases),
+		Time:  fmt.Sprintf("%.3f", r.Duration.Seconds()),
+	}
+
+	for _, c := range r.Cases {
+		tc := junitTestCase{
+			Name:      c.Name,
+			ClassName: r.Suite,
+			Time:      fmt.Sprintf("%.3f", c.Duration.Seconds()),
+		}
+
+		var transcript, failures strings.Builder
+		for i, turn := range c.Turns {
+			fmt.Fprintf(&transcript, "[turn %d] user: %s\n[turn %d] assistant: %s\n", i+1, turn.User, i+1, turn.Answer)
+			for _, failure := range turn.Failures {
+				fmt.Fprintf(&failures, "turn %d: %s\n", i+1, failure)
+			}
+		}
+		tc.SystemOut = transcript.String()
+
+		switch {
+		case c.Error != "":
+			suite.Errors++
+			tc.Error = &junitMessage{Message: c.Error, Body: failures.String()}
+		case !c.Passed:
+			suite.Failures++
+			tc.Failure = &junitMessage{Message: "expectations not met", Body: failures.String()}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
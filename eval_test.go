@@ -0,0 +1,46 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	openai "github.com/bhirbec/go-openai"
+	"github.com/bhirbec/go-openai/openaitest"
+)
+
+func TestEvalRunnerUsesClientClock(t *testing.T) {
+	srv := openaitest.NewServer()
+	defer srv.Close()
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := openaitest.NewFakeClock(start)
+	client := openai.NewClient("test", openai.WithBaseURL(srv.URL), openai.WithClock(clock))
+	ctx := context.Background()
+
+	assistant, err := client.CreateAssistant(ctx, &openai.CreateAssistantParams{Name: "eval", Model: "gpt-4o-mini"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var progress strings.Builder
+	runner := &openai.EvalRunner{Client: client, AssistantID: assistant.ID, PollInterval: time.Second, Progress: &progress}
+	report := runner.Run(ctx, &openai.EvalSuite{Name: "clock", Cases: []openai.EvalCase{{Name: "hello", Turns: []openai.EvalTurn{{User: "Hello"}}}}})
+
+	var slept time.Duration
+	for _, d := range clock.Sleeps() {
+		slept += d
+	}
+	if report.Duration != slept || report.Cases[0].Duration != slept {
+		t.Errorf("suite took %v and case %v, want the %v slept on the fake clock", report.Duration, report.Cases[0].Duration, slept)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(progress.String()), "\n") {
+		var event openai.ProgressEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatal(err)
+		}
+		if event.Time.Before(start) || event.Time.After(start.Add(slept)) {
+			t.Errorf("event %s at %v, want a time of the fake clock", event.Event, event.Time)
+		}
+	}
+}
@@ -1,9 +1,9 @@
-// XXX: create a method to handle the HTTP request and refactor this code to use it
 package openai
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -14,14 +14,14 @@ import (
 
 // File holds response data for a file upload
 type File struct {
-	ID        string `json:"id"`
-	CreatedAt int64  `json:"created_at"`
-	Bytes     int64  `json:"bytes"`
-	FileName  string `json:"filename"`
-	Purpose   string `json:"purpose"`
+	ID        string    `json:"id"`
+	CreatedAt Timestamp `json:"created_at"`
+	Bytes     int64     `json:"bytes"`
+	FileName  string    `json:"filename"`
+	Purpose   string    `json:"purpose"`
 }
 
-func UploadFile(path string) (string, error) {
+func (c *Client) UploadFile(ctx context.Context, path string) (string, error) {
 	// Read file content
 	content, err := os.ReadFile(path)
 	if err != nil {
@@ -34,10 +34,15 @@ func UploadFile(path string) (string, error) {
 	}
 
 	// Use UploadContent
-	return UploadContent(path, content)
+	return c.UploadContent(ctx, path, content)
 }
 
-func UploadContent(path string, content []byte) (string, error) {
+func (c *Client) UploadContent(ctx context.Context, path string, content []byte) (string, error) {
+	return c.uploadContent(ctx, path, content, "user_data")
+}
+
+// uploadContent uploads content for the given purpose, e.g. "batch"
+func (c *Client) uploadContent(ctx context.Context, path string, content []byte, purpose string) (string, error) {
 	// Prepare the request body
 	var requestBody bytes.Buffer
 	multiWriter := multipart.NewWriter(&requestBody)
@@ -47,7 +52,7 @@ func UploadContent(path string, content []byte) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to add purpose field: %w", err)
 	}
-	_, err = purposeWriter.Write([]byte("user_data")) // Modify as needed
+	_, err = purposeWriter.Write([]byte(purpose))
 	if err != nil {
 		return "", fmt.Errorf("failed to write purpose to form: %w", err)
 	}
@@ -66,121 +71,71 @@ func UploadContent(path string, content []byte) (string, error) {
 	multiWriter.Close()
 
 	// Create the request
-	url := "https://api.openai.com/v1/files" // Replace with the actual endpoint
-	req, err := http.NewRequest("POST", url, &requestBody)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/files", &requestBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
 	req.Header.Set("Content-Type", multiWriter.FormDataContentType())
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("upload failed with status %s: %s", resp.Status, string(body))
-	}
-
-	// Decode response to get file ID
 	var f File
-	if err := json.NewDecoder(resp.Body).Decode(&f); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+	if err := c.doRequest(req, &f); err != nil {
+		return "", fmt.Errorf("upload failed: %w", err)
 	}
 
-	fmt.Printf("Content for %s uploaded successfully with ID: %s\n", path, f.ID)
+	c.logger.InfoContext(ctx, "file uploaded", "path", path, "file_id", f.ID)
 	return f.ID, nil
 }
 
-// ListFiles retrieves a list of all files uploaded to ChatGPT
-func ListFiles() ([]File, error) {
-	url := "https://api.openai.com/v1/files"
-	req, err := http.NewRequest("GET", url, nil)
+// ListFiles retrieves a list of the files uploaded to ChatGPT, e.g.
+// ListFiles(ctx, WithLimit(20), WithOrder("desc"))
+func (c *Client) ListFiles(ctx context.Context, opts ...ListOption) ([]File, error) {
+	page, err := c.ListFilesPage(ctx, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("retrieving files failed with status %s: %s", resp.Status, string(body))
-	}
-
-	// Parse the response
-	var response struct {
-		Data []File `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+		return nil, err
 	}
-
-	return response.Data, nil
+	return page.Data, nil
 }
 
 // RetrieveFile retrieves information about a specific file by file ID
-func RetrieveFile(fileID string) (*File, error) {
-	url := fmt.Sprintf("https://api.openai.com/v1/files/%s", fileID)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create retrieve file request: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("file retrieval request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("file retrieval failed with status %s: %s", resp.Status, string(body))
-	}
-
+func (c *Client) RetrieveFile(ctx context.Context, fileID string) (*File, error) {
 	var file File
-	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
-		return nil, fmt.Errorf("failed to decode file retrieval response: %w", err)
+	if err := c.do(ctx, "GET", "/files/"+fileID, nil, &file); err != nil {
+		return nil, fmt.Errorf("file retrieval failed: %w", err)
 	}
 
-	fmt.Printf("File %s retrieved successfully with ID: %s\n", file.FileName, file.ID)
+	c.logger.DebugContext(ctx, "file retrieved", "filename", file.FileName, "file_id", file.ID)
 	return &file, nil
 }
 
-// DeleteFile deletes a file from ChatGPT by file ID
-func DeleteFile(fileID string) error {
-	url := fmt.Sprintf("https://api.openai.com/v1/files/%s", fileID)
-	req, err := http.NewRequest("DELETE", url, nil)
+// DownloadFile writes the content of a file to w as it streams in, e.g. the
+// output of a batch or a file generated by code_interpreter. With a download
+// policy, a file outgrowing MaxBytes fails with part of it already written.
+func (c *Client) DownloadFile(ctx context.Context, fileID string, w io.Writer) error {
+	content, err := c.openFileContent(ctx, fileID, "")
 	if err != nil {
-		return fmt.Errorf("failed to create delete request: %w", err)
+		return err
 	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
+	defer content.Close()
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("delete request failed: %w", err)
+	if _, err := io.Copy(w, content); err != nil {
+		var downloadErr *DownloadError
+		if errors.As(err, &downloadErr) {
+			return downloadErr
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("file download failed: %w", err)
 	}
-	defer resp.Body.Close()
+	return nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("file deletion failed with status %s: %s", resp.Status, string(body))
+// DeleteFile deletes a file from ChatGPT by file ID
+func (c *Client) DeleteFile(ctx context.Context, fileID string) error {
+	if err := c.do(ctx, "DELETE", "/files/"+fileID, nil, nil); err != nil {
+		return fmt.Errorf("file deletion failed: %w", err)
 	}
 
-	fmt.Printf("File with ID %s deleted successfully.\n", fileID)
+	c.logger.InfoContext(ctx, "file deleted", "file_id", fileID)
 	return nil
 }
@@ -0,0 +1,140 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// FineTuningJob is a fine-tuning job, as needed to promote its model
+type FineTuningJob struct {
+	ID             string    `json:"id"`
+	Model          string    `json:"model"`            // Base model
+	FineTunedModel string    `json:"fine_tuned_model"` // Empty until the job succeeds
+	Status         string    `json:"status"`           // e.g. "running", "succeeded" or "failed"
+	CreatedAt      Timestamp `json:"created_at"`
+	FinishedAt     Timestamp `json:"finished_at"`
+}
+
+// Model is a model available to the API key
+type Model struct {
+	ID      string `json:"id"`
+	OwnedBy string `json:"owned_by"`
+	Created int64  `json:"created"`
+}
+
+// RetrieveFineTuningJob fetches a fine-tuning job
+func (c *Client) RetrieveFineTuningJob(ctx context.Context, jobID string) (*FineTuningJob, error) {
+	var job FineTuningJob
+	if err := c.do(ctx, "GET", "/fine_tuning/jobs/"+jobID, nil, &job); err != nil {
+		return nil, fmt.Errorf("fine-tuning job retrieval failed: %w", err)
+	}
+	return &job, nil
+}
+
+// RetrieveModel fetches a model, failing with ErrModelNotFound when the key
+// cannot use it
+func (c *Client) RetrieveModel(ctx context.Context, model string) (*Model, error) {
+	var m Model
+	if err := c.do(ctx, "GET", "/models/"+model, nil, &m); err != nil {
+		return nil, fmt.Errorf("model retrieval failed: %w", err)
+	}
+	return &m, nil
+}
+
+// DeleteModel deletes a fine-tuned model
+func (c *Client) DeleteModel(ctx context.Context, model string) error {
+	if err := c.do(ctx, "DELETE", "/models/"+model, nil, nil); err != nil {
+		return fmt.Errorf("model deletion failed: %w", err)
+	}
+	c.logger.InfoContext(ctx, "model deleted", "model", model)
+	return nil
+}
+
+// SmokeTest is a prompt the promoted model must answer
+type SmokeTest struct {
+	Prompt string
+	Check  func(answer string) error // Validates the answer; any non-empty answer passes when nil
+}
+
+// PromoteConfig configures PromoteFineTunedModel
+type PromoteConfig struct {
+	Alias      string     // Name pointed to the fine-tuned model
+	Aliases    AliasStore // Where the alias is stored
+	SmokeTests []SmokeTest
+
+	// DeleteSuperseded deletes the fine-tuned model the alias pointed to
+	// before, once the alias is updated. Base models are never deleted.
+	DeleteSuperseded bool
+}
+
+// Promotion is the outcome of PromoteFineTunedModel
+type Promotion struct {
+	Model    string // Fine-tuned model the alias now points to
+	Previous string // Former target of the alias, empty if it did not exist
+	Deleted  bool   // Whether Previous was deleted
+}
+
+// PromoteFineTunedModel points an alias to the model of a succeeded
+// fine-tuning job. The model must be retrievable and pass the smoke tests
+// before the alias is updated, so a bad fine-tune is never served. A failure to
+// delete the superseded model is returned along with the promotion, since the
+// alias was updated.
+func (c *Client) PromoteFineTunedModel(ctx context.Context, jobID string, config PromoteConfig) (*Promotion, error) {
+	job, err := c.RetrieveFineTuningJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != "succeeded" || job.FineTunedModel == "" {
+		return nil, fmt.Errorf("fine-tuning job %s is %s, not succeeded", jobID, job.Status)
+	}
+
+	model := job.FineTunedModel
+	if _, err := c.RetrieveModel(ctx, model); err != nil {
+		return nil, err
+	}
+	for i, test := range config.SmokeTests {
+		if err := c.runSmokeTest(ctx, model, test); err != nil {
+			return nil, fmt.Errorf("smoke test %d failed for %s: %w", i+1, model, err)
+		}
+	}
+
+	previous, _, err := config.Aliases.Alias(ctx, config.Alias)
+	if err != nil {
+		return nil, fmt.Errorf("alias lookup failed: %w", err)
+	}
+	if err := config.Aliases.SwapAlias(ctx, config.Alias, previous, model); err != nil {
+		return nil, fmt.Errorf("alias update failed: %w", err)
+	}
+	c.logger.InfoContext(ctx, "fine-tuned model promoted", "alias", config.Alias, "model", model, "previous", previous)
+
+	promotion := &Promotion{Model: model, Previous: previous}
+	if config.DeleteSuperseded && previous != model && strings.HasPrefix(previous, "ft:") {
+		if err := c.DeleteModel(ctx, previous); err != nil && !errors.Is(err, ErrModelNotFound) {
+			return promotion, err
+		}
+		promotion.Deleted = true
+	}
+	return promotion, nil
+}
+
+// runSmokeTest sends the prompt of test to model and checks the answer
+func (c *Client) runSmokeTest(ctx context.Context, model string, test SmokeTest) error {
+	resp, err := c.createChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:    model,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: test.Prompt}},
+	})
+	if err != nil {
+		return sdkError(err)
+	}
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return errors.New("empty answer")
+	}
+	if test.Check != nil {
+		return test.Check(resp.Choices[0].Message.Content)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ForwardedFile describes a file streamed to an Uploader by ForwardFile
+type ForwardedFile struct {
+	ID          string
+	FileName    string
+	Size        int64 // Bytes of content, -1 when unknown
+	ContentType string
+}
+
+// Uploader receives the content of a file as it streams in from the API
+type Uploader interface {
+	Upload(ctx context.Context, file ForwardedFile, body io.Reader) error
+}
+
+// UploaderFunc adapts a function to the Uploader interface
+type UploaderFunc func(ctx context.Context, file ForwardedFile, body io.Reader) error
+
+func (f UploaderFunc) Upload(ctx context.Context, file ForwardedFile, body io.Reader) error {
+	return f(ctx, file, body)
+}
+
+// ForwardFile streams the content of a file to an uploader without buffering
+// it in memory or on disk, e.g. to hand a file generated by code_interpreter
+// to object storage from a serverless function. The file ID of generated
+// images and file_path annotations is the one to pass.
+func (c *Client) ForwardFile(ctx context.Context, fileID string, uploader Uploader) (*ForwardedFile, error) {
+	file, err := c.RetrieveFile(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := c.openFileContent(ctx, fileID, file.FileName)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	forwarded := &ForwardedFile{
+		ID:          file.ID,
+		FileName:    file.FileName,
+		Size:        content.Size,
+		ContentType: content.ContentType,
+	}
+	if forwarded.Size < 0 && file.Bytes > 0 {
+		forwarded.Size = file.Bytes
+	}
+
+	if err := uploader.Upload(ctx, *forwarded, content); err != nil {
+		var downloadErr *DownloadError
+		if errors.As(err, &downloadErr) {
+			return nil, downloadErr
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("file upload failed: %w", err)
+	}
+	c.logger.InfoContext(ctx, "file forwarded", "file_id", fileID, "bytes", forwarded.Size)
+	return forwarded, nil
+}
+
+// SignedURLUploader sends the content of files to a presigned URL, e.g. of an
+// S3 or GCS object, with the headers the URL was signed with
+type SignedURLUploader struct {
+	URL        string
+	Method     string       // PUT when empty
+	Header     http.Header  // Added to the request, e.g. x-amz-server-side-encryption
+	HTTPClient *http.Client // http.DefaultClient when nil
+}
+
+func (u *SignedURLUploader) Upload(ctx context.Context, file ForwardedFile, body io.Reader) error {
+	method := u.Method
+	if method == "" {
+		method = "PUT"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.URL, body)
+	if err != nil {
+		return err
+	}
+	// Object stores reject chunked uploads to presigned URLs, so the length
+	// is sent whenever known
+	req.ContentLength = file.Size
+	if file.ContentType != "" {
+		req.Header.Set("Content-Type", file.ContentType)
+	}
+	for key, values := range u.Header {
+		req.Header[http.CanonicalHeaderKey(key)] = values
+	}
+
+	httpClient := u.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("upload to signed URL returned status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package openai
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FunctionDefinition describes a function the model can call
+type FunctionDefinition struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Parameters  interface{} `json:"parameters,omitempty"` // JSON Schema of the arguments
+	Strict      *bool       `json:"strict,omitempty"`
+}
+
+// FunctionToolFromStruct returns a function tool whose parameters are the JSON
+// Schema of the struct T, so the arguments of a call can be decoded into a T.
+// Properties are named after the json tags; fields without omitempty are
+// required. The jsonschema tag adds to the schema of a field, e.g.
+//
+//	type Weather struct {
+//		City string `json:"city" jsonschema:"description=City name, e.g. Paris"`
+//		Unit string `json:"unit,omitempty" jsonschema:"enum=celsius|fahrenheit"`
+//		Days int    `json:"days" jsonschema:"minimum=1,maximum=7"`
+//	}
+//
+// Supported keys are description, enum (values separated by |), minimum,
+// maximum, minLength, maxLength, pattern, format, and the required and
+// optional flags overriding omitempty. It panics when T is not a struct.
+func FunctionToolFromStruct[T any](name, description string) Tool {
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic("openai: FunctionToolFromStruct requires a struct, got " + t.String())
+	}
+	return Tool{
+		Type: "function",
+		Function: &FunctionDefinition{
+			Name:        name,
+			Description: description,
+			Parameters:  jsonSchema(t, map[reflect.Type]bool{}),
+		},
+	}
+}
+
+var (
+	timeType       = reflect.TypeFor[time.Time]()
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+)
+
+// jsonSchema returns the schema of the values of t as encoded by
+// encoding/json. visiting holds the structs being described, so recursive
+// types end with an unconstrained schema instead of looping.
+func jsonSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == rawMessageType:
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Encoded as base64 by encoding/json
+			return map[string]interface{}{"type": "string"}
+		}
+		return map[string]interface{}{"type": "array", "items": jsonSchema(t.Elem(), visiting)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchema(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return map[string]interface{}{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		properties := map[string]interface{}{}
+		required := []string{}
+		addStructFields(t, properties, &required, visiting)
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	}
+	// Interfaces and other kinds accept any value
+	return map[string]interface{}{}
+}
+
+// addStructFields adds the properties of the fields of t, flattening embedded
+// structs like encoding/json
+func addStructFields(t reflect.Type, properties map[string]interface{}, required *[]string, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addStructFields(embedded, properties, required, visiting)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := jsonSchema(field.Type, visiting)
+		isRequired := !strings.Contains(","+options+",", ",omitempty,")
+		for key, value := range parseSchemaTag(field.Tag.Get("jsonschema")) {
+			switch key {
+			case "required":
+				isRequired = true
+			case "optional":
+				isRequired = false
+			case "enum":
+				var values []interface{}
+				for _, v := range strings.Split(value, "|") {
+					values = append(values, schemaValue(schema, v))
+				}
+				schema["enum"] = values
+			case "minimum", "maximum", "minLength", "maxLength":
+				if n, err := strconv.ParseFloat(value, 64); err == nil {
+					schema[key] = n
+				}
+			default:
+				schema[key] = value
+			}
+		}
+
+		properties[name] = schema
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// parseSchemaTag splits a jsonschema tag into its keys. A segment without "="
+// that is not a flag continues the previous value, so descriptions can hold
+// commas.
+func parseSchemaTag(tag string) map[string]string {
+	values := map[string]string{}
+	last := ""
+	for _, segment := range strings.Split(tag, ",") {
+		key, value, ok := strings.Cut(segment, "=")
+		switch {
+		case ok:
+			last = strings.TrimSpace(key)
+			values[last] = value
+		case strings.TrimSpace(segment) == "required" || strings.TrimSpace(segment) == "optional":
+			values[strings.TrimSpace(segment)] = ""
+		case last != "":
+			values[last] += "," + segment
+		}
+	}
+	return values
+}
+
+// schemaValue converts an enum value to the type of the schema
+func schemaValue(schema map[string]interface{}, value string) interface{} {
+	switch schema["type"] {
+	case "integer":
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
@@ -1,5 +1,15 @@
 module github.com/bhirbec/go-openai
 
-go 1.22.0
+go 1.23.0
 
-require github.com/sashabaranov/go-openai v1.38.1
+require (
+	github.com/pkoukk/tiktoken-go v0.1.8
+	github.com/pkoukk/tiktoken-go-loader v0.0.2
+	github.com/sashabaranov/go-openai v1.38.1
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/dlclark/regexp2 v1.10.0 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+)
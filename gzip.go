@@ -0,0 +1,89 @@
+package openai
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WithGzip asks the API for gzip-compressed responses and compresses JSON
+// request bodies of at least minBytes, e.g. runs with many additional_messages.
+// With minBytes <= 0 only responses are compressed. Responses are decompressed
+// transparently whatever the transport.
+func WithGzip(minBytes int) Option {
+	return func(c *Client) {
+		c.gzip = true
+		c.gzipMinBytes = minBytes
+	}
+}
+
+// gzipRequest returns the request of an attempt with its body compressed when
+// it is large enough. The header is copied so the original request, replayed
+// by retries and read by the audit log, stays uncompressed.
+func (c *Client) gzipRequest(req *http.Request) (*http.Request, error) {
+	out := req.Clone(req.Context())
+	out.Header.Set("Accept-Encoding", "gzip")
+
+	if c.gzipMinBytes <= 0 || req.Body == nil || req.ContentLength < int64(c.gzipMinBytes) ||
+		req.Header.Get("Content-Encoding") != "" ||
+		!strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return out, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, err := io.Copy(zw, req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	compressed := buf.Bytes()
+	out.Body = io.NopCloser(bytes.NewReader(compressed))
+	out.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	out.ContentLength = int64(len(compressed))
+	out.Header.Set("Content-Encoding", "gzip")
+	return out, nil
+}
+
+// gunzipResponse decompresses a gzip-encoded response the transport left as is,
+// which it does when the request set Accept-Encoding itself
+func gunzipResponse(resp *http.Response) {
+	if resp.Uncompressed || !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return
+	}
+	resp.Body = &gzipBody{body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// gzipBody decompresses a response body. The gzip header is read on the first
+// Read so streamed responses are not blocked until their first event.
+type gzipBody struct {
+	body io.ReadCloser
+	zr   *gzip.Reader
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.zr == nil {
+		zr, err := gzip.NewReader(b.body)
+		if err != nil {
+			return 0, err
+		}
+		b.zr = zr
+	}
+	return b.zr.Read(p)
+}
+
+func (b *gzipBody) Close() error {
+	return b.body.Close()
+}
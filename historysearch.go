@@ -0,0 +1,297 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// HistoryHit is a logged message matching a SearchHistory query
+type HistoryHit struct {
+	ThreadID  string    `json:"thread_id"`
+	MessageID string    `json:"message_id"`
+	Role      string    `json:"role"`
+	CreatedAt Timestamp `json:"created_at"`
+	Snippet   string    `json:"snippet"` // Text around the first keyword found, or the start of the message
+	Score     float64   `json:"score"`
+}
+
+// HistorySearchOptions refines SearchHistory
+type HistorySearchOptions struct {
+	Limit     int      // Hits returned, 20 when 0
+	ThreadIDs []string // Threads searched, all the logged threads when empty
+
+	// Index ranks the messages by similarity to the query too, so messages
+	// about the topic match without containing its words. Messages missing
+	// from the index are matched by keywords only; see IndexHistory.
+	Index         *HistoryIndex
+	MinSimilarity float64 // Similarity below which a message without keywords is not a hit, 0.3 when 0
+}
+
+// HistoryIndex stores the embeddings of the messages of a ThreadLog in a JSON
+// lines file, for SearchHistory. Path must be outside of the log's directory.
+type HistoryIndex struct {
+	Path  string
+	Model openai.EmbeddingModel // text-embedding-3-small when empty
+
+	mu sync.Mutex
+}
+
+// historyIndexEntry is a line of a HistoryIndex
+type historyIndexEntry struct {
+	ThreadID  string    `json:"thread_id"`
+	MessageID string    `json:"message_id"`
+	Embedding []float32 `json:"embedding"`
+}
+
+func (x *HistoryIndex) model() openai.EmbeddingModel {
+	if x.Model == "" {
+		return memoryEmbeddingModel
+	}
+	return x.Model
+}
+
+// load returns the embeddings of the index by message ID
+func (x *HistoryIndex) load() (map[string][]float32, error) {
+	f, err := os.Open(x.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string][]float32{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	embeddings := map[string][]float32{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry historyIndexEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A line cut by a crash mid-append is the last one; ignore it
+			continue
+		}
+		embeddings[entry.MessageID] = entry.Embedding
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history index: %w", err)
+	}
+	return embeddings, nil
+}
+
+// IndexHistory embeds the logged messages missing from the index and appends
+// them to it. It returns the number of messages added.
+func (c *Client) IndexHistory(ctx context.Context, log *ThreadLog, index *HistoryIndex) (int, error) {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+
+	indexed, err := index.load()
+	if err != nil {
+		return 0, err
+	}
+	threadIDs, err := log.Threads()
+	if err != nil {
+		return 0, err
+	}
+
+	var entries []historyIndexEntry
+	var texts []string
+	for _, threadID := range threadIDs {
+		messages, err := log.Messages(threadID)
+		if err != nil {
+			return 0, err
+		}
+		for _, msg := range messages {
+			if _, ok := indexed[msg.ID]; ok {
+				continue
+			}
+			if text := messageText(msg); text != "" {
+				entries = append(entries, historyIndexEntry{ThreadID: threadID, MessageID: msg.ID})
+				texts = append(texts, text)
+			}
+		}
+	}
+	if len(texts) == 0 {
+		return 0, nil
+	}
+
+	vectors, err := c.embedTexts(ctx, texts, index.model())
+	if err != nil {
+		return 0, err
+	}
+	var buf bytes.Buffer
+	for i := range entries {
+		entries[i].Embedding = vectors[i]
+		line, err := json.Marshal(entries[i])
+		if err != nil {
+			return 0, err
+		}
+		buf.Write(append(line, '\n'))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(index.Path), 0o755); err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(index.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return 0, err
+	}
+	if err := f.Close(); err != nil {
+		return 0, err
+	}
+
+	c.logger.InfoContext(ctx, "history indexed", "messages", len(entries))
+	return len(entries), nil
+}
+
+// SearchHistory finds the logged messages about a topic, the best matches
+// first, e.g. for support staff looking for past conversations. Messages are
+// scored by the share of the query's words they contain and, with an index,
+// by their similarity to the query; the query is then embedded with one API
+// call. Without an index, no API call is made.
+func (c *Client) SearchHistory(ctx context.Context, log *ThreadLog, query string, opts HistorySearchOptions) ([]HistoryHit, error) {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	minSimilarity := opts.MinSimilarity
+	if minSimilarity <= 0 {
+		minSimilarity = 0.3
+	}
+
+	var embeddings map[string][]float32
+	var queryVector []float32
+	if opts.Index != nil {
+		opts.Index.mu.Lock()
+		var err error
+		embeddings, err = opts.Index.load()
+		opts.Index.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		vectors, err := c.embedTexts(ctx, []string{query}, opts.Index.model())
+		if err != nil {
+			return nil, err
+		}
+		queryVector = vectors[0]
+	}
+
+	threadIDs := opts.ThreadIDs
+	if len(threadIDs) == 0 {
+		var err error
+		if threadIDs, err = log.Threads(); err != nil {
+			return nil, err
+		}
+	}
+
+	var hits []HistoryHit
+	for _, threadID := range threadIDs {
+		messages, err := log.Messages(threadID)
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range messages {
+			text := messageText(msg)
+			if text == "" {
+				continue
+			}
+
+			lower := strings.ToLower(text)
+			found := 0
+			first := -1
+			for _, term := range terms {
+				if i := strings.Index(lower, term); i >= 0 {
+					found++
+					if first < 0 || i < first {
+						first = i
+					}
+				}
+			}
+			score := float64(found) / float64(len(terms))
+
+			if queryVector != nil {
+				similarity := 0.0
+				if embedding, ok := embeddings[msg.ID]; ok {
+					similarity = cosineSimilarity(queryVector, embedding)
+				}
+				if found == 0 && similarity < minSimilarity {
+					continue
+				}
+				score = (score + similarity) / 2
+			} else if found == 0 {
+				continue
+			}
+
+			hits = append(hits, HistoryHit{
+				ThreadID:  threadID,
+				MessageID: msg.ID,
+				Role:      msg.Role,
+				CreatedAt: msg.CreatedAt,
+				Snippet:   snippet(text, first),
+				Score:     score,
+			})
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].CreatedAt.After(hits[j].CreatedAt.Time)
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// messageText returns the text content of a message
+func messageText(msg Message) string {
+	var parts []string
+	for _, content := range msg.Content {
+		if content.Type == "text" {
+			parts = append(parts, content.Text.Value)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// snippetBytes is the length of the snippets of HistoryHit
+const snippetBytes = 200
+
+// snippet returns the text around the byte offset at, or the start of the
+// text when at is negative
+func snippet(text string, at int) string {
+	start := min(max(at-snippetBytes/4, 0), len(text))
+	for start > 0 && start < len(text) && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	cut := truncateUTF8(text[start:], snippetBytes)
+	out := strings.Join(strings.Fields(cut), " ")
+	if start > 0 {
+		out = "…" + out
+	}
+	if start+len(cut) < len(text) {
+		out += "…"
+	}
+	return out
+}
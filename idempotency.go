@@ -0,0 +1,44 @@
+package openai
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// idempotencyHeader carries the key letting the server recognize a retried request
+const idempotencyHeader = "Idempotency-Key"
+
+// WithIdempotencyKeys enables or disables the automatic Idempotency-Key header.
+// It is enabled by default: every POST request gets a random key, kept across
+// its retries, so a request retried after a timeout does not create the
+// resource twice.
+func WithIdempotencyKeys(enabled bool) Option {
+	return func(c *Client) {
+		c.noIdempotencyKeys = !enabled
+	}
+}
+
+// WithIdempotencyKey sets the Idempotency-Key of the request, e.g. to make a
+// create call safe to repeat after a crash. The key must be unique per
+// intended resource.
+func WithIdempotencyKey(key string) RequestOption {
+	return WithExtraHeader(idempotencyHeader, key)
+}
+
+// setIdempotencyKey gives POST requests a key unless they already have one
+func (c *Client) setIdempotencyKey(req *http.Request) {
+	if c.noIdempotencyKeys || req.Method != http.MethodPost || req.Header.Get(idempotencyHeader) != "" {
+		return
+	}
+	req.Header.Set(idempotencyHeader, newIdempotencyKey())
+}
+
+// newIdempotencyKey returns a random UUID (version 4)
+func newIdempotencyKey() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,80 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// imageTextPrompt asks the vision model for indexable text
+const imageTextPrompt = "Transcribe all text visible in this image, preserving reading order. " +
+	"If the image contains little or no text, describe its content in detail instead. " +
+	"Answer with the transcription or description only."
+
+// imageMIMETypes lists the image formats accepted by the vision models
+var imageMIMETypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+// IsImageFile reports whether the path has an image extension that vector stores
+// cannot index but ExtractImageText can handle
+func IsImageFile(path string) bool {
+	_, ok := imageMIMETypes[strings.ToLower(filepath.Ext(path))]
+	return ok
+}
+
+// ExtractImageText sends an image through a vision chat call and returns the text
+// it contains, or a description of the image when it has no text
+func (c *Client) ExtractImageText(ctx context.Context, path string) (string, error) {
+	mimeType, ok := imageMIMETypes[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return "", fmt.Errorf("unsupported image file: %s", path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	dataURL := "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString(content)
+	resp, err := c.createChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{{
+			Role: openai.ChatMessageRoleUser,
+			MultiContent: []openai.ChatMessagePart{
+				{Type: openai.ChatMessagePartTypeText, Text: imageTextPrompt},
+				{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: dataURL, Detail: openai.ImageURLDetailHigh}},
+			},
+		}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error extracting text from image %s: %w", path, sdkError(err))
+	}
+
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("no text returned for image %s", path)
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// UploadImageAsText extracts the text of an image with ExtractImageText and
+// uploads it as "<name>.txt" so it can be indexed by a vector store.
+// It returns the ID of the uploaded text file.
+func (c *Client) UploadImageAsText(ctx context.Context, path string) (string, error) {
+	text, err := c.ExtractImageText(ctx, path)
+	if err != nil {
+		return "", err
+	}
+
+	name := filepath.Base(path) + ".txt"
+	return c.UploadContent(ctx, name, []byte(text))
+}
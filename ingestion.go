@@ -0,0 +1,132 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// IngestionEvent describes a file synced into a vector store
+type IngestionEvent struct {
+	VectorStoreID string
+	FileID        string
+	Path          string // Path relative to its source, e.g. "docs/faq.md"
+	Status        string // Status of the vector store file, e.g. "completed"
+	LastError     string // Why the file failed, from its last_error
+}
+
+// IngestionObserver follows the files ApplyStack syncs into vector stores, so
+// an ingestion dashboard can show progress as it happens. Methods may be called
+// from several goroutines at once.
+type IngestionObserver interface {
+	OnFileQueued(ctx context.Context, event IngestionEvent)    // The file is attached, the vector store is processing it
+	OnFileCompleted(ctx context.Context, event IngestionEvent) // The file can be searched
+	OnFileFailed(ctx context.Context, event IngestionEvent)    // The file failed or was cancelled, see LastError
+}
+
+// WithIngestionObserver reports the files synced by ApplyStack to observer.
+// ApplyStack then waits for the vector stores to process the files it uploads
+// before returning.
+func WithIngestionObserver(observer IngestionObserver) Option {
+	return func(c *Client) {
+		c.ingestion = observer
+	}
+}
+
+// ingestionPollInterval is how often queued files are checked
+const ingestionPollInterval = time.Second
+
+// ingestionWatch reports the files queued in vector stores to the observer and
+// the progress writer as they are processed. A nil watch, used without either,
+// does nothing.
+type ingestionWatch struct {
+	c      *Client
+	ctx    context.Context
+	cancel context.CancelFunc
+	poller *Poller
+	wg     sync.WaitGroup
+
+	mu  sync.Mutex
+	err error // First failure to wait for a file
+}
+
+func (c *Client) watchIngestion(ctx context.Context) *ingestionWatch {
+	if c.ingestion == nil && c.progress == nil {
+		return nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	return &ingestionWatch{c: c, ctx: ctx, cancel: cancel, poller: NewPoller(c, ingestionPollInterval, 0)}
+}
+
+// queue reports a file attached to a vector store and starts waiting for it
+func (w *ingestionWatch) queue(event IngestionEvent) {
+	if w == nil {
+		return
+	}
+	event.Status = "in_progress"
+	w.report(event)
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		file, err := w.poller.WaitForVectorStoreFile(w.ctx, event.VectorStoreID, event.FileID)
+		if err != nil {
+			w.mu.Lock()
+			if w.err == nil {
+				w.err = fmt.Errorf("failed to wait for %s: %w", event.Path, err)
+			}
+			w.mu.Unlock()
+			return
+		}
+
+		event.Status = file.Status
+		if file.LastError != nil {
+			event.LastError = fmt.Sprintf("%v: %v", (*file.LastError)["code"], (*file.LastError)["message"])
+		}
+		w.report(event)
+	}()
+}
+
+// report passes an event to the observer and the progress writer, by status
+func (w *ingestionWatch) report(event IngestionEvent) {
+	progress := ProgressEvent{Name: event.Path, ID: event.FileID, Status: event.Status, Error: event.LastError}
+	switch event.Status {
+	case "in_progress":
+		progress.Event = "file_queued"
+		if w.c.ingestion != nil {
+			w.c.ingestion.OnFileQueued(w.ctx, event)
+		}
+	case "completed":
+		progress.Event = "file_ingested"
+		if w.c.ingestion != nil {
+			w.c.ingestion.OnFileCompleted(w.ctx, event)
+		}
+	default:
+		progress.Event = "file_failed"
+		if w.c.ingestion != nil {
+			w.c.ingestion.OnFileFailed(w.ctx, event)
+		}
+	}
+	w.c.emitProgress(progress)
+}
+
+// wait waits for the queued files to be processed
+func (w *ingestionWatch) wait() error {
+	if w == nil {
+		return nil
+	}
+	w.wg.Wait()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// stop stops waiting, e.g. when the sync fails
+func (w *ingestionWatch) stop() {
+	if w == nil {
+		return
+	}
+	w.cancel()
+	w.wg.Wait()
+}
@@ -0,0 +1,263 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalidOutput is wrapped by the errors of answers not matching the
+// schema they are decoded with
+var ErrInvalidOutput = errors.New("output does not match the schema")
+
+// OutputValidationError lists why the answer of a run does not match the
+// schema of the type it is decoded into
+type OutputValidationError struct {
+	RunID    string
+	Output   string
+	Problems []string // e.g. `$.city: required property is missing`
+}
+
+func (e *OutputValidationError) Error() string {
+	return fmt.Sprintf("output of run %s does not match the schema: %s", e.RunID, strings.Join(e.Problems, "; "))
+}
+
+// Is makes errors.Is(err, ErrInvalidOutput) hold for OutputValidationError
+func (e *OutputValidationError) Is(target error) bool {
+	return target == ErrInvalidOutput
+}
+
+// IntoOptions configures Into
+type IntoOptions struct {
+	// MaxAttempts is the number of answers tried, 1 when 0. Each retry sends
+	// the problems found back to the model and waits for a new run.
+	MaxAttempts  int
+	PollInterval time.Duration // Interval between run status checks, one second when 0
+}
+
+// Into decodes the last answer of a completed run into a T, after checking it
+// against the JSON schema of T, e.g. with the response format built by
+// ResponseFormatFromStruct. Strict mode still occasionally yields answers that
+// do not match; with MaxAttempts above 1, the model is asked to answer again
+// on the same thread, with the same assistant, model, instructions and
+// response format. It returns an *OutputValidationError when no answer
+// matches.
+func Into[T any](ctx context.Context, c *Client, run *Run, opts IntoOptions) (T, error) {
+	var zero T
+	t := reflect.TypeFor[T]()
+	schema := jsonSchema(t, map[reflect.Type]bool{})
+	maxAttempts := max(opts.MaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		if run.Status != "completed" {
+			return zero, fmt.Errorf("run %s ended with status %s", run.ID, run.Status)
+		}
+		output, err := c.runOutput(ctx, run)
+		if err != nil {
+			return zero, err
+		}
+
+		value, problems := decodeOutput[T](output, schema)
+		if len(problems) == 0 {
+			return value, nil
+		}
+		invalid := &OutputValidationError{RunID: run.ID, Output: output, Problems: problems}
+		if attempt >= maxAttempts {
+			return zero, invalid
+		}
+		c.logger.WarnContext(ctx, "run output does not match the schema, retrying", "thread_id", run.ThreadID, "run_id", run.ID, "attempt", attempt, "problems", len(problems))
+
+		feedback := "Your answer does not match the required JSON schema:\n- " + strings.Join(problems, "\n- ") +
+			"\n\nAnswer again with JSON matching the schema exactly."
+		if _, err := c.CreateMessage(ctx, &CreateMessageParams{ThreadID: run.ThreadID, Role: "user", Content: feedback}); err != nil {
+			return zero, fmt.Errorf("failed to send validation errors: %w", err)
+		}
+		model := run.Model
+		retry, err := c.CreateRun(ctx, run.ThreadID, &CreateRunParams{
+			AssistantID:    run.AssistantID,
+			Model:          &model,
+			Instructions:   run.Instructions,
+			ResponseFormat: run.ResponseFormat,
+		}, nil)
+		if err != nil {
+			return zero, err
+		}
+		if run, err = c.WaitForRun(ctx, retry.ThreadID, retry.ID, opts.PollInterval); err != nil {
+			return zero, err
+		}
+	}
+}
+
+// runOutput returns the text of the last assistant message of a run
+func (c *Client) runOutput(ctx context.Context, run *Run) (string, error) {
+	messages, err := c.ListMessages(ctx, run.ThreadID, WithRunID(run.ID), WithOrder("desc"), WithLimit(20))
+	if err != nil {
+		return "", err
+	}
+	for _, msg := range messages {
+		if msg.Role == "assistant" {
+			return messageText(msg), nil
+		}
+	}
+	return "", fmt.Errorf("run %s has no answer", run.ID)
+}
+
+// decodeOutput checks output against schema and decodes it into a T,
+// returning the problems found
+func decodeOutput[T any](output string, schema map[string]interface{}) (T, []string) {
+	var value T
+	decoder := json.NewDecoder(strings.NewReader(output))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return value, []string{"the answer is not valid JSON: " + err.Error()}
+	}
+	if decoder.More() {
+		return value, []string{"the answer holds text after the JSON value"}
+	}
+
+	var problems []string
+	validateSchema(generic, schema, "$", &problems)
+	if len(problems) > 0 {
+		return value, problems
+	}
+	if err := json.Unmarshal([]byte(output), &value); err != nil {
+		return value, []string{err.Error()}
+	}
+	return value, nil
+}
+
+// validateSchema appends to problems the ways value, decoded with UseNumber,
+// breaks the schema built by jsonSchema or ResponseFormatFromStruct. Optional
+// properties may be null, as strict mode makes them.
+func validateSchema(value interface{}, schema map[string]interface{}, path string, problems *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok {
+		for _, option := range anyOf {
+			var optionProblems []string
+			if option, ok := option.(map[string]interface{}); ok {
+				validateSchema(value, option, path, &optionProblems)
+			}
+			if len(optionProblems) == 0 {
+				return
+			}
+		}
+		fail("matches none of the allowed schemas")
+		return
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !slices.Contains(types, jsonType(value, types)) {
+		fail("expected %s, got %s", strings.Join(types, " or "), jsonType(value, types))
+		return
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && !slices.ContainsFunc(enum, func(allowed interface{}) bool { return sameJSON(allowed, value) }) {
+		fail("%s is not one of the allowed values", compactJSON(value))
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		properties, _ := schema["properties"].(map[string]interface{})
+		required, _ := schema["required"].([]string)
+		for _, name := range required {
+			if _, ok := value[name]; !ok {
+				fail("required property %q is missing", name)
+			}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := properties[name].(map[string]interface{})
+			switch {
+			case ok:
+				if value[name] == nil && !slices.Contains(required, name) {
+					continue
+				}
+				validateSchema(value[name], property, path+"."+name, problems)
+			case schema["additionalProperties"] == false:
+				fail("unexpected property %q", name)
+			default:
+				if additional, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+					validateSchema(value[name], additional, path+"."+name, problems)
+				}
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range value {
+				validateSchema(item, items, fmt.Sprintf("%s[%d]", path, i), problems)
+			}
+		}
+	case json.Number:
+		n, _ := value.Float64()
+		if minimum, ok := schema["minimum"].(float64); ok && n < minimum {
+			fail("%s is below the minimum %v", value, minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && n > maximum {
+			fail("%s is above the maximum %v", value, maximum)
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(value))
+		if minLength, ok := schema["minLength"].(float64); ok && length < minLength {
+			fail("shorter than %v characters", minLength)
+		}
+		if maxLength, ok := schema["maxLength"].(float64); ok && length > maxLength {
+			fail("longer than %v characters", maxLength)
+		}
+	}
+}
+
+// schemaTypes returns the types allowed by the type keyword of a schema
+func schemaTypes(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	}
+	return nil
+}
+
+// jsonType returns the JSON Schema type of a decoded value. Integral numbers
+// are integers when the schema allows them, numbers otherwise.
+func jsonType(value interface{}, allowed []string) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := value.Int64(); err == nil && slices.Contains(allowed, "integer") {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	}
+	return "object"
+}
+
+// sameJSON reports whether two values encode to the same JSON, so an enum
+// value such as int64(1) matches the decoded json.Number 1
+func sameJSON(a, b interface{}) bool {
+	return compactJSON(a) == compactJSON(b)
+}
+
+func compactJSON(v interface{}) string {
+	encoded, _ := json.Marshal(v)
+	return string(encoded)
+}
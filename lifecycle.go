@@ -0,0 +1,105 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrClientClosed is returned by the calls made after Close, and is the cause
+// of the calls cancelled when Close gives up waiting
+var ErrClientClosed = errors.New("client is closed")
+
+// lifecycle tracks the in-flight operations of a client
+type lifecycle struct {
+	mu       sync.Mutex
+	closed   bool
+	inflight sync.WaitGroup
+
+	abort       context.Context // Cancelled when Close reaches its deadline
+	abortCancel context.CancelFunc
+}
+
+func newLifecycle() *lifecycle {
+	abort, cancel := context.WithCancel(context.Background())
+	return &lifecycle{abort: abort, abortCancel: cancel}
+}
+
+type trackedKey struct{}
+
+// track registers an in-flight operation: a request until its response body is
+// closed, or a polling loop. The returned context is cancelled if Close gives
+// up waiting, and done must be called when the operation ends. Operations
+// started within a tracked one are part of it, so a poll in progress can
+// complete after Close.
+func (c *Client) track(ctx context.Context) (context.Context, func(), error) {
+	l := c.life
+	if ctx.Value(trackedKey{}) == l {
+		return ctx, func() {}, nil
+	}
+
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil, nil, ErrClientClosed
+	}
+	l.inflight.Add(1)
+	l.mu.Unlock()
+
+	ctx, cancel := context.WithCancelCause(context.WithValue(ctx, trackedKey{}, l))
+	stop := context.AfterFunc(l.abort, func() { cancel(ErrClientClosed) })
+
+	var once sync.Once
+	done := func() {
+		once.Do(func() {
+			stop()
+			cancel(nil)
+			l.inflight.Done()
+		})
+	}
+	return ctx, done, nil
+}
+
+// Close stops the client from accepting new calls and waits for the calls in
+// flight, streams and WaitForRun polls included, to finish. When ctx is done
+// first, the remaining calls are cancelled and ctx's error is returned. The
+// audit sink is then flushed if it has a Flush method.
+func (c *Client) Close(ctx context.Context) error {
+	l := c.life
+	l.mu.Lock()
+	l.closed = true
+	l.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		l.inflight.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		l.abortCancel()
+		err = ctx.Err()
+	}
+
+	if flusher, ok := c.auditSink.(interface{ Flush() error }); ok {
+		if flushErr := flusher.Flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+	}
+	return err
+}
+
+// trackedBody ends the tracking of a request when its response body is closed
+type trackedBody struct {
+	io.ReadCloser
+	done func()
+}
+
+func (b *trackedBody) Close() error {
+	defer b.done()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,68 @@
+package openai
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// ListOption sets a query parameter of a list call, e.g. ListVectorStores or
+// ListMessages
+type ListOption func(url.Values)
+
+// WithLimit sets the number of items returned, between 1 and 100. The API
+// returns 20 items when no limit is given.
+func WithLimit(n int) ListOption {
+	return func(q url.Values) {
+		if n > 0 {
+			q.Set("limit", strconv.Itoa(n))
+		}
+	}
+}
+
+// WithOrder sorts the items by creation date, "asc" or "desc"
+func WithOrder(order string) ListOption {
+	return func(q url.Values) {
+		if order != "" {
+			q.Set("order", order)
+		}
+	}
+}
+
+// WithAfter returns the items following the one with the given ID
+func WithAfter(cursor string) ListOption {
+	return func(q url.Values) {
+		if cursor != "" {
+			q.Set("after", cursor)
+		}
+	}
+}
+
+// WithBefore returns the items preceding the one with the given ID
+func WithBefore(cursor string) ListOption {
+	return func(q url.Values) {
+		if cursor != "" {
+			q.Set("before", cursor)
+		}
+	}
+}
+
+// WithRunID returns only the messages created by a run, for ListMessages
+func WithRunID(runID string) ListOption {
+	return func(q url.Values) {
+		if runID != "" {
+			q.Set("run_id", runID)
+		}
+	}
+}
+
+// listPath appends the query parameters set by opts to path
+func listPath(path string, opts []ListOption) string {
+	q := url.Values{}
+	for _, opt := range opts {
+		opt(q)
+	}
+	if len(q) == 0 {
+		return path
+	}
+	return path + "?" + q.Encode()
+}
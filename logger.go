@@ -0,0 +1,32 @@
+package openai
+
+import "context"
+
+// Logger receives the structured logs of a client. It is implemented by
+// *slog.Logger; args are alternating keys and values.
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...any)
+	InfoContext(ctx context.Context, msg string, args ...any)
+	WarnContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+}
+
+// WithLogger sets where the client logs the resources it creates and deletes
+// (info level) and the retries of failed requests (warn level). Clients log
+// nothing by default; use WithLogger(slog.Default()) to get the output.
+func WithLogger(logger Logger) Option {
+	return func(c *Client) {
+		if logger == nil {
+			logger = nopLogger{}
+		}
+		c.logger = logger
+	}
+}
+
+// nopLogger discards all logs
+type nopLogger struct{}
+
+func (nopLogger) DebugContext(context.Context, string, ...any) {}
+func (nopLogger) InfoContext(context.Context, string, ...any)  {}
+func (nopLogger) WarnContext(context.Context, string, ...any)  {}
+func (nopLogger) ErrorContext(context.Context, string, ...any) {}
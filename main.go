@@ -1,7 +1,432 @@
 package openai
 
-var openaiAPIKey string
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
+var (
+	defaultOnce sync.Once
+	std         atomic.Pointer[Client]
+)
+
+// Default returns the client used by the package-level functions. Unless one
+// was set with SetDefault or SetOpenAIKey, it is created on first use from the
+// OPENAI_API_KEY and OPENAI_BASE_URL environment variables.
+func Default() *Client {
+	defaultOnce.Do(func() {
+		var opts []Option
+		if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
+			opts = append(opts, WithBaseURL(baseURL))
+		}
+		std.CompareAndSwap(nil, NewClient(os.Getenv("OPENAI_API_KEY"), opts...))
+	})
+	return std.Load()
+}
+
+// SetDefault replaces the client used by the package-level functions
+func SetDefault(c *Client) {
+	std.Store(c)
+}
+
+// SetOpenAIKey makes the package-level functions use a client with the given key
 func SetOpenAIKey(key string) {
-	openaiAPIKey = key
+	SetDefault(NewClient(key))
+}
+
+// The package-level functions below keep the original API for small scripts:
+// they use the Default client and cannot be cancelled. Use a Client for several
+// keys or for context support.
+
+// ListAssistants calls Client.ListAssistants on the Default client
+func ListAssistants(opts ...ListOption) ([]Assistant, error) {
+	return Default().ListAssistants(context.Background(), opts...)
+}
+
+// CreateAssistant calls Client.CreateAssistant on the Default client
+func CreateAssistant(params *CreateAssistantParams) (*Assistant, error) {
+	return Default().CreateAssistant(context.Background(), params)
+}
+
+// RetrieveAssistant calls Client.RetrieveAssistant on the Default client
+func RetrieveAssistant(assistantID string) (*Assistant, error) {
+	return Default().RetrieveAssistant(context.Background(), assistantID)
+}
+
+// ModifyAssistant calls Client.ModifyAssistant on the Default client
+func ModifyAssistant(assistantID string, params *CreateAssistantParams) error {
+	return Default().ModifyAssistant(context.Background(), assistantID, params)
+}
+
+// DeleteAssistant calls Client.DeleteAssistant on the Default client
+func DeleteAssistant(assistantID string) error {
+	return Default().DeleteAssistant(context.Background(), assistantID)
+}
+
+// AttachVectorStore calls Client.AttachVectorStore on the Default client
+func AttachVectorStore(assistantID, vectorStoreID string) (*Assistant, error) {
+	return Default().AttachVectorStore(context.Background(), assistantID, vectorStoreID)
+}
+
+// DetachVectorStore calls Client.DetachVectorStore on the Default client
+func DetachVectorStore(assistantID, vectorStoreID string) (*Assistant, error) {
+	return Default().DetachVectorStore(context.Background(), assistantID, vectorStoreID)
+}
+
+// AttachCodeInterpreterFile calls Client.AttachCodeInterpreterFile on the Default client
+func AttachCodeInterpreterFile(assistantID, fileID string) (*Assistant, error) {
+	return Default().AttachCodeInterpreterFile(context.Background(), assistantID, fileID)
+}
+
+// DetachCodeInterpreterFile calls Client.DetachCodeInterpreterFile on the Default client
+func DetachCodeInterpreterFile(assistantID, fileID string) (*Assistant, error) {
+	return Default().DetachCodeInterpreterFile(context.Background(), assistantID, fileID)
+}
+
+// UploadFile calls Client.UploadFile on the Default client
+func UploadFile(path string) (string, error) {
+	return Default().UploadFile(context.Background(), path)
+}
+
+// UploadContent calls Client.UploadContent on the Default client
+func UploadContent(path string, content []byte) (string, error) {
+	return Default().UploadContent(context.Background(), path, content)
+}
+
+// ListFiles calls Client.ListFiles on the Default client
+func ListFiles(opts ...ListOption) ([]File, error) {
+	return Default().ListFiles(context.Background(), opts...)
+}
+
+// RetrieveFile calls Client.RetrieveFile on the Default client
+func RetrieveFile(fileID string) (*File, error) {
+	return Default().RetrieveFile(context.Background(), fileID)
+}
+
+// DeleteFile calls Client.DeleteFile on the Default client
+func DeleteFile(fileID string) error {
+	return Default().DeleteFile(context.Background(), fileID)
+}
+
+// CreateEmbedding calls Client.CreateEmbedding on the Default client
+func CreateEmbedding(filePath string) (string, error) {
+	return Default().CreateEmbedding(context.Background(), filePath)
+}
+
+// CreateVectorForFile calls Client.CreateVectorForFile on the Default client
+func CreateVectorForFile(filePath string) (string, error) {
+	return Default().CreateVectorForFile(context.Background(), filePath)
+}
+
+// CreateMessage calls Client.CreateMessage on the Default client
+func CreateMessage(params *CreateMessageParams) (*Message, error) {
+	return Default().CreateMessage(context.Background(), params)
+}
+
+// ListMessages calls Client.ListMessages on the Default client
+func ListMessages(threadID string, opts ...ListOption) ([]Message, error) {
+	return Default().ListMessages(context.Background(), threadID, opts...)
+}
+
+// ListRuns calls Client.ListRuns on the Default client
+func ListRuns(threadID string, opts ...ListOption) ([]Run, error) {
+	return Default().ListRuns(context.Background(), threadID, opts...)
+}
+
+// RunWithRetry calls Client.RunWithRetry on the Default client
+func RunWithRetry(threadID string, params *CreateRunParams, policy RunRetryPolicy) (*RunReport, error) {
+	return Default().RunWithRetry(context.Background(), threadID, params, policy)
+}
+
+// CompactThread calls Client.CompactThread on the Default client
+func CompactThread(threadID string, keep int, model string) (*Thread, error) {
+	return Default().CompactThread(context.Background(), threadID, keep, model)
+}
+
+// SyncThreadLog calls Client.SyncThreadLog on the Default client
+func SyncThreadLog(log *ThreadLog, threadID string) (int, error) {
+	return Default().SyncThreadLog(context.Background(), log, threadID)
+}
+
+// SearchHistory calls Client.SearchHistory on the Default client
+func SearchHistory(log *ThreadLog, query string, opts HistorySearchOptions) ([]HistoryHit, error) {
+	return Default().SearchHistory(context.Background(), log, query, opts)
+}
+
+// ThreadUsage calls Client.ThreadUsage on the Default client
+func ThreadUsage(threadID string) (*ThreadUsageReport, error) {
+	return Default().ThreadUsage(context.Background(), threadID)
+}
+
+// EnsureAssistant calls Client.EnsureAssistant on the Default client
+func EnsureAssistant(params *CreateAssistantParams, matchKey string) (*Assistant, error) {
+	return Default().EnsureAssistant(context.Background(), params, matchKey)
+}
+
+// CloneAssistant calls Client.CloneAssistant on the Default client
+func CloneAssistant(sourceID string, overrides AssistantOverrides) (*Assistant, error) {
+	return Default().CloneAssistant(context.Background(), sourceID, overrides)
+}
+
+// ExportAssistant calls Client.ExportAssistant on the Default client
+func ExportAssistant(assistantID string) (*AssistantSpec, error) {
+	return Default().ExportAssistant(context.Background(), assistantID)
+}
+
+// ApplyAssistantSpec calls Client.ApplyAssistantSpec on the Default client
+func ApplyAssistantSpec(spec *AssistantSpec) (*Assistant, error) {
+	return Default().ApplyAssistantSpec(context.Background(), spec)
+}
+
+// ForwardFile calls Client.ForwardFile on the Default client
+func ForwardFile(fileID string, uploader Uploader) (*ForwardedFile, error) {
+	return Default().ForwardFile(context.Background(), fileID, uploader)
+}
+
+// CreateRun calls Client.CreateRun on the Default client
+func CreateRun(threadID string, params *CreateRunParams, include []string) (*Run, error) {
+	return Default().CreateRun(context.Background(), threadID, params, include)
+}
+
+// RetrieveRun calls Client.RetrieveRun on the Default client
+func RetrieveRun(threadID, runID string) (*Run, error) {
+	return Default().RetrieveRun(context.Background(), threadID, runID)
+}
+
+// SubmitToolOutputs calls Client.SubmitToolOutputs on the Default client
+func SubmitToolOutputs(threadID, runID string, outputs []ToolOutput) (*Run, error) {
+	return Default().SubmitToolOutputs(context.Background(), threadID, runID, outputs)
+}
+
+// WaitForRun calls Client.WaitForRun on the Default client
+func WaitForRun(threadID, runID string, interval time.Duration) (*Run, error) {
+	return Default().WaitForRun(context.Background(), threadID, runID, interval)
+}
+
+// ListRunSteps calls Client.ListRunSteps on the Default client
+func ListRunSteps(threadID, runID string, opts ...ListOption) ([]RunStep, error) {
+	return Default().ListRunSteps(context.Background(), threadID, runID, opts...)
+}
+
+// CreateThread calls Client.CreateThread on the Default client
+func CreateThread(params *CreateThreadParams) (*Thread, error) {
+	return Default().CreateThread(context.Background(), params)
+}
+
+// RetrieveThread calls Client.RetrieveThread on the Default client
+func RetrieveThread(threadID string) (*Thread, error) {
+	return Default().RetrieveThread(context.Background(), threadID)
+}
+
+// ModifyThread calls Client.ModifyThread on the Default client
+func ModifyThread(threadID string, params *ModifyThreadParams) (*Thread, error) {
+	return Default().ModifyThread(context.Background(), threadID, params)
+}
+
+// DeleteThread calls Client.DeleteThread on the Default client
+func DeleteThread(threadID string) (*DeletionStatus, error) {
+	return Default().DeleteThread(context.Background(), threadID)
+}
+
+// CreateVectorStore calls Client.CreateVectorStore on the Default client
+func CreateVectorStore(params *CreateVectorStoreParams) (*VectorStore, error) {
+	return Default().CreateVectorStore(context.Background(), params)
+}
+
+// ListVectorStores calls Client.ListVectorStores on the Default client
+func ListVectorStores(opts ...ListOption) ([]VectorStore, error) {
+	return Default().ListVectorStores(context.Background(), opts...)
+}
+
+// RetrieveVectorStore calls Client.RetrieveVectorStore on the Default client
+func RetrieveVectorStore(vectorStoreID string) (*VectorStore, error) {
+	return Default().RetrieveVectorStore(context.Background(), vectorStoreID)
+}
+
+// ModifyVectorStore calls Client.ModifyVectorStore on the Default client
+func ModifyVectorStore(vectorStoreID string, params *ModifyVectorStoreParams) (*VectorStore, error) {
+	return Default().ModifyVectorStore(context.Background(), vectorStoreID, params)
+}
+
+// DeleteVectorStore calls Client.DeleteVectorStore on the Default client
+func DeleteVectorStore(vectorStoreID string) error {
+	return Default().DeleteVectorStore(context.Background(), vectorStoreID)
+}
+
+// SearchVectorStore calls Client.SearchVectorStore on the Default client
+func SearchVectorStore(vectorStoreID string, params *SearchVectorStoreParams) ([]VectorStoreSearchResult, error) {
+	return Default().SearchVectorStore(context.Background(), vectorStoreID, params)
+}
+
+// CreateVectorStoreFile calls Client.CreateVectorStoreFile on the Default client
+func CreateVectorStoreFile(vectorStoreID, fileID string, chunkingStrategy map[string]interface{}) (*VectorStoreFile, error) {
+	return Default().CreateVectorStoreFile(context.Background(), vectorStoreID, fileID, chunkingStrategy)
+}
+
+// ListVectorStoreFiles calls Client.ListVectorStoreFiles on the Default client
+func ListVectorStoreFiles(vectorStoreID string, opts ...ListOption) ([]VectorStoreFile, error) {
+	return Default().ListVectorStoreFiles(context.Background(), vectorStoreID, opts...)
+}
+
+// RetrieveVectorStoreFile calls Client.RetrieveVectorStoreFile on the Default client
+func RetrieveVectorStoreFile(vectorStoreID, fileID string) (*VectorStoreFile, error) {
+	return Default().RetrieveVectorStoreFile(context.Background(), vectorStoreID, fileID)
+}
+
+// DeleteVectorStoreFile calls Client.DeleteVectorStoreFile on the Default client
+func DeleteVectorStoreFile(vectorStoreID, fileID string) error {
+	return Default().DeleteVectorStoreFile(context.Background(), vectorStoreID, fileID)
+}
+
+// SnapshotVectorStores calls Client.SnapshotVectorStores on the Default client
+func SnapshotVectorStores() (*Snapshot[VectorStore], error) {
+	return Default().SnapshotVectorStores(context.Background())
+}
+
+// VectorStoresSince calls Client.VectorStoresSince on the Default client
+func VectorStoresSince(cursor string) (*Snapshot[VectorStore], error) {
+	return Default().VectorStoresSince(context.Background(), cursor)
+}
+
+// SnapshotVectorStoreFiles calls Client.SnapshotVectorStoreFiles on the Default client
+func SnapshotVectorStoreFiles(vectorStoreID string) (*Snapshot[VectorStoreFile], error) {
+	return Default().SnapshotVectorStoreFiles(context.Background(), vectorStoreID)
+}
+
+// VectorStoreFilesSince calls Client.VectorStoreFilesSince on the Default client
+func VectorStoreFilesSince(vectorStoreID, cursor string) (*Snapshot[VectorStoreFile], error) {
+	return Default().VectorStoreFilesSince(context.Background(), vectorStoreID, cursor)
+}
+
+// SnapshotMessages calls Client.SnapshotMessages on the Default client
+func SnapshotMessages(threadID string) (*Snapshot[Message], error) {
+	return Default().SnapshotMessages(context.Background(), threadID)
+}
+
+// MessagesSince calls Client.MessagesSince on the Default client
+func MessagesSince(threadID, cursor string) (*Snapshot[Message], error) {
+	return Default().MessagesSince(context.Background(), threadID, cursor)
+}
+
+// SnapshotRuns calls Client.SnapshotRuns on the Default client
+func SnapshotRuns(threadID string) (*Snapshot[Run], error) {
+	return Default().SnapshotRuns(context.Background(), threadID)
+}
+
+// RunsSince calls Client.RunsSince on the Default client
+func RunsSince(threadID, cursor string) (*Snapshot[Run], error) {
+	return Default().RunsSince(context.Background(), threadID, cursor)
+}
+
+// SnapshotFiles calls Client.SnapshotFiles on the Default client
+func SnapshotFiles() (*Snapshot[File], error) {
+	return Default().SnapshotFiles(context.Background())
+}
+
+// FilesSince calls Client.FilesSince on the Default client
+func FilesSince(cursor string) (*Snapshot[File], error) {
+	return Default().FilesSince(context.Background(), cursor)
+}
+
+// SnapshotAssistants calls Client.SnapshotAssistants on the Default client
+func SnapshotAssistants() (*Snapshot[Assistant], error) {
+	return Default().SnapshotAssistants(context.Background())
+}
+
+// AssistantsSince calls Client.AssistantsSince on the Default client
+func AssistantsSince(cursor string) (*Snapshot[Assistant], error) {
+	return Default().AssistantsSince(context.Background(), cursor)
+}
+
+// UploadTabular calls Client.UploadTabular on the Default client
+func UploadTabular(path string, rowsPerChunk int) ([]string, error) {
+	return Default().UploadTabular(context.Background(), path, rowsPerChunk)
+}
+
+// UploadBundled calls Client.UploadBundled on the Default client
+func UploadBundled(prefix string, docs []Document, maxBytes int) ([]string, error) {
+	return Default().UploadBundled(context.Background(), prefix, docs, maxBytes)
+}
+
+// ExtractImageText calls Client.ExtractImageText on the Default client
+func ExtractImageText(path string) (string, error) {
+	return Default().ExtractImageText(context.Background(), path)
+}
+
+// UploadImageAsText calls Client.UploadImageAsText on the Default client
+func UploadImageAsText(path string) (string, error) {
+	return Default().UploadImageAsText(context.Background(), path)
+}
+
+// DedupDocuments calls Client.DedupDocuments on the Default client
+func DedupDocuments(docs []Document, threshold float64) (*DedupResult, error) {
+	return Default().DedupDocuments(context.Background(), docs, threshold)
+}
+
+// ExpandQuery calls Client.ExpandQuery on the Default client
+func ExpandQuery(query string, n int) ([]string, error) {
+	return Default().ExpandQuery(context.Background(), query, n)
+}
+
+// SearchVectorStoreExpanded calls Client.SearchVectorStoreExpanded on the Default client
+func SearchVectorStoreExpanded(vectorStoreID string, params *SearchVectorStoreParams, n int) (*ExpandedSearchResult, error) {
+	return Default().SearchVectorStoreExpanded(context.Background(), vectorStoreID, params, n)
+}
+
+// ExtractMemories calls Client.ExtractMemories on the Default client
+func ExtractMemories(transcript string, known []string) ([]string, error) {
+	return Default().ExtractMemories(context.Background(), transcript, known)
+}
+
+// RememberThread calls Client.RememberThread on the Default client
+func RememberThread(store MemoryStore, userID, threadID string) ([]Memory, error) {
+	return Default().RememberThread(context.Background(), store, userID, threadID)
+}
+
+// RecallMemories calls Client.RecallMemories on the Default client
+func RecallMemories(store MemoryStore, userID, query string, limit int) ([]Memory, error) {
+	return Default().RecallMemories(context.Background(), store, userID, query, limit)
+}
+
+// MeasureEmbeddingDrift calls Client.MeasureEmbeddingDrift on the Default client
+func MeasureEmbeddingDrift(docs []EmbeddedDocument, model string, sampleSize, k int) (*DriftReport, error) {
+	return Default().MeasureEmbeddingDrift(context.Background(), docs, model, sampleSize, k)
+}
+
+// MigrateEmbeddings calls Client.MigrateEmbeddings on the Default client
+func MigrateEmbeddings(source EmbeddingSource, model string, sink EmbeddingSink, opts MigrationOptions) (*MigrationCheckpoint, error) {
+	return Default().MigrateEmbeddings(context.Background(), source, model, sink, opts)
+}
+
+// TranscribeAudio calls Client.TranscribeAudio on the Default client
+func TranscribeAudio(path string, config *TranscribeConfig) (*Transcript, error) {
+	return Default().TranscribeAudio(context.Background(), path, config)
+}
+
+// GenerateImage calls Client.GenerateImage on the Default client
+func GenerateImage(params *ImageParams) ([]Image, error) {
+	return Default().GenerateImage(context.Background(), params)
+}
+
+// Moderate calls Client.Moderate on the Default client
+func Moderate(model string, inputs ...string) ([]ModerationResult, error) {
+	return Default().Moderate(context.Background(), model, inputs...)
+}
+
+// PromoteFineTunedModel calls Client.PromoteFineTunedModel on the Default client
+func PromoteFineTunedModel(jobID string, config PromoteConfig) (*Promotion, error) {
+	return Default().PromoteFineTunedModel(context.Background(), jobID, config)
+}
+
+// SubmitBatch calls Client.SubmitBatch on the Default client
+func SubmitBatch(name string, requests []byte, endpoint string, metadata map[string]string) (*Batch, error) {
+	return Default().SubmitBatch(context.Background(), name, requests, endpoint, metadata)
+}
+
+// RetrieveBatch calls Client.RetrieveBatch on the Default client
+func RetrieveBatch(batchID string) (*Batch, error) {
+	return Default().RetrieveBatch(context.Background(), batchID)
 }
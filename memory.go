@@ -0,0 +1,228 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// memoryEmbeddingModel is used to rank memories by relevance
+const memoryEmbeddingModel = openai.SmallEmbedding3
+
+// Memory is a durable fact about a user extracted from past conversations
+type Memory struct {
+	Fact      string    `json:"fact"`
+	Embedding []float32 `json:"embedding,omitempty"`
+	ThreadID  string    `json:"thread_id,omitempty"` // Thread the fact was extracted from
+	CreatedAt int64     `json:"created_at"`
+}
+
+// MemoryStore persists memories per user. Implementations must be safe for
+// concurrent use.
+type MemoryStore interface {
+	SaveMemories(userID string, memories []Memory) error
+	LoadMemories(userID string) ([]Memory, error)
+}
+
+// MapMemoryStore is an in-process MemoryStore
+type MapMemoryStore struct {
+	mu       sync.RWMutex
+	memories map[string][]Memory
+}
+
+// NewMapMemoryStore returns an empty in-process memory store
+func NewMapMemoryStore() *MapMemoryStore {
+	return &MapMemoryStore{memories: map[string][]Memory{}}
+}
+
+// SaveMemories appends memories for the user
+func (s *MapMemoryStore) SaveMemories(userID string, memories []Memory) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.memories[userID] = append(s.memories[userID], memories...)
+	return nil
+}
+
+// LoadMemories returns all the memories of the user, oldest first
+func (s *MapMemoryStore) LoadMemories(userID string) ([]Memory, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]Memory(nil), s.memories[userID]...), nil
+}
+
+// memoryExtractionSchema constrains the extraction model to a list of facts
+var memoryExtractionSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"facts": {"type": "array", "items": {"type": "string"}}
+	},
+	"required": ["facts"],
+	"additionalProperties": false
+}`)
+
+// ExtractMemories asks a model for the durable facts about the user found in a
+// conversation transcript. Facts already known are passed so they are not
+// extracted again.
+func (c *Client) ExtractMemories(ctx context.Context, transcript string, known []string) ([]string, error) {
+	instructions := "You maintain a long-term memory about a user across conversations. " +
+		"From the conversation below, extract durable facts about the user that will still be useful " +
+		"in future conversations: preferences, personal or professional details, ongoing projects, constraints. " +
+		"Ignore one-off requests and transient details. Write each fact as a short standalone sentence. " +
+		"Return an empty list when there is nothing worth remembering."
+	if len(known) > 0 {
+		instructions += "\n\nAlready known, do not repeat:\n- " + strings.Join(known, "\n- ")
+	}
+
+	resp, err := c.createChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: instructions},
+			{Role: openai.ChatMessageRoleUser, Content: transcript},
+		},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   "memories",
+				Schema: memoryExtractionSchema,
+				Strict: true,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error extracting memories: %w", sdkError(err))
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no memories returned")
+	}
+
+	var extraction struct {
+		Facts []string `json:"facts"`
+	}
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &extraction); err != nil {
+		return nil, fmt.Errorf("failed to decode memories: %w", err)
+	}
+
+	var facts []string
+	for _, fact := range extraction.Facts {
+		if fact = strings.TrimSpace(fact); fact != "" {
+			facts = append(facts, fact)
+		}
+	}
+	return facts, nil
+}
+
+// RememberThread extracts new facts from the messages of a thread and saves them
+// for the user. It returns the memories that were added.
+func (c *Client) RememberThread(ctx context.Context, store MemoryStore, userID, threadID string) ([]Memory, error) {
+	snap, err := c.SnapshotMessages(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	var transcript strings.Builder
+	for _, msg := range snap.Items {
+		for _, content := range msg.Content {
+			if content.Type == "text" {
+				fmt.Fprintf(&transcript, "%s: %s\n", msg.Role, content.Text.Value)
+			}
+		}
+	}
+	if transcript.Len() == 0 {
+		return nil, nil
+	}
+
+	existing, err := store.LoadMemories(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load memories: %w", err)
+	}
+	known := make([]string, len(existing))
+	for i, m := range existing {
+		known[i] = m.Fact
+	}
+
+	facts, err := c.ExtractMemories(ctx, transcript.String(), known)
+	if err != nil || len(facts) == 0 {
+		return nil, err
+	}
+
+	vectors, err := c.embedTexts(ctx, facts, memoryEmbeddingModel)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	memories := make([]Memory, len(facts))
+	for i, fact := range facts {
+		memories[i] = Memory{Fact: fact, Embedding: vectors[i], ThreadID: threadID, CreatedAt: now}
+	}
+	if err := store.SaveMemories(userID, memories); err != nil {
+		return nil, fmt.Errorf("failed to save memories: %w", err)
+	}
+	return memories, nil
+}
+
+// RecallMemories returns up to limit memories of the user, the most relevant to
+// query first. With an empty query the most recent memories are returned.
+func (c *Client) RecallMemories(ctx context.Context, store MemoryStore, userID, query string, limit int) ([]Memory, error) {
+	memories, err := store.LoadMemories(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load memories: %w", err)
+	}
+
+	if query == "" {
+		sort.SliceStable(memories, func(i, j int) bool {
+			return memories[i].CreatedAt > memories[j].CreatedAt
+		})
+	} else if len(memories) > 0 {
+		vectors, err := c.embedTexts(ctx, []string{query}, memoryEmbeddingModel)
+		if err != nil {
+			return nil, err
+		}
+		scores := make([]float64, len(memories))
+		order := make([]int, len(memories))
+		for i := range memories {
+			scores[i] = cosineSimilarity(vectors[0], memories[i].Embedding)
+			order[i] = i
+		}
+		sort.SliceStable(order, func(i, j int) bool {
+			return scores[order[i]] > scores[order[j]]
+		})
+		ranked := make([]Memory, len(memories))
+		for i, idx := range order {
+			ranked[i] = memories[idx]
+		}
+		memories = ranked
+	}
+
+	if limit > 0 && len(memories) > limit {
+		memories = memories[:limit]
+	}
+	return memories, nil
+}
+
+// InjectMemories appends the memories to the additional instructions of a run
+func InjectMemories(params *CreateRunParams, memories []Memory) {
+	if len(memories) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	if params.AdditionalInstructions != nil && *params.AdditionalInstructions != "" {
+		b.WriteString(*params.AdditionalInstructions)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("Facts remembered about the user from previous conversations:")
+	for _, m := range memories {
+		b.WriteString("\n- ")
+		b.WriteString(m.Fact)
+	}
+
+	instructions := b.String()
+	params.AdditionalInstructions = &instructions
+}
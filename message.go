@@ -1,25 +1,80 @@
 package openai
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 )
 
 // Message represents a single message in a thread
 type Message struct {
-	ID          string                 `json:"id"`
-	Object      string                 `json:"object"`
-	CreatedAt   int64                  `json:"created_at"`
-	AssistantID *string                `json:"assistant_id,omitempty"`
-	ThreadID    string                 `json:"thread_id"`
-	RunID       *string                `json:"run_id,omitempty"`
-	Role        string                 `json:"role"`
-	Content     []MessageContent       `json:"content"`
-	Attachments []interface{}          `json:"attachments,omitempty"`
-	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	ID          string              `json:"id"`
+	Object      string              `json:"object"`
+	CreatedAt   Timestamp           `json:"created_at"`
+	AssistantID *string             `json:"assistant_id,omitempty"`
+	ThreadID    string              `json:"thread_id"`
+	RunID       *string             `json:"run_id,omitempty"`
+	Role        string              `json:"role"`
+	Content     []MessageContent    `json:"content"`
+	Attachments []MessageAttachment `json:"attachments,omitempty"`
+	Metadata    Metadata            `json:"metadata,omitempty"`
+
+	// Status is "in_progress", "completed" or "incomplete". The message of a
+	// run that was interrupted, e.g. by its token limits or a cancellation,
+	// is incomplete and IncompleteDetails tells why.
+	Status            string             `json:"status,omitempty"`
+	IncompleteDetails *IncompleteDetails `json:"incomplete_details,omitempty"`
+	CompletedAt       *Timestamp         `json:"completed_at,omitempty"`
+	IncompleteAt      *Timestamp         `json:"incomplete_at,omitempty"`
+}
+
+// IsIncomplete reports whether the message is a partial answer of an
+// interrupted run, which a UI may offer to retry
+func (m *Message) IsIncomplete() bool {
+	return m.Status == "incomplete"
+}
+
+// MessageAttachment is a file attached to a message and the tools it was
+// added to
+type MessageAttachment struct {
+	FileID string           `json:"file_id"`
+	Tools  []AttachmentTool `json:"tools,omitempty"`
+}
+
+// NewMessageAttachment returns the attachment of a file added to tools, e.g.
+// "file_search" to search it or "code_interpreter" to read it in code
+func NewMessageAttachment(fileID string, tools ...string) MessageAttachment {
+	attachment := MessageAttachment{FileID: fileID}
+	for _, tool := range tools {
+		attachment.Tools = append(attachment.Tools, AttachmentTool{Type: tool})
+	}
+	return attachment
+}
+
+// AttachmentTool is a tool a message attachment is added to
+type AttachmentTool struct {
+	Type string `json:"type"` // "code_interpreter" or "file_search"
+}
+
+// UnmarshalJSON decodes a message, tolerating legacy data: the file_ids of
+// Assistants v1 messages become attachments without tools
+func (m *Message) UnmarshalJSON(data []byte) error {
+	type message Message
+	var raw struct {
+		message
+		FileIDs []string `json:"file_ids"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*m = Message(raw.message)
+	if len(m.Attachments) == 0 {
+		for _, fileID := range raw.FileIDs {
+			m.Attachments = append(m.Attachments, MessageAttachment{FileID: fileID})
+		}
+	}
+	return nil
 }
 
 // MessageContent represents the content structure within a message
@@ -30,19 +85,38 @@ type MessageContent struct {
 
 // ContentText holds the textual content of a message
 type ContentText struct {
-	Value       string        `json:"value"`
-	Annotations []interface{} `json:"annotations,omitempty"`
+	Value       string           `json:"value"`
+	Annotations []TextAnnotation `json:"annotations,omitempty"`
+}
+
+// TextAnnotation marks the part of a text citing a file searched by the
+// file_search tool, or linking to a file generated by the code_interpreter
+// tool
+type TextAnnotation struct {
+	Type       string `json:"type"` // "file_citation" or "file_path"
+	Text       string `json:"text"` // Text to replace in the message, e.g. "【4:0†source】"
+	StartIndex int    `json:"start_index"`
+	EndIndex   int    `json:"end_index"`
+
+	FileCitation *struct {
+		FileID string `json:"file_id"`
+	} `json:"file_citation,omitempty"`
+	FilePath *struct {
+		FileID string `json:"file_id"`
+	} `json:"file_path,omitempty"`
 }
 
 // CreateMessageParams holds the parameters for creating a new message
 type CreateMessageParams struct {
-	ThreadID string `json:"-"`       // Not part of the request body but needed to construct the URL
-	Role     string `json:"role"`    // e.g., "user" or "assistant"
-	Content  string `json:"content"` // The message content
+	ThreadID    string              `json:"-"`       // Not part of the request body but needed to construct the URL
+	Role        string              `json:"role"`    // e.g., "user" or "assistant"
+	Content     string              `json:"content"` // The message content
+	Attachments []MessageAttachment `json:"attachments,omitempty"`
+	Metadata    Metadata            `json:"metadata,omitempty"`
 }
 
 // CreateMessage creates a new message in a given thread.
-func CreateMessage(params *CreateMessageParams) (*Message, error) {
+func (c *Client) CreateMessage(ctx context.Context, params *CreateMessageParams) (*Message, error) {
 	if params.ThreadID == "" {
 		return nil, fmt.Errorf("threadID is required")
 	}
@@ -53,96 +127,21 @@ func CreateMessage(params *CreateMessageParams) (*Message, error) {
 		return nil, fmt.Errorf("content is required")
 	}
 
-	url := fmt.Sprintf("https://api.openai.com/v1/threads/%s/messages", params.ThreadID)
-	body, err := json.Marshal(map[string]string{
-		"role":    params.Role,
-		"content": params.Content,
-	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal message content: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request to create message: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request to create message failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to create message with status %s: %s", resp.Status, string(body))
-	}
-
-	var result struct {
-		Data Message `json:"data"`
+	var message Message
+	if err := c.do(ctx, "POST", "/threads/"+params.ThreadID+"/messages", params, &message); err != nil {
+		return nil, fmt.Errorf("failed to create message: %w", err)
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode message response: %w", err)
-	}
-
-	return &result.Data, nil
+	return &message, nil
 }
 
-// ListMessages retrieves a list of messages from a given thread with optional query parameters
-func ListMessages(threadID string, limit int, order, after, before, runID string) ([]Message, error) {
-	url := fmt.Sprintf("https://api.openai.com/v1/threads/%s/messages", threadID)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request to list messages: %w", err)
-	}
-
-	// Set query parameters based on provided values
-	q := req.URL.Query()
-	if limit > 0 {
-		q.Add("limit", fmt.Sprintf("%d", limit))
-	}
-	if order != "" {
-		q.Add("order", order)
-	}
-	if after != "" {
-		q.Add("after", after)
-	}
-	if before != "" {
-		q.Add("before", before)
-	}
-	if runID != "" {
-		q.Add("run_id", runID)
-	}
-	req.URL.RawQuery = q.Encode()
-
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("request to list messages failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to list messages with status %s: %s", resp.Status, string(body))
-	}
-
+// ListMessages retrieves the messages of a thread, e.g.
+// ListMessages(ctx, threadID, WithOrder("asc"), WithRunID(runID))
+func (c *Client) ListMessages(ctx context.Context, threadID string, opts ...ListOption) ([]Message, error) {
 	var result struct {
 		Data []Message `json:"data"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode messages response: %w", err)
+	if err := c.do(ctx, "GET", listPath("/threads/"+threadID+"/messages", opts), nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
 	}
-
 	return result.Data, nil
 }
@@ -0,0 +1,154 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits of the metadata of assistants, threads, messages, runs and vector
+// stores
+const (
+	MaxMetadataKeys        = 16
+	MaxMetadataKeyLength   = 64
+	MaxMetadataValueLength = 512
+)
+
+// ErrInvalidMetadata is wrapped by the errors of metadata exceeding the limits
+var ErrInvalidMetadata = errors.New("invalid metadata")
+
+// Metadata is the set of key-value pairs attached to an object. Values that
+// are not strings, as found in old objects, are decoded as their JSON text.
+type Metadata map[string]string
+
+// Validate reports the first limit the metadata exceeds
+func (m Metadata) Validate() error {
+	if len(m) > MaxMetadataKeys {
+		return fmt.Errorf("%w: %d keys, at most %d allowed", ErrInvalidMetadata, len(m), MaxMetadataKeys)
+	}
+	for _, key := range m.sortedKeys() {
+		if n := utf8.RuneCountInString(key); n > MaxMetadataKeyLength {
+			return fmt.Errorf("%w: key %q has %d characters, at most %d allowed", ErrInvalidMetadata, key, n, MaxMetadataKeyLength)
+		}
+		if n := utf8.RuneCountInString(m[key]); n > MaxMetadataValueLength {
+			return fmt.Errorf("%w: value of %q has %d characters, at most %d allowed", ErrInvalidMetadata, key, n, MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
+
+// Truncate returns a copy of the metadata within the limits: values are cut,
+// keys too long are dropped, and only the first keys in sorted order are kept
+func (m Metadata) Truncate() Metadata {
+	if m == nil {
+		return nil
+	}
+	out := Metadata{}
+	for _, key := range m.sortedKeys() {
+		if len(out) == MaxMetadataKeys {
+			break
+		}
+		if utf8.RuneCountInString(key) > MaxMetadataKeyLength {
+			continue
+		}
+		value := m[key]
+		if utf8.RuneCountInString(value) > MaxMetadataValueLength {
+			value = string([]rune(value)[:MaxMetadataValueLength])
+		}
+		out[key] = value
+	}
+	return out
+}
+
+func (m Metadata) sortedKeys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (m *Metadata) UnmarshalJSON(data []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*m = nil
+		return nil
+	}
+
+	*m = make(Metadata, len(raw))
+	for key, value := range raw {
+		switch value := value.(type) {
+		case nil:
+		case string:
+			(*m)[key] = value
+		default:
+			text, _ := json.Marshal(value)
+			(*m)[key] = string(text)
+		}
+	}
+	return nil
+}
+
+// MetadataPolicy is what the client does with metadata exceeding the limits
+type MetadataPolicy int
+
+const (
+	MetadataReject    MetadataPolicy = iota // Fail the call with ErrInvalidMetadata, the default
+	MetadataTruncate                        // Send the metadata truncated, see Metadata.Truncate
+	MetadataUnchecked                       // Send the metadata as is, for the API to judge
+)
+
+// WithMetadataPolicy sets what the client does with the metadata of the
+// objects it creates or modifies when it exceeds the limits, default metadata
+// included
+func WithMetadataPolicy(policy MetadataPolicy) Option {
+	return func(c *Client) {
+		c.metadataPolicy = policy
+	}
+}
+
+// checkMetadata applies the metadata policy to a request accepting metadata.
+// The body is only rewritten when the metadata is truncated.
+func (c *Client) checkMetadata(req *http.Request) error {
+	if c.metadataPolicy == MetadataUnchecked || req.GetBody == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	var payload struct {
+		Metadata Metadata `json:"metadata"`
+	}
+	err = json.NewDecoder(body).Decode(&payload)
+	body.Close()
+	if err != nil {
+		return nil
+	}
+
+	err = payload.Metadata.Validate()
+	if err == nil {
+		return nil
+	}
+	if c.metadataPolicy != MetadataTruncate {
+		return err
+	}
+	c.logger.WarnContext(req.Context(), "metadata truncated", "path", c.relativePath(req), "reason", err)
+	return rewriteJSONBody(req, func(fields map[string]json.RawMessage) error {
+		raw, err := json.Marshal(payload.Metadata.Truncate())
+		if err != nil {
+			return err
+		}
+		fields["metadata"] = raw
+		return nil
+	})
+}
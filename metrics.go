@@ -0,0 +1,65 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestMetric describes one HTTP request, retries being separate requests
+type RequestMetric struct {
+	Method     string
+	Endpoint   string // Path template with IDs replaced, e.g. "/threads/{id}/runs"
+	StatusCode int    // 0 when the request failed without a response
+	Duration   time.Duration
+	Retry      int   // 0 for the first attempt
+	Err        error // Network error or timeout, nil when a response was received
+}
+
+// Metrics receives the measurements of a client, e.g. to export them to
+// Prometheus or OpenTelemetry. Methods may be called from several goroutines
+// at once and should not block.
+type Metrics interface {
+	// ObserveRequest is called once per HTTP request
+	ObserveRequest(ctx context.Context, metric RequestMetric)
+
+	// ObserveUsage is called with the token usage of chat, embeddings and
+	// run calls, see WithUsageHook
+	ObserveUsage(ctx context.Context, usage Usage)
+}
+
+// WithMetrics reports the requests and token usage of the client to metrics
+func WithMetrics(metrics Metrics) Option {
+	return func(c *Client) {
+		c.metrics = metrics
+		WithUsageHook(metrics.ObserveUsage)(c)
+	}
+}
+
+// endpointTemplate replaces the resource IDs of a path with "{id}" so it can
+// be used as a low-cardinality label
+func endpointTemplate(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if resourceID.MatchString(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// observeRequest reports an attempt to the metrics
+func (c *Client) observeRequest(req *http.Request, retry int, start time.Time, resp *http.Response, err error) {
+	metric := RequestMetric{
+		Method:   req.Method,
+		Endpoint: endpointTemplate(c.relativePath(req)),
+		Duration: c.clock.Now().Sub(start),
+		Retry:    retry,
+		Err:      err,
+	}
+	if resp != nil {
+		metric.StatusCode = resp.StatusCode
+	}
+	c.metrics.ObserveRequest(req.Context(), metric)
+}
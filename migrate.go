@@ -0,0 +1,199 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// EmbeddingSource reads the documents of an index to re-embed
+type EmbeddingSource interface {
+	// ReadDocuments returns up to limit documents following cursor, "" for the
+	// first ones, and the cursor to read the next ones. No documents means the
+	// end of the index. The order must be stable so a migration can resume.
+	ReadDocuments(ctx context.Context, cursor string, limit int) (docs []Document, next string, err error)
+}
+
+// EmbeddingSink stores the generations of an index. A migration writes to a new
+// generation while the live one keeps serving queries.
+type EmbeddingSink interface {
+	WriteEmbeddings(ctx context.Context, generation string, docs []EmbeddedDocument) error
+
+	// SwapGeneration atomically makes generation the live one
+	SwapGeneration(ctx context.Context, generation string) error
+}
+
+// MigrationCheckpoint is the progress of a migration
+type MigrationCheckpoint struct {
+	Model      string `json:"model"`
+	Generation string `json:"generation"`
+	Cursor     string `json:"cursor"`   // Cursor of the next documents to read
+	Migrated   int    `json:"migrated"` // Number of documents written to the generation
+	Done       bool   `json:"done"`     // Whether the generation was swapped in
+}
+
+// MigrationCheckpointStore persists the progress of a migration
+type MigrationCheckpointStore interface {
+	// LoadCheckpoint returns the saved checkpoint, nil when there is none
+	LoadCheckpoint(ctx context.Context) (*MigrationCheckpoint, error)
+	SaveCheckpoint(ctx context.Context, checkpoint *MigrationCheckpoint) error
+}
+
+// MigrationOptions configures MigrateEmbeddings
+type MigrationOptions struct {
+	BatchSize int // Documents read and embedded at once, 100 when 0
+
+	// Checkpoints saves the progress after each batch, so a migration that
+	// failed or was interrupted resumes where it stopped. Without it, a
+	// migration starts over.
+	Checkpoints MigrationCheckpointStore
+
+	// Generation names the new generation of the index, "<model>-<unix time>"
+	// when empty. It is ignored when resuming.
+	Generation string
+}
+
+// MigrateEmbeddings re-embeds every document of source with model and writes
+// them to a new generation of sink, which is swapped in once all documents are
+// written, e.g. to move an index from text-embedding-ada-002 to
+// text-embedding-3-small. See MeasureEmbeddingDrift to assess the change first.
+func (c *Client) MigrateEmbeddings(ctx context.Context, source EmbeddingSource, model string, sink EmbeddingSink, opts MigrationOptions) (*MigrationCheckpoint, error) {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = embeddingBatchSize
+	}
+
+	checkpoint, err := c.loadMigrationCheckpoint(ctx, model, opts)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint.Done {
+		return checkpoint, nil
+	}
+
+	for {
+		docs, next, err := source.ReadDocuments(ctx, checkpoint.Cursor, batchSize)
+		if err != nil {
+			return checkpoint, fmt.Errorf("reading documents failed: %w", err)
+		}
+		if len(docs) == 0 {
+			break
+		}
+
+		texts := make([]string, len(docs))
+		for i, doc := range docs {
+			texts[i] = string(doc.Content)
+		}
+		vectors, err := c.embedTexts(ctx, texts, openai.EmbeddingModel(model))
+		if err != nil {
+			return checkpoint, err
+		}
+
+		embedded := make([]EmbeddedDocument, len(docs))
+		for i, doc := range docs {
+			embedded[i] = EmbeddedDocument{Document: doc, Embedding: vectors[i]}
+		}
+		if err := sink.WriteEmbeddings(ctx, checkpoint.Generation, embedded); err != nil {
+			return checkpoint, fmt.Errorf("writing embeddings failed: %w", err)
+		}
+
+		checkpoint.Cursor = next
+		checkpoint.Migrated += len(docs)
+		if err := saveMigrationCheckpoint(ctx, opts, checkpoint); err != nil {
+			return checkpoint, err
+		}
+		c.logger.InfoContext(ctx, "embeddings migrated", "generation", checkpoint.Generation, "migrated", checkpoint.Migrated)
+	}
+
+	if err := sink.SwapGeneration(ctx, checkpoint.Generation); err != nil {
+		return checkpoint, fmt.Errorf("generation swap failed: %w", err)
+	}
+	checkpoint.Done = true
+	if err := saveMigrationCheckpoint(ctx, opts, checkpoint); err != nil {
+		return checkpoint, err
+	}
+	c.logger.InfoContext(ctx, "embedding generation swapped", "generation", checkpoint.Generation, "model", model)
+	return checkpoint, nil
+}
+
+// loadMigrationCheckpoint returns the checkpoint to resume from, or a new one
+func (c *Client) loadMigrationCheckpoint(ctx context.Context, model string, opts MigrationOptions) (*MigrationCheckpoint, error) {
+	if opts.Checkpoints != nil {
+		checkpoint, err := opts.Checkpoints.LoadCheckpoint(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("loading checkpoint failed: %w", err)
+		}
+		if checkpoint != nil {
+			if checkpoint.Model != model {
+				return nil, fmt.Errorf("checkpoint is for a migration to %s, not %s", checkpoint.Model, model)
+			}
+			return checkpoint, nil
+		}
+	}
+
+	generation := opts.Generation
+	if generation == "" {
+		generation = fmt.Sprintf("%s-%d", model, c.clock.Now().Unix())
+	}
+	return &MigrationCheckpoint{Model: model, Generation: generation}, nil
+}
+
+func saveMigrationCheckpoint(ctx context.Context, opts MigrationOptions, checkpoint *MigrationCheckpoint) error {
+	if opts.Checkpoints == nil {
+		return nil
+	}
+	if err := opts.Checkpoints.SaveCheckpoint(ctx, checkpoint); err != nil {
+		return fmt.Errorf("saving checkpoint failed: %w", err)
+	}
+	return nil
+}
+
+// FileCheckpointStore saves a migration checkpoint as a JSON file
+type FileCheckpointStore struct {
+	Path string
+}
+
+// LoadCheckpoint reads the checkpoint, nil when the file does not exist
+func (s *FileCheckpointStore) LoadCheckpoint(_ context.Context) (*MigrationCheckpoint, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint MigrationCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// SaveCheckpoint replaces the file atomically, so an interrupted write leaves
+// the previous checkpoint
+func (s *FileCheckpointStore) SaveCheckpoint(_ context.Context, checkpoint *MigrationCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.Path)
+}
@@ -0,0 +1,167 @@
+package openai
+
+import (
+	"context"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Endpoints reported in ModelRequest and Usage
+const (
+	EndpointChat       = "chat"
+	EndpointRun        = "run"
+	EndpointEmbeddings = "embeddings"
+	EndpointImages     = "images"
+)
+
+// ModelRequest describes an outgoing request whose model a ModelRouter may change
+type ModelRequest struct {
+	Endpoint string // EndpointChat or EndpointRun
+	Model    string // Model asked by the caller, empty for runs using the assistant's model
+	Size     int    // Size in bytes of the request input, to route large requests differently
+}
+
+// ModelRouter picks the model of chat and run requests, e.g. to send large
+// requests or some tenants to a cheaper model. Tenants, feature flags and other
+// routing inputs can be carried by the context of the call.
+type ModelRouter interface {
+	// RouteModel returns the model to use, or req.Model to keep it. An empty
+	// result for a run keeps the assistant's model.
+	RouteModel(ctx context.Context, req ModelRequest) string
+}
+
+// ModelRouterFunc adapts a function to the ModelRouter interface
+type ModelRouterFunc func(ctx context.Context, req ModelRequest) string
+
+func (f ModelRouterFunc) RouteModel(ctx context.Context, req ModelRequest) string {
+	return f(ctx, req)
+}
+
+// WithModelRouter routes the model of every chat and run request through router
+func WithModelRouter(router ModelRouter) Option {
+	return func(c *Client) {
+		c.modelRouter = router
+	}
+}
+
+// routeModel returns the model picked by the client's router, if any
+func (c *Client) routeModel(ctx context.Context, req ModelRequest) string {
+	if c.modelRouter == nil {
+		return req.Model
+	}
+	return c.modelRouter.RouteModel(ctx, req)
+}
+
+// createChatCompletion sends a chat request through the go-openai client after
+// routing its model
+func (c *Client) createChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	return c.chatCompletion(ctx, req, false)
+}
+
+// createSemanticChatCompletion is createChatCompletion for the helpers whose
+// answers the semantic cache may serve
+func (c *Client) createSemanticChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+	return c.chatCompletion(ctx, req, true)
+}
+
+func (c *Client) chatCompletion(ctx context.Context, req openai.ChatCompletionRequest, semantic bool) (openai.ChatCompletionResponse, error) {
+	req.Model = c.chatModel(ctx, req)
+
+	cacheKey, cacheable := "", false
+	if c.chatCache != nil {
+		cacheKey, cacheable = c.chatCacheKey(ctx, req)
+	}
+	if cacheable {
+		resp, ok, err := c.chatCache.get(ctx, cacheKey)
+		if err != nil {
+			c.logger.WarnContext(ctx, "failed to read chat cache", "error", err)
+		}
+		if ok {
+			return resp, nil
+		}
+	}
+
+	var storeSemantic func(openai.ChatCompletionResponse)
+	if semantic && c.semanticCache != nil {
+		resp, ok, store := c.semanticCached(ctx, req)
+		if ok {
+			return resp, nil
+		}
+		storeSemantic = store
+	}
+
+	resp, err := c.sdkClient().CreateChatCompletion(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	if err := c.checkChatSafety(ctx, resp); err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	if cacheable {
+		if err := c.chatCache.put(ctx, cacheKey, resp); err != nil {
+			c.logger.WarnContext(ctx, "failed to write chat cache", "error", err)
+		}
+	}
+	if storeSemantic != nil {
+		storeSemantic(resp)
+	}
+
+	c.recordUsage(ctx, Usage{
+		Endpoint:         EndpointChat,
+		Model:            resp.Model,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	})
+	return resp, nil
+}
+
+// chatModel returns the model to request for a chat completion, as picked by
+// the router and mapped for the backend
+func (c *Client) chatModel(ctx context.Context, req openai.ChatCompletionRequest) string {
+	if c.modelRouter != nil {
+		size := 0
+		for _, msg := range req.Messages {
+			size += len(msg.Content)
+			for _, part := range msg.MultiContent {
+				size += len(part.Text)
+				if part.ImageURL != nil {
+					size += len(part.ImageURL.URL)
+				}
+			}
+		}
+		req.Model = c.routeModel(ctx, ModelRequest{Endpoint: EndpointChat, Model: req.Model, Size: size})
+	}
+	return c.mapModel(req.Model)
+}
+
+// routeRunModel returns the run params with the model picked by the router
+func (c *Client) routeRunModel(ctx context.Context, params *CreateRunParams) *CreateRunParams {
+	if c.modelRouter == nil || params == nil {
+		return params
+	}
+
+	req := ModelRequest{Endpoint: EndpointRun}
+	if params.Model != nil {
+		req.Model = *params.Model
+	}
+	if params.Instructions != nil {
+		req.Size += len(*params.Instructions)
+	}
+	if params.AdditionalInstructions != nil {
+		req.Size += len(*params.AdditionalInstructions)
+	}
+	for _, msg := range params.AdditionalMessages {
+		req.Size += len(msg.Content)
+	}
+
+	model := c.routeModel(ctx, req)
+	if model == req.Model {
+		return params
+	}
+	routed := *params
+	routed.Model = nil
+	if model != "" {
+		routed.Model = &model
+	}
+	return &routed
+}
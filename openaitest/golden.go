@@ -0,0 +1,156 @@
+// Package openaitest provides utilities for testing code built on the openai package.
+package openaitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// MaskedValue replaces the value of masked fields in normalized payloads
+const MaskedValue = "<masked>"
+
+// DefaultMaskedFields lists the fields whose values change between runs and are
+// masked by default: generated IDs and timestamps.
+var DefaultMaskedFields = []string{
+	"id",
+	"created_at",
+	"expires_at",
+	"started_at",
+	"completed_at",
+	"cancelled_at",
+	"failed_at",
+	"last_active_at",
+}
+
+// Golden compares normalized JSON payloads against golden files. Set the
+// UPDATE_GOLDEN environment variable to 1 to rewrite the golden files with the
+// current payloads.
+type Golden struct {
+	// Dir is the directory holding the golden files, "testdata" by default
+	Dir string
+
+	// Mask lists the field names whose values are replaced by MaskedValue, at any
+	// depth. DefaultMaskedFields is used when nil.
+	Mask []string
+}
+
+// Normalize re-encodes a JSON payload with sorted keys and a stable indentation,
+// and masks the given fields wherever they appear
+func Normalize(payload []byte, mask []string) ([]byte, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %w", err)
+	}
+
+	masked := make(map[string]bool, len(mask))
+	for _, field := range mask {
+		masked[field] = true
+	}
+	v = maskFields(v, masked)
+
+	// encoding/json sorts map keys, which gives the stable field ordering
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+func maskFields(v interface{}, masked map[string]bool) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if masked[key] && value != nil {
+				v[key] = MaskedValue
+			} else {
+				v[key] = maskFields(value, masked)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = maskFields(v[i], masked)
+		}
+	}
+	return v
+}
+
+// Assert marshals v, which may also be raw JSON bytes, normalizes it and compares
+// it with the golden file <Dir>/<name>.golden
+func (g *Golden) Assert(t testing.TB, name string, v interface{}) {
+	t.Helper()
+
+	payload, ok := v.([]byte)
+	if !ok {
+		var err error
+		if payload, err = json.Marshal(v); err != nil {
+			t.Fatalf("golden %s: failed to marshal payload: %v", name, err)
+		}
+	}
+
+	mask := g.Mask
+	if mask == nil {
+		mask = DefaultMaskedFields
+	}
+	got, err := Normalize(payload, mask)
+	if err != nil {
+		t.Fatalf("golden %s: %v", name, err)
+	}
+
+	dir := g.Dir
+	if dir == "" {
+		dir = "testdata"
+	}
+	path := filepath.Join(dir, name+".golden")
+
+	if os.Getenv("UPDATE_GOLDEN") == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("golden %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("golden %s: %v", name, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("golden %s: %v (run with UPDATE_GOLDEN=1 to create it)", name, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("golden %s: payload differs from %s\n%s", name, path, lineDiff(string(want), string(got)))
+	}
+}
+
+// AssertGolden compares v against testdata/<name>.golden with the default masked fields
+func AssertGolden(t testing.TB, name string, v interface{}) {
+	t.Helper()
+	(&Golden{}).Assert(t, name, v)
+}
+
+// lineDiff renders the lines that differ between want and got
+func lineDiff(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	var b strings.Builder
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			fmt.Fprintf(&b, "line %d:\n  - %s\n  + %s\n", i+1, w, g)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,279 @@
+package openaitest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	openai "github.com/bhirbec/go-openai"
+)
+
+// LoadOp is an operation of a load test
+type LoadOp struct {
+	Name   string
+	Weight int // Relative frequency of the operation, 1 when 0
+	Do     func(ctx context.Context) error
+}
+
+// LoadConfig describes a load test
+type LoadConfig struct {
+	QPS         float64       // Operations started per second
+	Duration    time.Duration // Time during which operations are started
+	Concurrency int           // Operations in progress at most, 64 when 0; further starts wait
+	Ops         []LoadOp
+	Seed        uint64 // Seed of the random choice of operations
+}
+
+// LoadStats are the latencies of a set of operations
+type LoadStats struct {
+	Count  int
+	Errors int
+	P50    time.Duration
+	P90    time.Duration
+	P99    time.Duration
+	Max    time.Duration
+}
+
+// LoadReport is the outcome of a load test. Allocations are counted for the
+// whole process, so they include those of a Server running in it.
+type LoadReport struct {
+	Elapsed     time.Duration // Time until the last operation returned
+	QPS         float64       // Operations completed per second
+	Total       LoadStats
+	Ops         map[string]LoadStats
+	AllocsPerOp float64
+	BytesPerOp  float64
+}
+
+// RunLoad starts the operations of config at the configured rate, choosing
+// each one at random by weight, until the duration elapses or ctx is done,
+// then waits for those in progress, e.g. to check that a transport change
+// keeps latencies and allocations in check:
+//
+//	srv := openaitest.NewServer()
+//	defer srv.Close()
+//	client := openai.NewClient("test", openai.WithBaseURL(srv.URL))
+//	ops, err := openaitest.MixedLoadOps(ctx, client)
+//	...
+//	report, err := openaitest.RunLoad(ctx, openaitest.LoadConfig{QPS: 200, Duration: 10 * time.Second, Ops: ops})
+//	report.Write(os.Stdout)
+func RunLoad(ctx context.Context, config LoadConfig) (*LoadReport, error) {
+	if config.QPS <= 0 || config.Duration <= 0 {
+		return nil, errors.New("load test requires a positive QPS and duration")
+	}
+	weights := 0
+	for _, op := range config.Ops {
+		if op.Weight < 0 {
+			return nil, fmt.Errorf("operation %s has a negative weight", op.Name)
+		}
+		weights += max(op.Weight, 1)
+	}
+	if weights == 0 {
+		return nil, errors.New("load test requires operations")
+	}
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = 64
+	}
+
+	rng := rand.New(rand.NewPCG(config.Seed, config.Seed))
+	pick := func() *LoadOp {
+		n := rng.IntN(weights)
+		for i := range config.Ops {
+			if n -= max(config.Ops[i].Weight, 1); n < 0 {
+				return &config.Ops[i]
+			}
+		}
+		return &config.Ops[len(config.Ops)-1]
+	}
+
+	type sample struct {
+		op      string
+		latency time.Duration
+		failed  bool
+	}
+	var (
+		mu      sync.Mutex
+		samples []sample
+		wg      sync.WaitGroup
+	)
+	slots := make(chan struct{}, concurrency)
+	interval := time.Duration(float64(time.Second) / config.QPS)
+
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+schedule:
+	for i := 0; ; i++ {
+		next := start.Add(time.Duration(i) * interval)
+		if next.Sub(start) >= config.Duration {
+			break
+		}
+		timer.Reset(time.Until(next))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			break schedule
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			break schedule
+		}
+
+		op := pick()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			opStart := time.Now()
+			err := op.Do(ctx)
+			s := sample{op: op.Name, latency: time.Since(opStart), failed: err != nil}
+			mu.Lock()
+			samples = append(samples, s)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+
+	report := &LoadReport{Elapsed: elapsed, Ops: map[string]LoadStats{}}
+	all := make([]time.Duration, 0, len(samples))
+	byOp := map[string][]time.Duration{}
+	errorsByOp := map[string]int{}
+	failures := 0
+	for _, s := range samples {
+		all = append(all, s.latency)
+		byOp[s.op] = append(byOp[s.op], s.latency)
+		if s.failed {
+			errorsByOp[s.op]++
+			failures++
+		}
+	}
+	report.Total = loadStats(all, failures)
+	for name, latencies := range byOp {
+		report.Ops[name] = loadStats(latencies, errorsByOp[name])
+	}
+	if n := len(samples); n > 0 {
+		report.QPS = float64(n) / elapsed.Seconds()
+		report.AllocsPerOp = float64(after.Mallocs-before.Mallocs) / float64(n)
+		report.BytesPerOp = float64(after.TotalAlloc-before.TotalAlloc) / float64(n)
+	}
+	return report, ctx.Err()
+}
+
+// loadStats computes the percentiles of latencies, which it sorts
+func loadStats(latencies []time.Duration, errors int) LoadStats {
+	stats := LoadStats{Count: len(latencies), Errors: errors}
+	if len(latencies) == 0 {
+		return stats
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(p float64) time.Duration {
+		return latencies[int(p*float64(len(latencies)-1))]
+	}
+	stats.P50 = percentile(0.50)
+	stats.P90 = percentile(0.90)
+	stats.P99 = percentile(0.99)
+	stats.Max = latencies[len(latencies)-1]
+	return stats
+}
+
+// Write prints the report as a table, one line per operation
+func (r *LoadReport) Write(w io.Writer) error {
+	names := make([]string, 0, len(r.Ops))
+	for name := range r.Ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "op\tcount\terrors\tp50\tp90\tp99\tmax\t")
+	line := func(name string, s LoadStats) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%v\t%v\t%v\t%v\t\n", name, s.Count, s.Errors,
+			s.P50.Round(time.Microsecond), s.P90.Round(time.Microsecond), s.P99.Round(time.Microsecond), s.Max.Round(time.Microsecond))
+	}
+	for _, name := range names {
+		line(name, r.Ops[name])
+	}
+	line("total", r.Total)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%.1f ops/s over %v, %.0f allocs/op, %.0f B/op\n", r.QPS, r.Elapsed.Round(time.Millisecond), r.AllocsPerOp, r.BytesPerOp)
+	return err
+}
+
+// MixedLoadOps creates an assistant, a vector store holding a file and a
+// thread with client, and returns operations exercising them: creating
+// threads, adding messages, running the assistant, listing messages, searching
+// the vector store and retrieving the assistant. Runs are weighted lowest, as
+// each one makes several requests.
+func MixedLoadOps(ctx context.Context, client *openai.Client) ([]LoadOp, error) {
+	fileID, err := client.UploadContent(ctx, "load.md", []byte("# Load\n\nThe fake server scores paragraphs by the words they share with the query.\n\nRuns complete as soon as they are created."))
+	if err != nil {
+		return nil, err
+	}
+	store, err := client.CreateVectorStore(ctx, &openai.CreateVectorStoreParams{Name: "load", FileIDs: []string{fileID}})
+	if err != nil {
+		return nil, err
+	}
+	assistant, err := client.CreateAssistant(ctx, &openai.CreateAssistantParams{Name: "load", Model: "gpt-4o-mini"})
+	if err != nil {
+		return nil, err
+	}
+	thread, err := client.CreateThread(ctx, &openai.CreateThreadParams{})
+	if err != nil {
+		return nil, err
+	}
+
+	return []LoadOp{
+		{Name: "create_thread", Weight: 2, Do: func(ctx context.Context) error {
+			_, err := client.CreateThread(ctx, &openai.CreateThreadParams{})
+			return err
+		}},
+		{Name: "create_message", Weight: 3, Do: func(ctx context.Context) error {
+			_, err := client.CreateMessage(ctx, &openai.CreateMessageParams{ThreadID: thread.ID, Role: "user", Content: "How are runs completed?"})
+			return err
+		}},
+		{Name: "run", Weight: 1, Do: func(ctx context.Context) error {
+			thread, err := client.CreateThread(ctx, &openai.CreateThreadParams{})
+			if err != nil {
+				return err
+			}
+			if _, err := client.CreateMessage(ctx, &openai.CreateMessageParams{ThreadID: thread.ID, Role: "user", Content: "Hello"}); err != nil {
+				return err
+			}
+			run, err := client.CreateRun(ctx, thread.ID, &openai.CreateRunParams{AssistantID: assistant.ID}, nil)
+			if err != nil {
+				return err
+			}
+			_, err = client.WaitForRun(ctx, thread.ID, run.ID, 10*time.Millisecond)
+			return err
+		}},
+		{Name: "list_messages", Weight: 3, Do: func(ctx context.Context) error {
+			_, err := client.ListMessages(ctx, thread.ID, openai.WithLimit(20))
+			return err
+		}},
+		{Name: "search_vector_store", Weight: 2, Do: func(ctx context.Context) error {
+			_, err := client.SearchVectorStore(ctx, store.ID, &openai.SearchVectorStoreParams{Query: "how are paragraphs scored"})
+			return err
+		}},
+		{Name: "retrieve_assistant", Weight: 2, Do: func(ctx context.Context) error {
+			_, err := client.RetrieveAssistant(ctx, assistant.ID)
+			return err
+		}},
+	}, nil
+}
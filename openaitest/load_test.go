@@ -0,0 +1,44 @@
+package openaitest_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	openai "github.com/bhirbec/go-openai"
+	"github.com/bhirbec/go-openai/openaitest"
+)
+
+// BenchmarkMixedLoad drives the mixed operations against the fake server at
+// 200 QPS for a second per iteration, e.g.
+//
+//	go test ./openaitest -run '^$' -bench MixedLoad -benchtime 5x
+func BenchmarkMixedLoad(b *testing.B) {
+	srv := openaitest.NewServer()
+	defer srv.Close()
+	client := openai.NewClient("test", openai.WithBaseURL(srv.URL))
+	ctx := context.Background()
+	ops, err := openaitest.MixedLoadOps(ctx, client)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 0; i < b.N; i++ {
+		report, err := openaitest.RunLoad(ctx, openaitest.LoadConfig{QPS: 200, Duration: time.Second, Ops: ops, Seed: uint64(i)})
+		if err != nil {
+			b.Fatal(err)
+		}
+		if report.Total.Errors > 0 {
+			b.Fatalf("%d operations failed", report.Total.Errors)
+		}
+		b.ReportMetric(float64(report.Total.P50.Microseconds()), "p50-µs")
+		b.ReportMetric(float64(report.Total.P99.Microseconds()), "p99-µs")
+		b.ReportMetric(report.AllocsPerOp, "allocs/load-op")
+		if i == b.N-1 {
+			var out strings.Builder
+			report.Write(&out)
+			b.Log("\n" + out.String())
+		}
+	}
+}
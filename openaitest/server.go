@@ -0,0 +1,978 @@
+package openaitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is an in-memory fake of the OpenAI API for integration tests. It
+// implements the assistants, threads, messages, runs, files, vector stores,
+// vector store files, vector store search and models endpoints, enough to run
+// the RAG flows of the openai package without hitting the real API:
+//
+//	srv := openaitest.NewServer()
+//	defer srv.Close()
+//	client := openai.NewClient("test", openai.WithBaseURL(srv.URL))
+//
+// Runs complete as soon as they are created, adding the message returned by
+// Reply to their thread. Vector store files are processed instantly, and
+// searches score the paragraphs of the attached files by the words they share
+// with the query.
+type Server struct {
+	*httptest.Server
+
+	// Reply returns the content of the assistant message added by a run, given
+	// the text of the thread messages in order. The fake echoes the last
+	// message when nil.
+	Reply func(messages []string) string
+
+	// Now returns the time used for timestamps, time.Now when nil
+	Now func() time.Time
+
+	mu           sync.Mutex
+	ids          map[string]int
+	assistants   *collection
+	threads      *collection
+	files        *collection
+	vectorStores *collection
+	messages     map[string]*collection // By thread
+	runs         map[string]*collection // By thread
+	storeFiles   map[string]*collection // By vector store
+	contents     map[string][]byte      // File contents by file ID
+}
+
+// object is a resource as encoded by the API
+type object = map[string]interface{}
+
+// collection holds resources in creation order
+type collection struct {
+	order []string
+	items map[string]object
+}
+
+func newCollection() *collection {
+	return &collection{items: map[string]object{}}
+}
+
+func (c *collection) add(id string, obj object) {
+	c.order = append(c.order, id)
+	c.items[id] = obj
+}
+
+func (c *collection) remove(id string) {
+	delete(c.items, id)
+	for i, v := range c.order {
+		if v == id {
+			c.order = append(c.order[:i], c.order[i+1:]...)
+			break
+		}
+	}
+}
+
+func (c *collection) list() []object {
+	out := make([]object, 0, len(c.order))
+	for _, id := range c.order {
+		out = append(out, c.items[id])
+	}
+	return out
+}
+
+// NewServer starts a fake API server. Close it when done.
+func NewServer() *Server {
+	s := &Server{
+		ids:          map[string]int{},
+		assistants:   newCollection(),
+		threads:      newCollection(),
+		files:        newCollection(),
+		vectorStores: newCollection(),
+		messages:     map[string]*collection{},
+		runs:         map[string]*collection{},
+		storeFiles:   map[string]*collection{},
+		contents:     map[string][]byte{},
+	}
+
+	mux := http.NewServeMux()
+	handle := func(pattern string, h func(w http.ResponseWriter, r *http.Request)) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			h(w, r)
+		})
+	}
+
+	handle("GET /models", s.listModels)
+
+	handle("POST /assistants", s.createAssistant)
+	handle("GET /assistants", s.listAssistants)
+	handle("GET /assistants/{id}", s.retrieve(s.assistants, "assistant"))
+	handle("POST /assistants/{id}", s.modify(s.assistants, "assistant", "name", "description", "model", "instructions", "tools", "tool_resources", "metadata", "temperature", "top_p", "response_format"))
+	handle("DELETE /assistants/{id}", s.deleteResource(s.assistants, "assistant", "assistant.deleted"))
+
+	handle("POST /threads", s.createThread)
+	handle("GET /threads/{id}", s.retrieve(s.threads, "thread"))
+	handle("POST /threads/{id}", s.modify(s.threads, "thread", "metadata", "tool_resources"))
+	handle("DELETE /threads/{id}", s.deleteThread)
+	handle("POST /threads/{id}/messages", s.createMessage)
+	handle("GET /threads/{id}/messages", s.listMessages)
+	handle("GET /threads/{id}/messages/{messageID}", s.retrieveMessage)
+	handle("POST /threads/{id}/runs", s.createRun)
+	handle("GET /threads/{id}/runs", s.listRuns)
+	handle("GET /threads/{id}/runs/{runID}", s.retrieveRun)
+	handle("GET /threads/{id}/runs/{runID}/steps", s.listRunSteps)
+
+	handle("POST /files", s.uploadFile)
+	handle("GET /files", s.listFiles)
+	handle("GET /files/{id}", s.retrieve(s.files, "file"))
+	handle("GET /files/{id}/content", s.fileContent)
+	handle("DELETE /files/{id}", s.deleteFile)
+
+	handle("POST /vector_stores", s.createVectorStore)
+	handle("GET /vector_stores", s.listVectorStores)
+	handle("GET /vector_stores/{id}", s.retrieve(s.vectorStores, "vector store"))
+	handle("POST /vector_stores/{id}", s.modify(s.vectorStores, "vector store", "name", "metadata", "expires_after"))
+	handle("DELETE /vector_stores/{id}", s.deleteVectorStore)
+	handle("POST /vector_stores/{id}/files", s.createVectorStoreFile)
+	handle("GET /vector_stores/{id}/files", s.listVectorStoreFiles)
+	handle("GET /vector_stores/{id}/files/{fileID}", s.retrieveVectorStoreFile)
+	handle("DELETE /vector_stores/{id}/files/{fileID}", s.deleteVectorStoreFile)
+	handle("POST /vector_stores/{id}/search", s.searchVectorStore)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("Invalid URL (%s %s)", r.Method, r.URL.Path), "")
+	})
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// FileContent returns the content of an uploaded file
+func (s *Server) FileContent(fileID string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.contents[fileID]
+	return content, ok
+}
+
+func (s *Server) now() int64 {
+	if s.Now != nil {
+		return s.Now().Unix()
+	}
+	return time.Now().Unix()
+}
+
+// newID returns sequential IDs per prefix, e.g. "asst_1", "asst_2"
+func (s *Server) newID(prefix string) string {
+	s.ids[prefix]++
+	sep := "_"
+	if prefix == "file" {
+		sep = "-"
+	}
+	return prefix + sep + strconv.Itoa(s.ids[prefix])
+}
+
+func (s *Server) listModels(w http.ResponseWriter, r *http.Request) {
+	var models []object
+	for _, id := range []string{"gpt-4o", "gpt-4o-mini", "text-embedding-3-small", "text-embedding-ada-002"} {
+		models = append(models, object{"id": id, "object": "model", "created": 0, "owned_by": "openai"})
+	}
+	writeJSON(w, http.StatusOK, object{"object": "list", "data": models})
+}
+
+func (s *Server) createAssistant(w http.ResponseWriter, r *http.Request) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	if model, _ := body["model"].(string); model == "" {
+		writeError(w, http.StatusBadRequest, "Missing required parameter: 'model'.", "")
+		return
+	}
+
+	id := s.newID("asst")
+	assistant := object{
+		"id":              id,
+		"object":          "assistant",
+		"created_at":      s.now(),
+		"name":            nil,
+		"description":     nil,
+		"instructions":    nil,
+		"tools":           []interface{}{},
+		"tool_resources":  object{},
+		"metadata":        object{},
+		"temperature":     1.0,
+		"top_p":           1.0,
+		"response_format": "auto",
+	}
+	merge(assistant, body, "name", "description", "model", "instructions", "tools", "tool_resources", "metadata", "temperature", "top_p", "response_format")
+	s.assistants.add(id, assistant)
+	writeJSON(w, http.StatusOK, assistant)
+}
+
+func (s *Server) listAssistants(w http.ResponseWriter, r *http.Request) {
+	writeList(w, r, s.assistants.list())
+}
+
+func (s *Server) createThread(w http.ResponseWriter, r *http.Request) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+
+	id := s.newID("thread")
+	thread := object{
+		"id":             id,
+		"object":         "thread",
+		"created_at":     s.now(),
+		"metadata":       object{},
+		"tool_resources": object{},
+	}
+	merge(thread, body, "metadata", "tool_resources")
+	s.threads.add(id, thread)
+	s.messages[id] = newCollection()
+	s.runs[id] = newCollection()
+
+	messages, _ := body["messages"].([]interface{})
+	for _, m := range messages {
+		if params, ok := m.(object); ok {
+			s.addMessage(id, params, "")
+		}
+	}
+	writeJSON(w, http.StatusOK, thread)
+}
+
+func (s *Server) deleteThread(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.threads.items[id]; !ok {
+		writeNotFound(w, "thread", id)
+		return
+	}
+	s.threads.remove(id)
+	delete(s.messages, id)
+	delete(s.runs, id)
+	writeJSON(w, http.StatusOK, object{"id": id, "object": "thread.deleted", "deleted": true})
+}
+
+// addMessage adds a message to a thread, normalizing its content to the list
+// of content parts returned by the API
+func (s *Server) addMessage(threadID string, params object, runID string) object {
+	id := s.newID("msg")
+	role, _ := params["role"].(string)
+	message := object{
+		"id":           id,
+		"object":       "thread.message",
+		"created_at":   s.now(),
+		"thread_id":    threadID,
+		"status":       "completed",
+		"role":         role,
+		"content":      messageContent(params["content"]),
+		"assistant_id": nil,
+		"run_id":       nil,
+		"attachments":  []interface{}{},
+		"metadata":     object{},
+	}
+	if runID != "" {
+		message["run_id"] = runID
+	}
+	merge(message, params, "assistant_id", "attachments", "metadata")
+	s.messages[threadID].add(id, message)
+	return message
+}
+
+func messageContent(content interface{}) []interface{} {
+	text := func(value string) object {
+		return object{"type": "text", "text": object{"value": value, "annotations": []interface{}{}}}
+	}
+
+	switch content := content.(type) {
+	case string:
+		return []interface{}{text(content)}
+	case []interface{}:
+		parts := make([]interface{}, 0, len(content))
+		for _, part := range content {
+			p, ok := part.(object)
+			if !ok {
+				continue
+			}
+			if value, ok := p["text"].(string); ok && p["type"] == "text" {
+				parts = append(parts, text(value))
+			} else {
+				parts = append(parts, p)
+			}
+		}
+		return parts
+	}
+	return []interface{}{}
+}
+
+// messageText concatenates the text parts of a message
+func messageText(message object) string {
+	var parts []string
+	content, _ := message["content"].([]interface{})
+	for _, part := range content {
+		if p, ok := part.(object); ok {
+			if text, ok := p["text"].(object); ok {
+				value, _ := text["value"].(string)
+				parts = append(parts, value)
+			}
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func (s *Server) thread(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	if _, ok := s.threads.items[id]; !ok {
+		writeNotFound(w, "thread", id)
+		return "", false
+	}
+	return id, true
+}
+
+func (s *Server) createMessage(w http.ResponseWriter, r *http.Request) {
+	threadID, ok := s.thread(w, r)
+	if !ok {
+		return
+	}
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	if role, _ := body["role"].(string); role != "user" && role != "assistant" {
+		writeError(w, http.StatusBadRequest, "Invalid value for 'role': expected 'user' or 'assistant'.", "")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.addMessage(threadID, body, ""))
+}
+
+func (s *Server) listMessages(w http.ResponseWriter, r *http.Request) {
+	threadID, ok := s.thread(w, r)
+	if !ok {
+		return
+	}
+	messages := s.messages[threadID].list()
+	if runID := r.URL.Query().Get("run_id"); runID != "" {
+		filtered := messages[:0:0]
+		for _, m := range messages {
+			if m["run_id"] == runID {
+				filtered = append(filtered, m)
+			}
+		}
+		messages = filtered
+	}
+	writeList(w, r, messages)
+}
+
+func (s *Server) retrieveMessage(w http.ResponseWriter, r *http.Request) {
+	threadID, ok := s.thread(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("messageID")
+	message, ok := s.messages[threadID].items[id]
+	if !ok {
+		writeNotFound(w, "message", id)
+		return
+	}
+	writeJSON(w, http.StatusOK, message)
+}
+
+func (s *Server) createRun(w http.ResponseWriter, r *http.Request) {
+	threadID, ok := s.thread(w, r)
+	if !ok {
+		return
+	}
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	assistantID, _ := body["assistant_id"].(string)
+	assistant, ok := s.assistants.items[assistantID]
+	if !ok {
+		writeNotFound(w, "assistant", assistantID)
+		return
+	}
+
+	additional, _ := body["additional_messages"].([]interface{})
+	for _, m := range additional {
+		if params, ok := m.(object); ok {
+			s.addMessage(threadID, params, "")
+		}
+	}
+
+	id := s.newID("run")
+	now := s.now()
+	run := object{
+		"id":              id,
+		"object":          "thread.run",
+		"created_at":      now,
+		"thread_id":       threadID,
+		"assistant_id":    assistantID,
+		"status":          "completed",
+		"started_at":      now,
+		"completed_at":    now,
+		"model":           assistant["model"],
+		"instructions":    assistant["instructions"],
+		"tools":           assistant["tools"],
+		"metadata":        object{},
+		"temperature":     assistant["temperature"],
+		"top_p":           assistant["top_p"],
+		"last_error":      nil,
+		"required_action": nil,
+	}
+	merge(run, body, "model", "instructions", "tools", "metadata", "temperature", "top_p")
+
+	var texts []string
+	prompt := 0
+	for _, m := range s.messages[threadID].list() {
+		text := messageText(m)
+		texts = append(texts, text)
+		prompt += len(strings.Fields(text))
+	}
+	reply := s.reply(texts)
+	message := s.addMessage(threadID, object{"role": "assistant", "content": reply, "assistant_id": assistantID}, id)
+	completion := len(strings.Fields(reply))
+	run["usage"] = object{"prompt_tokens": prompt, "completion_tokens": completion, "total_tokens": prompt + completion}
+	run["message_id"] = message["id"]
+
+	s.runs[threadID].add(id, run)
+	writeJSON(w, http.StatusOK, publicRun(run))
+}
+
+func (s *Server) reply(messages []string) string {
+	if s.Reply != nil {
+		return s.Reply(messages)
+	}
+	if len(messages) == 0 {
+		return ""
+	}
+	return messages[len(messages)-1]
+}
+
+// publicRun returns the run without the fields kept for the fake's own use
+func publicRun(run object) object {
+	out := make(object, len(run))
+	for k, v := range run {
+		if k != "message_id" {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func (s *Server) listRuns(w http.ResponseWriter, r *http.Request) {
+	threadID, ok := s.thread(w, r)
+	if !ok {
+		return
+	}
+	var runs []object
+	for _, run := range s.runs[threadID].list() {
+		runs = append(runs, publicRun(run))
+	}
+	writeList(w, r, runs)
+}
+
+func (s *Server) run(w http.ResponseWriter, r *http.Request) (object, bool) {
+	threadID, ok := s.thread(w, r)
+	if !ok {
+		return nil, false
+	}
+	id := r.PathValue("runID")
+	run, ok := s.runs[threadID].items[id]
+	if !ok {
+		writeNotFound(w, "run", id)
+		return nil, false
+	}
+	return run, true
+}
+
+func (s *Server) retrieveRun(w http.ResponseWriter, r *http.Request) {
+	if run, ok := s.run(w, r); ok {
+		writeJSON(w, http.StatusOK, publicRun(run))
+	}
+}
+
+func (s *Server) listRunSteps(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.run(w, r)
+	if !ok {
+		return
+	}
+	step := object{
+		"id":           strings.Replace(run["id"].(string), "run_", "step_", 1),
+		"object":       "thread.run.step",
+		"created_at":   run["created_at"],
+		"run_id":       run["id"],
+		"assistant_id": run["assistant_id"],
+		"thread_id":    run["thread_id"],
+		"type":         "message_creation",
+		"status":       "completed",
+		"step_details": object{
+			"type":             "message_creation",
+			"message_creation": object{"message_id": run["message_id"]},
+		},
+		"usage": run["usage"],
+	}
+	writeList(w, r, []object{step})
+}
+
+func (s *Server) uploadFile(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid multipart body: "+err.Error(), "")
+		return
+	}
+	purpose := r.FormValue("purpose")
+	if purpose == "" {
+		writeError(w, http.StatusBadRequest, "Missing required parameter: 'purpose'.", "")
+		return
+	}
+	f, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Missing required parameter: 'file'.", "")
+		return
+	}
+	defer f.Close()
+	content, err := io.ReadAll(f)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to read file: "+err.Error(), "")
+		return
+	}
+
+	id := s.newID("file")
+	file := object{
+		"id":         id,
+		"object":     "file",
+		"bytes":      len(content),
+		"created_at": s.now(),
+		"filename":   header.Filename,
+		"purpose":    purpose,
+	}
+	s.files.add(id, file)
+	s.contents[id] = content
+	writeJSON(w, http.StatusOK, file)
+}
+
+func (s *Server) listFiles(w http.ResponseWriter, r *http.Request) {
+	writeList(w, r, s.files.list())
+}
+
+func (s *Server) fileContent(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	content, ok := s.contents[id]
+	if !ok {
+		writeNotFound(w, "file", id)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Write(content)
+}
+
+func (s *Server) deleteFile(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.files.items[id]; !ok {
+		writeNotFound(w, "file", id)
+		return
+	}
+	s.files.remove(id)
+	delete(s.contents, id)
+	writeJSON(w, http.StatusOK, object{"id": id, "object": "file", "deleted": true})
+}
+
+func (s *Server) createVectorStore(w http.ResponseWriter, r *http.Request) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+
+	id := s.newID("vs")
+	store := object{
+		"id":             id,
+		"object":         "vector_store",
+		"created_at":     s.now(),
+		"name":           "",
+		"status":         "completed",
+		"metadata":       object{},
+		"expires_after":  nil,
+		"last_active_at": s.now(),
+	}
+	merge(store, body, "name", "metadata", "expires_after")
+	s.vectorStores.add(id, store)
+	s.storeFiles[id] = newCollection()
+
+	fileIDs, _ := body["file_ids"].([]interface{})
+	for _, fileID := range fileIDs {
+		if fileID, ok := fileID.(string); ok {
+			if _, ok := s.attachFile(id, fileID, nil); !ok {
+				s.vectorStores.remove(id)
+				delete(s.storeFiles, id)
+				writeNotFound(w, "file", fileID)
+				return
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, s.refreshVectorStore(id))
+}
+
+// refreshVectorStore updates the usage and file counts of a vector store
+func (s *Server) refreshVectorStore(id string) object {
+	store := s.vectorStores.items[id]
+	usage := 0
+	counts := map[string]int{"in_progress": 0, "completed": 0, "failed": 0, "cancelled": 0, "total": 0}
+	for _, f := range s.storeFiles[id].list() {
+		usage += f["usage_bytes"].(int)
+		counts[f["status"].(string)]++
+		counts["total"]++
+	}
+	store["usage_bytes"] = usage
+	store["file_counts"] = counts
+	return store
+}
+
+func (s *Server) listVectorStores(w http.ResponseWriter, r *http.Request) {
+	writeList(w, r, s.vectorStores.list())
+}
+
+func (s *Server) deleteVectorStore(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.vectorStores.items[id]; !ok {
+		writeNotFound(w, "vector store", id)
+		return
+	}
+	s.vectorStores.remove(id)
+	delete(s.storeFiles, id)
+	writeJSON(w, http.StatusOK, object{"id": id, "object": "vector_store.deleted", "deleted": true})
+}
+
+func (s *Server) vectorStore(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	if _, ok := s.vectorStores.items[id]; !ok {
+		writeNotFound(w, "vector store", id)
+		return "", false
+	}
+	return id, true
+}
+
+// attachFile attaches an uploaded file to a vector store. Files are processed
+// instantly.
+func (s *Server) attachFile(storeID, fileID string, attributes interface{}) (object, bool) {
+	content, ok := s.contents[fileID]
+	if !ok {
+		return nil, false
+	}
+	if attributes == nil {
+		attributes = object{}
+	}
+	file := object{
+		"id":                fileID,
+		"object":            "vector_store.file",
+		"created_at":        s.now(),
+		"vector_store_id":   storeID,
+		"status":            "completed",
+		"usage_bytes":       len(content),
+		"last_error":        nil,
+		"attributes":        attributes,
+		"chunking_strategy": object{"type": "static", "static": object{"max_chunk_size_tokens": 800, "chunk_overlap_tokens": 400}},
+	}
+	files := s.storeFiles[storeID]
+	if _, ok := files.items[fileID]; ok {
+		files.remove(fileID)
+	}
+	files.add(fileID, file)
+	return file, true
+}
+
+func (s *Server) createVectorStoreFile(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := s.vectorStore(w, r)
+	if !ok {
+		return
+	}
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	fileID, _ := body["file_id"].(string)
+	file, ok := s.attachFile(storeID, fileID, body["attributes"])
+	if !ok {
+		writeNotFound(w, "file", fileID)
+		return
+	}
+	s.refreshVectorStore(storeID)
+	writeJSON(w, http.StatusOK, file)
+}
+
+func (s *Server) listVectorStoreFiles(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := s.vectorStore(w, r)
+	if !ok {
+		return
+	}
+	files := s.storeFiles[storeID].list()
+	if status := r.URL.Query().Get("filter"); status != "" {
+		filtered := files[:0:0]
+		for _, f := range files {
+			if f["status"] == status {
+				filtered = append(filtered, f)
+			}
+		}
+		files = filtered
+	}
+	writeList(w, r, files)
+}
+
+func (s *Server) retrieveVectorStoreFile(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := s.vectorStore(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("fileID")
+	file, ok := s.storeFiles[storeID].items[id]
+	if !ok {
+		writeNotFound(w, "vector store file", id)
+		return
+	}
+	writeJSON(w, http.StatusOK, file)
+}
+
+func (s *Server) deleteVectorStoreFile(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := s.vectorStore(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("fileID")
+	if _, ok := s.storeFiles[storeID].items[id]; !ok {
+		writeNotFound(w, "vector store file", id)
+		return
+	}
+	s.storeFiles[storeID].remove(id)
+	s.refreshVectorStore(storeID)
+	writeJSON(w, http.StatusOK, object{"id": id, "object": "vector_store.file.deleted", "deleted": true})
+}
+
+func (s *Server) searchVectorStore(w http.ResponseWriter, r *http.Request) {
+	storeID, ok := s.vectorStore(w, r)
+	if !ok {
+		return
+	}
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	query, _ := body["query"].(string)
+	maxResults := 10
+	if n, ok := body["max_num_results"].(float64); ok && n > 0 {
+		maxResults = int(n)
+	}
+
+	words := map[string]bool{}
+	for _, word := range searchWords(query) {
+		words[word] = true
+	}
+
+	var results []object
+	for _, f := range s.storeFiles[storeID].list() {
+		fileID := f["id"].(string)
+		filename := ""
+		if file, ok := s.files.items[fileID]; ok {
+			filename, _ = file["filename"].(string)
+		}
+		for _, chunk := range strings.Split(string(s.contents[fileID]), "\n\n") {
+			chunk = strings.TrimSpace(chunk)
+			chunkWords := searchWords(chunk)
+			if len(chunkWords) == 0 || len(words) == 0 {
+				continue
+			}
+			matched := map[string]bool{}
+			for _, word := range chunkWords {
+				if words[word] {
+					matched[word] = true
+				}
+			}
+			if len(matched) == 0 {
+				continue
+			}
+			results = append(results, object{
+				"file_id":    fileID,
+				"filename":   filename,
+				"score":      float64(len(matched)) / float64(len(words)),
+				"attributes": f["attributes"],
+				"content":    []interface{}{object{"type": "text", "text": chunk}},
+			})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i]["score"].(float64) > results[j]["score"].(float64)
+	})
+	if len(results) > maxResults {
+		results = results[:maxResults]
+	}
+	if results == nil {
+		results = []object{}
+	}
+
+	writeJSON(w, http.StatusOK, object{
+		"object":       "vector_store.search_results.page",
+		"search_query": query,
+		"data":         results,
+		"has_more":     false,
+		"next_page":    nil,
+	})
+}
+
+// searchWords splits text into lowercase words
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r > 127)
+	})
+}
+
+// retrieve returns a handler writing the resource named by the id path value
+func (s *Server) retrieve(c *collection, kind string) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		obj, ok := c.items[id]
+		if !ok {
+			writeNotFound(w, kind, id)
+			return
+		}
+		if c == s.vectorStores {
+			obj = s.refreshVectorStore(id)
+		}
+		writeJSON(w, http.StatusOK, obj)
+	}
+}
+
+// modify returns a handler updating the given fields of a resource
+func (s *Server) modify(c *collection, kind string, fields ...string) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		obj, ok := c.items[id]
+		if !ok {
+			writeNotFound(w, kind, id)
+			return
+		}
+		body, ok := readBody(w, r)
+		if !ok {
+			return
+		}
+		merge(obj, body, fields...)
+		if c == s.vectorStores {
+			obj = s.refreshVectorStore(id)
+		}
+		writeJSON(w, http.StatusOK, obj)
+	}
+}
+
+// deleteResource returns a handler deleting a resource
+func (s *Server) deleteResource(c *collection, kind, deletedObject string) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if _, ok := c.items[id]; !ok {
+			writeNotFound(w, kind, id)
+			return
+		}
+		c.remove(id)
+		writeJSON(w, http.StatusOK, object{"id": id, "object": deletedObject, "deleted": true})
+	}
+}
+
+// merge copies the given fields of src into dst, when present
+func merge(dst, src object, fields ...string) {
+	for _, field := range fields {
+		if v, ok := src[field]; ok {
+			dst[field] = v
+		}
+	}
+}
+
+func readBody(w http.ResponseWriter, r *http.Request) (object, bool) {
+	body := object{}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Failed to read body: "+err.Error(), "")
+		return nil, false
+	}
+	if len(data) == 0 {
+		return body, true
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		writeError(w, http.StatusBadRequest, "We could not parse the JSON body of your request.", "")
+		return nil, false
+	}
+	return body, true
+}
+
+// writeList writes a page of objects, honoring the limit, order, after and
+// before query parameters. Objects are given in creation order.
+func writeList(w http.ResponseWriter, r *http.Request, objects []object) {
+	q := r.URL.Query()
+	limit := 20
+	if n, err := strconv.Atoi(q.Get("limit")); err == nil && n > 0 {
+		limit = min(n, 100)
+	}
+
+	items := append([]object(nil), objects...)
+	if q.Get("order") != "asc" {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	if after := q.Get("after"); after != "" {
+		for i, item := range items {
+			if item["id"] == after {
+				items = items[i+1:]
+				break
+			}
+		}
+	}
+	if before := q.Get("before"); before != "" {
+		for i, item := range items {
+			if item["id"] == before {
+				items = items[:i]
+				break
+			}
+		}
+		if len(items) > limit {
+			items = items[len(items)-limit:]
+		}
+	}
+
+	hasMore := len(items) > limit
+	if hasMore {
+		items = items[:limit]
+	}
+	page := object{"object": "list", "data": items, "has_more": hasMore, "first_id": nil, "last_id": nil}
+	if len(items) > 0 {
+		page["first_id"] = items[0]["id"]
+		page["last_id"] = items[len(items)-1]["id"]
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an OpenAI error envelope
+func writeError(w http.ResponseWriter, status int, message, code string) {
+	var c interface{}
+	if code != "" {
+		c = code
+	}
+	writeJSON(w, status, object{"error": object{
+		"message": message,
+		"type":    "invalid_request_error",
+		"param":   nil,
+		"code":    c,
+	}})
+}
+
+func writeNotFound(w http.ResponseWriter, kind, id string) {
+	writeError(w, http.StatusNotFound, fmt.Sprintf("No %s found with id '%s'.", kind, id), "")
+}
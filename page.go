@@ -0,0 +1,165 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// Page is a page of a list endpoint. FirstID and LastID are the cursors to
+// pass to WithBefore and WithAfter to get the neighbouring pages.
+type Page[T any] struct {
+	Data    []T    `json:"data"`
+	FirstID string `json:"first_id"`
+	LastID  string `json:"last_id"`
+	HasMore bool   `json:"has_more"`
+}
+
+// ListVectorStoresPage returns a page of vector stores with its cursors
+func (c *Client) ListVectorStoresPage(ctx context.Context, opts ...ListOption) (*Page[VectorStore], error) {
+	page, err := listPage[VectorStore](ctx, c, "/vector_stores", opts)
+	if err != nil {
+		return nil, fmt.Errorf("list vector stores failed: %w", err)
+	}
+	return page, nil
+}
+
+// ListVectorStoreFilesPage returns a page of the files of a vector store with
+// its cursors
+func (c *Client) ListVectorStoreFilesPage(ctx context.Context, vectorStoreID string, opts ...ListOption) (*Page[VectorStoreFile], error) {
+	page, err := listPage[VectorStoreFile](ctx, c, "/vector_stores/"+vectorStoreID+"/files", opts)
+	if err != nil {
+		return nil, fmt.Errorf("list vector store files failed: %w", err)
+	}
+	return page, nil
+}
+
+// ListFilesPage returns a page of files with its cursors
+func (c *Client) ListFilesPage(ctx context.Context, opts ...ListOption) (*Page[File], error) {
+	page, err := listPage[File](ctx, c, "/files", opts)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving files failed: %w", err)
+	}
+	return page, nil
+}
+
+// ListAssistantsPage returns a page of assistants with its cursors
+func (c *Client) ListAssistantsPage(ctx context.Context, opts ...ListOption) (*Page[Assistant], error) {
+	page, err := listPage[Assistant](ctx, c, "/assistants", opts)
+	if err != nil {
+		return nil, fmt.Errorf("retrieving assistants failed: %w", err)
+	}
+	return page, nil
+}
+
+// ListMessagesPage returns a page of the messages of a thread with its cursors
+func (c *Client) ListMessagesPage(ctx context.Context, threadID string, opts ...ListOption) (*Page[Message], error) {
+	page, err := listPage[Message](ctx, c, "/threads/"+threadID+"/messages", opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list messages: %w", err)
+	}
+	return page, nil
+}
+
+// ListRunsPage returns a page of the runs of a thread with its cursors
+func (c *Client) ListRunsPage(ctx context.Context, threadID string, opts ...ListOption) (*Page[Run], error) {
+	page, err := listPage[Run](ctx, c, "/threads/"+threadID+"/runs", opts)
+	if err != nil {
+		return nil, fmt.Errorf("run listing failed: %w", err)
+	}
+	return page, nil
+}
+
+// AllVectorStores iterates over the vector stores, fetching the pages as the
+// loop advances, e.g.
+//
+//	for store, err := range client.AllVectorStores(ctx, WithOrder("asc")) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+//
+// WithLimit sets the size of the pages. The iteration stops after the first
+// error.
+func (c *Client) AllVectorStores(ctx context.Context, opts ...ListOption) iter.Seq2[VectorStore, error] {
+	return paginate(opts, func(opts []ListOption) (*Page[VectorStore], error) {
+		return c.ListVectorStoresPage(ctx, opts...)
+	})
+}
+
+// AllVectorStoreFiles iterates over the files of a vector store, like
+// AllVectorStores
+func (c *Client) AllVectorStoreFiles(ctx context.Context, vectorStoreID string, opts ...ListOption) iter.Seq2[VectorStoreFile, error] {
+	return paginate(opts, func(opts []ListOption) (*Page[VectorStoreFile], error) {
+		return c.ListVectorStoreFilesPage(ctx, vectorStoreID, opts...)
+	})
+}
+
+// AllFiles iterates over the files, like AllVectorStores
+func (c *Client) AllFiles(ctx context.Context, opts ...ListOption) iter.Seq2[File, error] {
+	return paginate(opts, func(opts []ListOption) (*Page[File], error) {
+		return c.ListFilesPage(ctx, opts...)
+	})
+}
+
+// AllAssistants iterates over the assistants, like AllVectorStores
+func (c *Client) AllAssistants(ctx context.Context, opts ...ListOption) iter.Seq2[Assistant, error] {
+	return paginate(opts, func(opts []ListOption) (*Page[Assistant], error) {
+		return c.ListAssistantsPage(ctx, opts...)
+	})
+}
+
+// AllMessages iterates over the messages of a thread, like AllVectorStores
+func (c *Client) AllMessages(ctx context.Context, threadID string, opts ...ListOption) iter.Seq2[Message, error] {
+	return paginate(opts, func(opts []ListOption) (*Page[Message], error) {
+		return c.ListMessagesPage(ctx, threadID, opts...)
+	})
+}
+
+// AllRuns iterates over the runs of a thread, like AllVectorStores
+func (c *Client) AllRuns(ctx context.Context, threadID string, opts ...ListOption) iter.Seq2[Run, error] {
+	return paginate(opts, func(opts []ListOption) (*Page[Run], error) {
+		return c.ListRunsPage(ctx, threadID, opts...)
+	})
+}
+
+// listPage fetches a page of the list endpoint at path
+func listPage[T any](ctx context.Context, c *Client, path string, opts []ListOption) (*Page[T], error) {
+	var page Page[T]
+	if err := c.do(ctx, "GET", listPath(path, opts), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// paginate yields the items of the pages returned by fetch, requesting the
+// page after the last one until the API reports no more items. The cursor is
+// appended to opts so it overrides a WithAfter of the caller.
+func paginate[T any](opts []ListOption, fetch func(opts []ListOption) (*Page[T], error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		cursor := ""
+		for {
+			pageOpts := opts
+			if cursor != "" {
+				pageOpts = append(opts[:len(opts):len(opts)], WithAfter(cursor))
+			}
+
+			page, err := fetch(pageOpts)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range page.Data {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if !page.HasMore || page.LastID == "" || page.LastID == cursor {
+				return
+			}
+			cursor = page.LastID
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// PingResult describes the API reached by Ping
+type PingResult struct {
+	BaseURL      string
+	Organization string        // Organization of the key, as reported by the API
+	Project      string        // Project set with WithHeader("OpenAI-Project", ...), if any
+	Latency      time.Duration // Round trip of the models request
+	Models       []string      // IDs of the models available to the key, sorted
+}
+
+// Ping checks connectivity and credentials by listing the available models,
+// e.g. for readiness probes. It fails with an APIError when the key is rejected.
+func (c *Client) Ping(ctx context.Context) (*PingResult, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ping request: %w", err)
+	}
+
+	start := c.clock.Now()
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, fmt.Errorf("ping request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ping failed: %w", c.responseError(resp))
+	}
+
+	var models struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&models); err != nil {
+		return nil, fmt.Errorf("failed to decode models response: %w", err)
+	}
+
+	result := &PingResult{
+		BaseURL:      c.baseURL,
+		Organization: resp.Header.Get("Openai-Organization"),
+		Project:      c.headers.Get("OpenAI-Project"),
+		Latency:      c.clock.Now().Sub(start),
+	}
+	for _, model := range models.Data {
+		result.Models = append(result.Models, model.ID)
+	}
+	sort.Strings(result.Models)
+	return result, nil
+}
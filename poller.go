@@ -0,0 +1,218 @@
+package openai
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Poller waits for many runs and vector store files at once. Waiters of the
+// same resource share its polls, and each type of resource is polled by a
+// single loop issuing at most maxPolls requests per interval, so a service
+// watching hundreds of runs makes a bounded number of requests instead of
+// running one poll loop per run. It is safe for concurrent use.
+//
+// Polls are made on behalf of all the waiters, so they do not carry the
+// request options of the waiters' contexts.
+type Poller struct {
+	client   *Client
+	interval time.Duration
+	maxPolls int
+
+	runs  *pollGroup[*Run]
+	files *pollGroup[*VectorStoreFile]
+}
+
+// NewPoller returns a poller checking the resources waited for every interval,
+// 1s when 0. maxPolls caps the requests per interval, 0 for no limit; when
+// more resources are waited for, they are polled in turn.
+func NewPoller(client *Client, interval time.Duration, maxPolls int) *Poller {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	p := &Poller{client: client, interval: interval, maxPolls: maxPolls}
+	p.runs = newPollGroup(p, p.pollRun)
+	p.files = newPollGroup(p, p.pollVectorStoreFile)
+	return p
+}
+
+// WaitForRun waits until the run reaches a terminal status, like
+// Client.WaitForRun
+func (p *Poller) WaitForRun(ctx context.Context, threadID, runID string) (*Run, error) {
+	return p.runs.wait(ctx, pollKey{threadID, runID})
+}
+
+// WaitForVectorStoreFile waits until the file is processed by the vector
+// store, successfully or not
+func (p *Poller) WaitForVectorStoreFile(ctx context.Context, vectorStoreID, fileID string) (*VectorStoreFile, error) {
+	return p.files.wait(ctx, pollKey{vectorStoreID, fileID})
+}
+
+func (p *Poller) pollRun(ctx context.Context, key pollKey) (*Run, bool, error) {
+	run, err := p.client.RetrieveRun(ctx, key[0], key[1])
+	if err != nil {
+		return nil, true, err
+	}
+	if !run.IsTerminal() {
+		return nil, false, nil
+	}
+
+	// Recorded once per run, however many waiters share it
+	p.client.recordRunUsage(ctx, run)
+	return run, true, nil
+}
+
+func (p *Poller) pollVectorStoreFile(ctx context.Context, key pollKey) (*VectorStoreFile, bool, error) {
+	file, err := p.client.RetrieveVectorStoreFile(ctx, key[0], key[1])
+	if err != nil {
+		return nil, true, err
+	}
+	return file, file.IsTerminal(), nil
+}
+
+// pollKey identifies a resource by its parent and own ID
+type pollKey [2]string
+
+// pollGroup polls the resources of one type for their waiters
+type pollGroup[T any] struct {
+	poller *Poller
+	fetch  func(ctx context.Context, key pollKey) (T, bool, error) // Reports whether the waiters are done
+
+	mu      sync.Mutex
+	targets map[pollKey]map[chan pollResult[T]]bool // Waiters by resource
+	queue   []pollKey                               // Resources in polling order
+	running bool
+	cancel  context.CancelFunc // Aborts the current round when the last waiter leaves
+}
+
+type pollResult[T any] struct {
+	value T
+	err   error
+}
+
+func newPollGroup[T any](p *Poller, fetch func(context.Context, pollKey) (T, bool, error)) *pollGroup[T] {
+	return &pollGroup[T]{poller: p, fetch: fetch, targets: map[pollKey]map[chan pollResult[T]]bool{}}
+}
+
+// wait registers a waiter for the resource, starting the loop if needed
+func (g *pollGroup[T]) wait(ctx context.Context, key pollKey) (T, error) {
+	ch := make(chan pollResult[T], 1)
+
+	g.mu.Lock()
+	waiters, ok := g.targets[key]
+	if !ok {
+		waiters = map[chan pollResult[T]]bool{}
+		g.targets[key] = waiters
+		g.queue = append(g.queue, key)
+	}
+	waiters[ch] = true
+	if !g.running {
+		g.running = true
+		go g.loop()
+	}
+	g.mu.Unlock()
+
+	select {
+	case result := <-ch:
+		return result.value, result.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		delete(waiters, ch)
+		if g.cancel != nil && g.idleLocked() {
+			g.cancel()
+		}
+		g.mu.Unlock()
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// loop polls the resources until none is waited for. Each round has its own
+// context, cancelled when the last waiter gives up so the requests in flight
+// and the wait for the next round end right away.
+func (g *pollGroup[T]) loop() {
+	for {
+		ctx, batch := g.next()
+		if batch == nil {
+			return
+		}
+
+		var wg sync.WaitGroup
+		for _, key := range batch {
+			wg.Add(1)
+			go func(key pollKey) {
+				defer wg.Done()
+				value, done, err := g.fetch(ctx, key)
+				if done && ctx.Err() == nil {
+					g.resolve(key, pollResult[T]{value: value, err: err})
+				}
+			}(key)
+		}
+		wg.Wait()
+
+		g.poller.client.clock.Sleep(ctx, g.poller.interval)
+	}
+}
+
+// next returns the resources to poll in this round and moves them to the back
+// of the queue. It stops the loop when nothing is waited for anymore.
+func (g *pollGroup[T]) next() (context.Context, []pollKey) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.cancel != nil {
+		g.cancel()
+		g.cancel = nil
+	}
+
+	// Forget the resources whose waiters all gave up
+	g.queue = slices.DeleteFunc(g.queue, func(key pollKey) bool {
+		if len(g.targets[key]) == 0 {
+			delete(g.targets, key)
+			return true
+		}
+		return false
+	})
+	if len(g.queue) == 0 {
+		g.running = false
+		return nil, nil
+	}
+
+	n := len(g.queue)
+	if g.poller.maxPolls > 0 && g.poller.maxPolls < n {
+		n = g.poller.maxPolls
+	}
+	batch := slices.Clone(g.queue[:n])
+	g.queue = append(g.queue[n:], batch...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g.cancel = cancel
+	return ctx, batch
+}
+
+// idleLocked reports whether no resource is waited for anymore
+func (g *pollGroup[T]) idleLocked() bool {
+	for _, waiters := range g.targets {
+		if len(waiters) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve hands the result to the waiters of the resource and stops polling it
+func (g *pollGroup[T]) resolve(key pollKey, result pollResult[T]) {
+	g.mu.Lock()
+	waiters := make([]chan pollResult[T], 0, len(g.targets[key]))
+	for ch := range g.targets[key] {
+		waiters = append(waiters, ch)
+	}
+	delete(g.targets, key)
+	g.queue = slices.DeleteFunc(g.queue, func(k pollKey) bool { return k == key })
+	g.mu.Unlock()
+
+	for _, ch := range waiters {
+		ch <- result
+	}
+}
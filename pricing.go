@@ -0,0 +1,56 @@
+package openai
+
+import "strings"
+
+// ModelPrice is the price of a model in dollars per million tokens
+type ModelPrice struct {
+	Input       float64
+	Output      float64
+	CachedInput float64 // Prompt tokens read from the prompt cache, priced as Input when 0
+}
+
+// Pricing maps model names to their price. Dated snapshots such as
+// "gpt-4o-mini-2024-07-18" are priced as the longest model name they start with.
+type Pricing map[string]ModelPrice
+
+// DefaultPricing holds the list prices of common models. Prices change; set
+// your own table where accuracy matters.
+var DefaultPricing = Pricing{
+	"gpt-4o":                 {Input: 2.50, Output: 10.00, CachedInput: 1.25},
+	"gpt-4o-mini":            {Input: 0.15, Output: 0.60, CachedInput: 0.075},
+	"gpt-4.1":                {Input: 2.00, Output: 8.00, CachedInput: 0.50},
+	"gpt-4.1-mini":           {Input: 0.40, Output: 1.60, CachedInput: 0.10},
+	"gpt-4.1-nano":           {Input: 0.10, Output: 0.40, CachedInput: 0.025},
+	"o3-mini":                {Input: 1.10, Output: 4.40, CachedInput: 0.55},
+	"text-embedding-3-small": {Input: 0.02},
+	"text-embedding-3-large": {Input: 0.13},
+	"text-embedding-ada-002": {Input: 0.10},
+}
+
+// Price returns the price of a model
+func (p Pricing) Price(model string) (ModelPrice, bool) {
+	if price, ok := p[model]; ok {
+		return price, true
+	}
+
+	best := ""
+	for name := range p {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelPrice{}, false
+	}
+	return p[best], true
+}
+
+// Cost returns the cost in dollars of the usage, and false when the model has
+// no price
+func (p Pricing) Cost(usage Usage) (float64, bool) {
+	price, ok := p.Price(usage.Model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(usage.PromptTokens)*price.Input + float64(usage.CompletionTokens)*price.Output) / 1e6, true
+}
@@ -0,0 +1,87 @@
+package openai
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// ProgressEvent is a machine-readable progress record written as one JSON line
+// by the long-running helpers, for external orchestrators to follow
+type ProgressEvent struct {
+	Time   time.Time `json:"time"`
+	Event  string    `json:"event"`            // e.g. "case_started", "stack_apply_completed"
+	Name   string    `json:"name,omitempty"`   // Name of the item the event is about
+	ID     string    `json:"id,omitempty"`     // ID of the API resource the event is about
+	Done   int       `json:"done,omitempty"`   // Items processed so far
+	Total  int       `json:"total,omitempty"`  // Items to process
+	Status string    `json:"status,omitempty"` // e.g. "passed", "failed"
+	Error  string    `json:"error,omitempty"`
+}
+
+// progressWriter encodes events as JSON Lines. A nil writer or destination
+// discards events. Write errors are ignored: progress reporting must not fail
+// the operation it reports on.
+type progressWriter struct {
+	mu  sync.Mutex
+	w   io.Writer
+	now func() time.Time
+}
+
+// newProgressWriter returns a writer timing events with now
+func newProgressWriter(w io.Writer, now func() time.Time) *progressWriter {
+	return &progressWriter{w: w, now: now}
+}
+
+// WithProgress writes a JSON line per ProgressEvent to w as the long-running
+// helpers of the client work, e.g. for an Airflow task to follow them:
+//   - ApplyStack: stack_apply_started, file_removed, file_queued, then
+//     file_ingested or file_failed as vector stores process the uploads,
+//     vector_store_synced, assistant_applied, vector_store_deleted and
+//     stack_apply_completed or stack_apply_failed
+//   - DestroyStack: stack_destroy_started, assistant_deleted,
+//     vector_store_deleted and stack_destroy_completed or stack_destroy_failed
+//   - SubmitBatch and CreateBatch: batch_file_uploaded and batch_submitted
+//   - BatchResults: batch_file_downloaded and batch_results_collected
+//   - EvalRunner, unless its Progress is set: see EvalRunner.Progress
+//
+// Like WithIngestionObserver, it makes ApplyStack wait for the vector stores
+// to process the files it uploads.
+func WithProgress(w io.Writer) Option {
+	return func(c *Client) {
+		c.progress = newProgressWriter(w, func() time.Time { return c.clock.Now() })
+	}
+}
+
+// emitProgress writes an event to the progress writer of the client, if any
+func (c *Client) emitProgress(event ProgressEvent) {
+	c.progress.emit(event)
+}
+
+// progressOutcome returns the event ending an operation named op, e.g.
+// "stack_apply_completed" or "stack_apply_failed"
+func progressOutcome(op, name string, err error) ProgressEvent {
+	if err != nil {
+		return ProgressEvent{Event: op + "_failed", Name: name, Status: "failed", Error: err.Error()}
+	}
+	return ProgressEvent{Event: op + "_completed", Name: name, Status: "completed"}
+}
+
+func (p *progressWriter) emit(event ProgressEvent) {
+	if p == nil || p.w == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = p.now().UTC()
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.w.Write(append(line, '\n'))
+}
@@ -0,0 +1,140 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// queryExpansionSchema constrains the expansion model to a list of queries
+var queryExpansionSchema = json.RawMessage(`{
+	"type": "object",
+	"properties": {
+		"queries": {"type": "array", "items": {"type": "string"}}
+	},
+	"required": ["queries"],
+	"additionalProperties": false
+}`)
+
+// ExpandQuery asks a small model for up to n paraphrases or sub-questions of a
+// search query. The original query is not part of the returned list.
+func (c *Client) ExpandQuery(ctx context.Context, query string, n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	resp, err := c.createSemanticChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model: openai.GPT4oMini,
+		Messages: []openai.ChatCompletionMessage{
+			{
+				Role: openai.ChatMessageRoleSystem,
+				Content: fmt.Sprintf("You rewrite search queries for a document retrieval system. "+
+					"Return %d alternative queries: paraphrases using different vocabulary, "+
+					"or sub-questions that together cover the original question.", n),
+			},
+			{Role: openai.ChatMessageRoleUser, Content: query},
+		},
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   "query_expansion",
+				Schema: queryExpansionSchema,
+				Strict: true,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error expanding query: %w", sdkError(err))
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no query expansion returned")
+	}
+
+	var expansion struct {
+		Queries []string `json:"queries"`
+	}
+	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &expansion); err != nil {
+		return nil, fmt.Errorf("failed to decode query expansion: %w", err)
+	}
+
+	// Drop blanks, duplicates and echoes of the original query
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(query)): true}
+	var queries []string
+	for _, q := range expansion.Queries {
+		key := strings.ToLower(strings.TrimSpace(q))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		queries = append(queries, strings.TrimSpace(q))
+		if len(queries) == n {
+			break
+		}
+	}
+	return queries, nil
+}
+
+// ExpandedSearchResult is the outcome of SearchVectorStoreExpanded
+type ExpandedSearchResult struct {
+	Queries []string                  // The original query followed by its expansions
+	Results []VectorStoreSearchResult // Merged results, best score first
+}
+
+// SearchVectorStoreExpanded expands the query into n additional queries with
+// ExpandQuery, searches the vector store for each of them and merges the results.
+// A chunk returned by several queries is kept once with its best score.
+// params.MaxNumResults, when set, caps the merged results as well.
+func (c *Client) SearchVectorStoreExpanded(ctx context.Context, vectorStoreID string, params *SearchVectorStoreParams, n int) (*ExpandedSearchResult, error) {
+	expansions, err := c.ExpandQuery(ctx, params.Query, n)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ExpandedSearchResult{Queries: append([]string{params.Query}, expansions...)}
+	best := map[string]int{} // chunk key -> index in result.Results
+
+	for _, query := range result.Queries {
+		queryParams := *params
+		queryParams.Query = query
+
+		results, err := c.SearchVectorStore(ctx, vectorStoreID, &queryParams)
+		if err != nil {
+			return nil, fmt.Errorf("search for %q failed: %w", query, err)
+		}
+
+		for _, r := range results {
+			key := searchResultKey(r)
+			if i, ok := best[key]; ok {
+				if r.Score > result.Results[i].Score {
+					result.Results[i] = r
+				}
+				continue
+			}
+			best[key] = len(result.Results)
+			result.Results = append(result.Results, r)
+		}
+	}
+
+	sort.SliceStable(result.Results, func(i, j int) bool {
+		return result.Results[i].Score > result.Results[j].Score
+	})
+	if params.MaxNumResults > 0 && len(result.Results) > params.MaxNumResults {
+		result.Results = result.Results[:params.MaxNumResults]
+	}
+	return result, nil
+}
+
+// searchResultKey identifies a chunk across searches
+func searchResultKey(r VectorStoreSearchResult) string {
+	var b strings.Builder
+	b.WriteString(r.FileID)
+	for _, c := range r.Content {
+		b.WriteString("\x00")
+		b.WriteString(c.Text)
+	}
+	return b.String()
+}
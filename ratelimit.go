@@ -0,0 +1,137 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitInfo holds the rate limit headers sent by the API. The reset
+// durations are relative to the time the response was received.
+type RateLimitInfo struct {
+	LimitRequests     int
+	LimitTokens       int
+	RemainingRequests int
+	RemainingTokens   int
+	ResetRequests     time.Duration // Time until the request budget is fully restored
+	ResetTokens       time.Duration // Time until the token budget is fully restored
+}
+
+// parseRateLimitInfo reads the x-ratelimit-* headers. It returns false when the
+// response carries none of them.
+func parseRateLimitInfo(header http.Header) (RateLimitInfo, bool) {
+	var info RateLimitInfo
+	found := false
+
+	ints := map[string]*int{
+		"X-Ratelimit-Limit-Requests":     &info.LimitRequests,
+		"X-Ratelimit-Limit-Tokens":       &info.LimitTokens,
+		"X-Ratelimit-Remaining-Requests": &info.RemainingRequests,
+		"X-Ratelimit-Remaining-Tokens":   &info.RemainingTokens,
+	}
+	for name, dst := range ints {
+		if v, err := strconv.Atoi(header.Get(name)); err == nil {
+			*dst = v
+			found = true
+		}
+	}
+
+	// Resets are formatted as Go-like durations, e.g. "1s", "6m0s" or "20ms"
+	durations := map[string]*time.Duration{
+		"X-Ratelimit-Reset-Requests": &info.ResetRequests,
+		"X-Ratelimit-Reset-Tokens":   &info.ResetTokens,
+	}
+	for name, dst := range durations {
+		if d, err := time.ParseDuration(header.Get(name)); err == nil {
+			*dst = d
+			found = true
+		}
+	}
+	return info, found
+}
+
+// WithRateLimitCallback calls fn with the rate limit headers of every response
+// that carries them, so callers can throttle before hitting the limits. fn may
+// be called from several goroutines at once.
+func WithRateLimitCallback(fn func(RateLimitInfo)) Option {
+	return func(c *Client) {
+		c.onRateLimitInfo = fn
+	}
+}
+
+// WithOnRateLimited calls fn for every response rejected with a 429 status,
+// including those about to be retried, so a batch job can pause its other
+// workers for err.RetryAfter instead of having them fail too. fn may be called
+// from several goroutines at once.
+func WithOnRateLimited(fn func(ctx context.Context, err *RateLimitError)) Option {
+	return func(c *Client) {
+		c.onRateLimited = fn
+	}
+}
+
+// notifyRateLimited calls the OnRateLimited callback with the error of a 429
+// response. The body is read to build the error and restored for the caller.
+func (c *Client) notifyRateLimited(ctx context.Context, resp *http.Response) {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	resp.Body.Close()
+
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	var rateErr *RateLimitError
+	if errors.As(c.responseError(resp), &rateErr) {
+		c.onRateLimited(ctx, rateErr)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+}
+
+// RateLimitError is returned when a request is rejected with a 429 status after
+// the retries allowed by the client's retry policy. It wraps the APIError.
+type RateLimitError struct {
+	*APIError
+	RetryAfter time.Duration // Delay requested by the API, or the time until the exhausted budget resets
+	RateLimit  RateLimitInfo
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s (retry after %s)", e.APIError.Error(), e.RetryAfter)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.APIError
+}
+
+// responseError builds the error returned for a non-2xx response
+func (c *Client) responseError(resp *http.Response) error {
+	return c.rateLimitError(resp, newAPIError(resp))
+}
+
+// rateLimitError wraps apiErr in a RateLimitError when the response is a 429
+func (c *Client) rateLimitError(resp *http.Response, apiErr *APIError) error {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return apiErr
+	}
+
+	info, _ := parseRateLimitInfo(resp.Header)
+	rateErr := &RateLimitError{APIError: apiErr, RateLimit: info}
+	if d, ok := retryAfter(resp.Header, c.clock.Now()); ok {
+		rateErr.RetryAfter = d
+	} else if info.RemainingRequests == 0 && info.ResetRequests > 0 {
+		rateErr.RetryAfter = info.ResetRequests
+	} else {
+		rateErr.RetryAfter = info.ResetTokens
+	}
+	return rateErr
+}
+
+// RetryAfterOf returns the delay requested by a RateLimitError in err's chain
+func RetryAfterOf(err error) (time.Duration, bool) {
+	var rateErr *RateLimitError
+	if errors.As(err, &rateErr) {
+		return rateErr.RetryAfter, true
+	}
+	return 0, false
+}
@@ -0,0 +1,100 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles requests on the client side so bulk workloads stay
+// under the organization's requests-per-minute and tokens-per-minute limits.
+// It is safe for concurrent use and can be shared by several clients using
+// the same organization.
+//
+// Tokens are estimated from the size of JSON request bodies (about 4 bytes per
+// token); file uploads are only counted as requests.
+type RateLimiter struct {
+	mu       sync.Mutex
+	requests bucket
+	tokens   bucket
+}
+
+// NewRateLimiter returns a limiter allowing the given number of requests and
+// tokens per minute, with bursts up to one minute's worth. A limit of 0 or
+// less disables that bucket.
+func NewRateLimiter(requestsPerMinute, tokensPerMinute int) *RateLimiter {
+	return &RateLimiter{
+		requests: newBucket(requestsPerMinute),
+		tokens:   newBucket(tokensPerMinute),
+	}
+}
+
+// WithRateLimiter makes the client wait for the limiter before every request,
+// retries included
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(c *Client) {
+		c.rateLimiter = limiter
+	}
+}
+
+// wait blocks until a request of the given estimated token count is allowed,
+// or ctx is done. Capacity is reserved up front so concurrent callers queue
+// up instead of all waking at once.
+func (l *RateLimiter) wait(ctx context.Context, clock Clock, tokens int) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := clock.Now()
+	delay := max(l.requests.reserve(now, 1), l.tokens.reserve(now, float64(tokens)))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	return clock.Sleep(ctx, delay)
+}
+
+// bucket is a token bucket refilled continuously
+type bucket struct {
+	rate      float64 // Units per second, 0 when disabled
+	capacity  float64
+	available float64
+	last      time.Time
+}
+
+func newBucket(perMinute int) bucket {
+	if perMinute <= 0 {
+		return bucket{}
+	}
+	return bucket{rate: float64(perMinute) / 60, capacity: float64(perMinute), available: float64(perMinute)}
+}
+
+// reserve takes n units, letting the balance go negative, and returns how long
+// the caller must wait for the balance to be paid back
+func (b *bucket) reserve(now time.Time, n float64) time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	if !b.last.IsZero() {
+		b.available = min(b.capacity, b.available+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	b.available -= min(n, b.capacity)
+	if b.available >= 0 {
+		return 0
+	}
+	return time.Duration(-b.available / b.rate * float64(time.Second))
+}
+
+// estimateTokens guesses the tokens consumed by a request from its JSON body
+func estimateTokens(req *http.Request) int {
+	if req.ContentLength <= 0 || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return 0
+	}
+	return int(req.ContentLength+3) / 4
+}
@@ -0,0 +1,82 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// do sends a request to path, relative to the base URL, and decodes the
+// response into out. body is sent as JSON unless nil. out is decoded as JSON,
+// except an io.Writer which receives the raw body as it streams in; nil
+// discards the response. Responses outside of 2xx are returned as an APIError,
+// or a RateLimitError for 429s, after the retries made by send.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	return c.doRequest(req, out)
+}
+
+// newRequest builds a request to path with body encoded as JSON. The body is
+// kept in memory so it can be replayed by retries.
+func (c *Client) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// doRequest sends a request built by the caller, e.g. a multipart upload, and
+// handles the response like do
+func (c *Client) doRequest(req *http.Request, out interface{}) error {
+	resp, err := c.send(req)
+	if err != nil {
+		// A cancelled caller gets the context's error rather than the
+		// transport's rendering of it
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return c.responseError(resp)
+	}
+
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case io.Writer:
+		if _, err := io.Copy(out, resp.Body); err != nil {
+			if ctxErr := req.Context().Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		return nil
+	default:
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,145 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RequestOption customizes the requests of a single call, see WithRequestOptions
+type RequestOption func(*requestConfig)
+
+type requestConfig struct {
+	headers   http.Header
+	extraBody map[string]interface{}
+	timeout   time.Duration
+	auditTags map[string]string
+
+	correlationID string
+
+	cacheScope        string
+	semanticThreshold float64
+	semanticHit       *SemanticCacheHit
+	beta              *string
+}
+
+type requestOptionsKey struct{}
+
+// WithExtraHeader sets a header on the request, e.g. a beta feature flag or a
+// gateway routing header. It overrides the headers set by the client.
+func WithExtraHeader(key, value string) RequestOption {
+	return func(config *requestConfig) {
+		if config.headers == nil {
+			config.headers = http.Header{}
+		}
+		config.headers.Set(key, value)
+	}
+}
+
+// WithExtraBody adds a field to the JSON body of the request, or replaces the
+// field of the same name, for parameters the request structs do not declare
+func WithExtraBody(key string, value interface{}) RequestOption {
+	return func(config *requestConfig) {
+		if config.extraBody == nil {
+			config.extraBody = map[string]interface{}{}
+		}
+		config.extraBody[key] = value
+	}
+}
+
+// WithRequestOptions returns a context whose calls apply the options to every
+// request they send, on top of the options of the parent context:
+//
+//	ctx = openai.WithRequestOptions(ctx, openai.WithExtraHeader("X-Route", "eu"))
+//	run, err := client.CreateRun(ctx, threadID, params, nil)
+func WithRequestOptions(ctx context.Context, opts ...RequestOption) context.Context {
+	config := &requestConfig{}
+	if parent, ok := ctx.Value(requestOptionsKey{}).(*requestConfig); ok {
+		config.headers = parent.headers.Clone()
+		config.timeout = parent.timeout
+		config.correlationID = parent.correlationID
+		config.semanticThreshold = parent.semanticThreshold
+		config.semanticHit = parent.semanticHit
+		config.beta = parent.beta
+		if parent.auditTags != nil {
+			config.auditTags = make(map[string]string, len(parent.auditTags))
+			for k, v := range parent.auditTags {
+				config.auditTags[k] = v
+			}
+		}
+		if parent.extraBody != nil {
+			config.extraBody = make(map[string]interface{}, len(parent.extraBody))
+			for k, v := range parent.extraBody {
+				config.extraBody[k] = v
+			}
+		}
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return context.WithValue(ctx, requestOptionsKey{}, config)
+}
+
+// applyRequestOptions applies the options carried by the request context
+func applyRequestOptions(req *http.Request) error {
+	config, ok := req.Context().Value(requestOptionsKey{}).(*requestConfig)
+	if !ok {
+		return nil
+	}
+
+	for key, values := range config.headers {
+		req.Header[key] = values
+	}
+
+	if len(config.extraBody) == 0 {
+		return nil
+	}
+	return rewriteJSONBody(req, func(fields map[string]json.RawMessage) error {
+		for k, v := range config.extraBody {
+			raw, err := json.Marshal(v)
+			if err != nil {
+				return fmt.Errorf("failed to marshal extra body field %q: %w", k, err)
+			}
+			fields[k] = raw
+		}
+		return nil
+	})
+}
+
+// rewriteJSONBody lets edit modify the top-level fields of a JSON request body.
+// Fields are kept raw so the values edit leaves alone are not altered. Requests
+// without a JSON body are left unchanged.
+func rewriteJSONBody(req *http.Request, edit func(fields map[string]json.RawMessage) error) error {
+	if req.Body == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return fmt.Errorf("failed to decode request body: %w", err)
+	}
+	if err := edit(fields); err != nil {
+		return err
+	}
+	body, err = json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to encode request body: %w", err)
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+	return nil
+}
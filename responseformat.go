@@ -0,0 +1,111 @@
+package openai
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+)
+
+// Values of the ResponseFormat of assistants and runs. ResponseFormatAuto lets
+// the model pick; the other formats are types so they can carry options.
+const ResponseFormatAuto = "auto"
+
+// ResponseFormatText makes the model answer with plain text
+type ResponseFormatText struct{}
+
+func (ResponseFormatText) MarshalJSON() ([]byte, error) {
+	return []byte(`{"type":"text"}`), nil
+}
+
+// ResponseFormatJSONObject makes the model answer with a JSON object. The
+// instructions must still ask for JSON and describe its fields.
+type ResponseFormatJSONObject struct{}
+
+func (ResponseFormatJSONObject) MarshalJSON() ([]byte, error) {
+	return []byte(`{"type":"json_object"}`), nil
+}
+
+// ResponseFormatJSONSchema makes the model answer with JSON matching Schema.
+// With Strict, the answer is guaranteed to match, but the schema must then
+// list every property as required and forbid additional properties.
+type ResponseFormatJSONSchema struct {
+	Name        string
+	Description string
+	Schema      interface{}
+	Strict      bool
+}
+
+func (f ResponseFormatJSONSchema) MarshalJSON() ([]byte, error) {
+	type jsonSchemaFormat struct {
+		Name        string      `json:"name"`
+		Description string      `json:"description,omitempty"`
+		Schema      interface{} `json:"schema,omitempty"`
+		Strict      bool        `json:"strict,omitempty"`
+	}
+	return json.Marshal(struct {
+		Type       string           `json:"type"`
+		JSONSchema jsonSchemaFormat `json:"json_schema"`
+	}{
+		Type:       "json_schema",
+		JSONSchema: jsonSchemaFormat(f),
+	})
+}
+
+// ResponseFormatFromStruct returns a strict JSON schema response format built
+// from the struct T like FunctionToolFromStruct, so answers can be decoded
+// into a T. Optional fields are made required but nullable, as strict mode
+// demands. It panics when T is not a struct.
+func ResponseFormatFromStruct[T any](name string) ResponseFormatJSONSchema {
+	t := reflect.TypeFor[T]()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic("openai: ResponseFormatFromStruct requires a struct, got " + t.String())
+	}
+	schema := jsonSchema(t, map[reflect.Type]bool{})
+	strictSchema(schema)
+	return ResponseFormatJSONSchema{Name: name, Schema: schema, Strict: true}
+}
+
+// strictSchema makes the optional properties of the objects of schema
+// required and nullable
+func strictSchema(schema map[string]interface{}) {
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		strictSchema(items)
+	}
+	properties, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	required, _ := schema["required"].([]string)
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		property := properties[name].(map[string]interface{})
+		strictSchema(property)
+		if slices.Contains(required, name) {
+			continue
+		}
+		required = append(required, name)
+		properties[name] = nullable(property)
+	}
+	schema["required"] = required
+}
+
+// nullable returns schema also accepting null
+func nullable(schema map[string]interface{}) map[string]interface{} {
+	typ, ok := schema["type"].(string)
+	if !ok {
+		return map[string]interface{}{"anyOf": []interface{}{schema, map[string]interface{}{"type": "null"}}}
+	}
+	schema["type"] = []string{typ, "null"}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		schema["enum"] = append(enum, nil)
+	}
+	return schema
+}
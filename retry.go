@@ -0,0 +1,181 @@
+package openai
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how requests failing with a transient error are retried.
+// Network errors and the 429, 500, 502 and 503 statuses are retried with an
+// exponential backoff and jitter, or after the delay given by the Retry-After
+// header when the API sends one.
+type RetryPolicy struct {
+	MaxRetries int           // Number of retries after the first attempt, 0 disables retries
+	BaseDelay  time.Duration // Delay before the first retry, doubled on each retry
+	MaxDelay   time.Duration // Upper bound of the computed backoff
+}
+
+// DefaultRetryPolicy is the policy of clients created without WithRetryPolicy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 2,
+	BaseDelay:  500 * time.Millisecond,
+	MaxDelay:   30 * time.Second,
+}
+
+// WithRetryPolicy sets how transient errors are retried
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithMaxRetries sets the number of retries of the client's retry policy
+func WithMaxRetries(n int) Option {
+	return func(c *Client) {
+		c.retryPolicy.MaxRetries = n
+	}
+}
+
+// retryableStatus reports whether a response status is worth retrying
+func retryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry (0 for the first retry)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.BaseDelay
+	for i := 0; i < retry && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Equal jitter: keep half of the delay and randomize the other half so
+	// clients failing together do not retry together
+	half := delay / 2
+	return half + rand.N(half+1)
+}
+
+// retryAfter parses the delay requested by the API, if any. The
+// non-standard retry-after-ms header is preferred for its precision.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	if ms := header.Get("Retry-After-Ms"); ms != "" {
+		if v, err := strconv.ParseFloat(ms, 64); err == nil && v >= 0 {
+			return time.Duration(v * float64(time.Millisecond)), true
+		}
+	}
+
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// sendWithRetries executes the request, retrying transient failures per the
+// client's retry policy. Requests whose body cannot be replayed are sent once.
+func (c *Client) sendWithRetries(req *http.Request) (*http.Response, error) {
+	tokens := estimateTokens(req)
+	for retry := 0; ; retry++ {
+		// Tokens are only counted once, retries of a rejected request do not
+		// consume them again
+		if err := c.rateLimiter.wait(req.Context(), c.clock, tokens); err != nil {
+			return nil, err
+		}
+		tokens = 0
+
+		attemptReq, a := c.newAttempt(req)
+		if c.gzip {
+			var err error
+			if attemptReq, err = c.gzipRequest(attemptReq); err != nil {
+				a.fail(req.Context(), err)
+				return nil, err
+			}
+		}
+		probe, err := c.circuitBreaker.allow(c.clock.Now())
+		if err != nil {
+			a.fail(req.Context(), err)
+			return nil, err
+		}
+		start := c.clock.Now()
+		resp, err := c.httpClient.Do(attemptReq)
+		if err != nil {
+			err = a.fail(attemptReq.Context(), err)
+		} else {
+			a.watch(resp, c.timeouts.StreamRead)
+			gunzipResponse(resp)
+		}
+		if c.circuitBreaker.record(c.clock.Now(), breakerFailure(req.Context(), resp, err), probe) {
+			c.logger.WarnContext(req.Context(), "circuit breaker opened", "method", req.Method, "url", req.URL.Redacted())
+		}
+		if c.auditSink != nil {
+			c.auditRequest(req, start, resp, err)
+		}
+		if c.metrics != nil {
+			c.observeRequest(req, retry, start, resp, err)
+		}
+		if c.onRateLimited != nil && err == nil && resp.StatusCode == http.StatusTooManyRequests {
+			c.notifyRateLimited(req.Context(), resp)
+		}
+
+		if retry >= c.retryPolicy.MaxRetries || req.Context().Err() != nil {
+			return resp, err
+		}
+		if err == nil && !retryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+
+		delay := c.retryPolicy.backoff(retry)
+		if resp != nil {
+			if d, ok := retryAfter(resp.Header, c.clock.Now()); ok {
+				delay = d
+			}
+			// Drain the body so the connection can be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		}
+
+		args := []any{"method", req.Method, "url", req.URL.Redacted(), "retry", retry + 1, "delay", delay}
+		if err != nil {
+			args = append(args, "error", err)
+		} else {
+			args = append(args, "status", resp.StatusCode)
+		}
+		c.logger.WarnContext(req.Context(), "retrying request", args...)
+
+		if err := c.clock.Sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
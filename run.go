@@ -1,11 +1,10 @@
 package openai
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"net/url"
+	"time"
 )
 
 type CreateRunParams struct {
@@ -15,7 +14,7 @@ type CreateRunParams struct {
 	AdditionalInstructions *string                  `json:"additional_instructions,omitempty"`
 	AdditionalMessages     []ThreadMessage          `json:"additional_messages,omitempty"`
 	Tools                  []map[string]interface{} `json:"tools,omitempty"`
-	Metadata               map[string]string        `json:"metadata,omitempty"`
+	Metadata               Metadata                 `json:"metadata,omitempty"`
 	Temperature            *float64                 `json:"temperature,omitempty"`
 	TopP                   *float64                 `json:"top_p,omitempty"`
 	Stream                 *bool                    `json:"stream,omitempty"`
@@ -24,121 +23,227 @@ type CreateRunParams struct {
 	TruncationStrategy     *map[string]interface{}  `json:"truncation_strategy,omitempty"`
 	ToolChoice             *map[string]interface{}  `json:"tool_choice,omitempty"`
 	ParallelToolCalls      *bool                    `json:"parallel_tool_calls,omitempty"`
-	ResponseFormat         *interface{}             `json:"response_format,omitempty"`
+	ResponseFormat         interface{}              `json:"response_format,omitempty"` // ResponseFormatAuto, ResponseFormatJSONSchema...
 }
 
 type Run struct {
-	ID           string  `json:"id"`
-	Object       string  `json:"object"`
-	CreatedAt    int64   `json:"created_at"`
-	AssistantID  string  `json:"assistant_id"`
-	ThreadID     string  `json:"thread_id"`
-	Status       string  `json:"status"`
-	StartedAt    *int64  `json:"started_at,omitempty"`
-	ExpiresAt    *int64  `json:"expires_at,omitempty"`
-	CancelledAt  *int64  `json:"cancelled_at,omitempty"`
-	FailedAt     *int64  `json:"failed_at,omitempty"`
-	CompletedAt  *int64  `json:"completed_at,omitempty"`
-	LastError    *string `json:"last_error,omitempty"`
-	Model        string  `json:"model"`
-	Instructions *string `json:"instructions,omitempty"`
+	ID           string     `json:"id"`
+	Object       string     `json:"object"`
+	CreatedAt    Timestamp  `json:"created_at"`
+	AssistantID  string     `json:"assistant_id"`
+	ThreadID     string     `json:"thread_id"`
+	Status       string     `json:"status"`
+	StartedAt    *Timestamp `json:"started_at,omitempty"`
+	ExpiresAt    *Timestamp `json:"expires_at,omitempty"`
+	CancelledAt  *Timestamp `json:"cancelled_at,omitempty"`
+	FailedAt     *Timestamp `json:"failed_at,omitempty"`
+	CompletedAt  *Timestamp `json:"completed_at,omitempty"`
+	LastError    *RunError  `json:"last_error,omitempty"`
+	Model        string     `json:"model"`
+	Instructions *string    `json:"instructions,omitempty"`
 	// Tools             []map[string]string    `json:"tools,omitempty"`
-	Metadata          map[string]interface{} `json:"metadata,omitempty"`
-	IncompleteDetails *string                `json:"incomplete_details,omitempty"`
-	Usage             struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	Metadata            Metadata               `json:"metadata,omitempty"`
+	IncompleteDetails   *IncompleteDetails     `json:"incomplete_details,omitempty"`
+	RequiredAction      *RequiredAction        `json:"required_action,omitempty"`
+	Usage               RunUsage               `json:"usage"`
 	Temperature         *float64               `json:"temperature,omitempty"`
 	TopP                *float64               `json:"top_p,omitempty"`
 	MaxPromptTokens     *int                   `json:"max_prompt_tokens,omitempty"`
 	MaxCompletionTokens *int                   `json:"max_completion_tokens,omitempty"`
 	TruncationStrategy  map[string]interface{} `json:"truncation_strategy,omitempty"`
-	ResponseFormat      string                 `json:"response_format"`
+	ResponseFormat      interface{}            `json:"response_format"` // "auto" or an object, decoded as a map
 	ToolChoice          string                 `json:"tool_choice"`
 	ParallelToolCalls   *bool                  `json:"parallel_tool_calls,omitempty"`
 }
 
+// RunUsage is the number of tokens used by a run, set once it ends
+type RunUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+
+	PromptTokensDetails *struct {
+		CachedTokens int `json:"cached_tokens"` // Prompt tokens read from the prompt cache
+	} `json:"prompt_tokens_details,omitempty"`
+	CompletionTokensDetails *struct {
+		ReasoningTokens int `json:"reasoning_tokens"` // Completion tokens spent reasoning, billed as output
+	} `json:"completion_tokens_details,omitempty"`
+}
+
+// RunError describes why a run failed
+type RunError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// IncompleteDetails tells why a run or message ended incomplete
+type IncompleteDetails struct {
+	Reason string `json:"reason"`
+}
+
+// RequiredAction describes what is needed for a run in the "requires_action" status to continue
+type RequiredAction struct {
+	Type              string `json:"type"`
+	SubmitToolOutputs struct {
+		ToolCalls []RunToolCall `json:"tool_calls"`
+	} `json:"submit_tool_outputs"`
+}
+
+// RunToolCall is a function call requested by a run
+type RunToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// ToolOutput is the result of a RunToolCall sent back to the run
+type ToolOutput struct {
+	ToolCallID string `json:"tool_call_id"`
+	Output     string `json:"output"`
+}
+
 // CreateRun creates a run in a specified thread using the given parameters
-func CreateRun(threadID string, params *CreateRunParams, include []string) (*Run, error) {
-	url := fmt.Sprintf("https://api.openai.com/v1/threads/%s/runs", threadID)
+func (c *Client) CreateRun(ctx context.Context, threadID string, params *CreateRunParams, include []string) (*Run, error) {
+	path := "/threads/" + threadID + "/runs"
 	if len(include) > 0 {
-		queryParams := "?include=" + include[0]
-		for _, field := range include[1:] {
-			queryParams += "&include=" + field
-		}
-		url += queryParams
+		path += "?" + url.Values{"include": include}.Encode()
 	}
 
-	payloadBytes, err := json.Marshal(params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal run payload: %w", err)
+	var response Run
+	if err := c.do(ctx, "POST", path, c.routeRunModel(ctx, params), &response); err != nil {
+		return nil, fmt.Errorf("run creation failed: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create run request: %w", err)
+	c.logger.InfoContext(ctx, "run created", "thread_id", threadID, "run_id", response.ID, "status", response.Status)
+	return &response, nil
+}
+
+// RetrieveRun retrieves the status and details of a specific run within a thread
+func (c *Client) RetrieveRun(ctx context.Context, threadID, runID string) (*Run, error) {
+	var run Run
+	if err := c.do(ctx, "GET", "/threads/"+threadID+"/runs/"+runID, nil, &run); err != nil {
+		return nil, fmt.Errorf("run retrieval failed: %w", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
+	return &run, nil
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// ListRuns lists the runs of a thread, e.g.
+// ListRuns(ctx, threadID, WithLimit(10), WithOrder("desc"))
+func (c *Client) ListRuns(ctx context.Context, threadID string, opts ...ListOption) ([]Run, error) {
+	page, err := c.ListRunsPage(ctx, threadID, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("run request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	return page.Data, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("run creation failed with status %s: %s", resp.Status, string(body))
+// SubmitToolOutputs sends the outputs of the tool calls of a run in the "requires_action" status
+func (c *Client) SubmitToolOutputs(ctx context.Context, threadID, runID string, outputs []ToolOutput) (*Run, error) {
+	payload := map[string]interface{}{
+		"tool_outputs": c.limitToolOutputs(ctx, outputs),
 	}
 
-	// Decode the JSON response
-	var response Run
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode run response: %w", err)
+	var run Run
+	if err := c.do(ctx, "POST", "/threads/"+threadID+"/runs/"+runID+"/submit_tool_outputs", payload, &run); err != nil {
+		return nil, fmt.Errorf("submit tool outputs failed: %w", err)
 	}
-
-	fmt.Printf("Run created successfully with ID: %s, Status: %s\n", response.ID, response.Status)
-	return &response, nil
+	return &run, nil
 }
 
-// RetrieveRun retrieves the status and details of a specific run within a thread
-func RetrieveRun(threadID, runID string) (*Run, error) {
-	// Construct the request URL
-	url := fmt.Sprintf("https://api.openai.com/v1/threads/%s/runs/%s", threadID, runID)
+// IsTerminal reports whether the run can no longer progress without action from the caller
+func (r *Run) IsTerminal() bool {
+	switch r.Status {
+	case "queued", "in_progress", "cancelling":
+		return false
+	}
+	return true
+}
 
-	// Create the HTTP request
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create get run request: %w", err)
+// WaitForRun polls a run every interval until it completes, fails, expires, is
+// cancelled, is incomplete or requires action
+func (c *Client) WaitForRun(ctx context.Context, threadID, runID string, interval time.Duration) (*Run, error) {
+	if interval <= 0 {
+		interval = time.Second
 	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
 
-	// Execute the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// The whole poll counts as one call in flight for Close
+	ctx, done, err := c.track(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("run retrieval request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
+	defer done()
+
+	for {
+		run, err := c.RetrieveRun(ctx, threadID, runID)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+		if run.IsTerminal() {
+			c.recordRunUsage(ctx, run)
+			return run, nil
+		}
 
-	// Handle non-200 status codes
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("run retrieval failed with status %s: %s", resp.Status, string(body))
+		if err := c.clock.Sleep(ctx, interval); err != nil {
+			return nil, err
+		}
 	}
+}
 
-	// Decode the JSON response into a Run struct
-	var run Run
-	if err := json.NewDecoder(resp.Body).Decode(&run); err != nil {
-		return nil, fmt.Errorf("failed to decode run response: %w", err)
-	}
+// RunStep represents a step taken by a run: creating a message or calling tools
+type RunStep struct {
+	ID          string         `json:"id"`
+	Object      string         `json:"object"`
+	CreatedAt   Timestamp      `json:"created_at"`
+	RunID       string         `json:"run_id"`
+	Type        string         `json:"type"`
+	Status      string         `json:"status"`
+	StepDetails RunStepDetails `json:"step_details"`
+	LastError   *RunError      `json:"last_error,omitempty"`
+	Usage       *RunStepUsage  `json:"usage,omitempty"`
+}
 
-	return &run, nil
+// RunStepDetails holds the message created or the tools called by a run step
+type RunStepDetails struct {
+	Type            string `json:"type"`
+	MessageCreation *struct {
+		MessageID string `json:"message_id"`
+	} `json:"message_creation,omitempty"`
+	ToolCalls []RunStepToolCall `json:"tool_calls,omitempty"`
+}
+
+// RunStepToolCall is a tool call made during a run step
+type RunStepToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"` // "function", "file_search" or "code_interpreter"
+	Function *struct {
+		Name      string  `json:"name"`
+		Arguments string  `json:"arguments"`
+		Output    *string `json:"output"`
+	} `json:"function,omitempty"`
+}
+
+// RunStepUsage reports the tokens used by a run step
+type RunStepUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ListRunSteps retrieves the steps of a run, 100 at most in chronological
+// order unless opts set another limit or order
+func (c *Client) ListRunSteps(ctx context.Context, threadID, runID string, opts ...ListOption) ([]RunStep, error) {
+	var result struct {
+		Data []RunStep `json:"data"`
+	}
+	opts = append([]ListOption{WithOrder("asc"), WithLimit(100)}, opts...)
+	if err := c.do(ctx, "GET", listPath("/threads/"+threadID+"/runs/"+runID+"/steps", opts), nil, &result); err != nil {
+		return nil, fmt.Errorf("list run steps failed: %w", err)
+	}
+	return result.Data, nil
 }
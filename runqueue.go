@@ -0,0 +1,201 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// RunPriority is the scheduling class of a queued run. Lower values are
+// dispatched first.
+type RunPriority int
+
+const (
+	RunPriorityHigh RunPriority = iota
+	RunPriorityNormal
+	RunPriorityLow
+
+	numRunPriorities = 3
+)
+
+// ErrRunQueueClosed is returned when submitting to a closed queue
+var ErrRunQueueClosed = errors.New("run queue is closed")
+
+// RunRequest describes a run waiting in a RunQueue
+type RunRequest struct {
+	Tenant   string // Runs of the same tenant are dispatched in FIFO order
+	Priority RunPriority
+	ThreadID string
+	Params   *CreateRunParams
+	Include  []string
+}
+
+type queuedRun struct {
+	ctx  context.Context
+	req  RunRequest
+	done chan struct{}
+	run  *Run
+	err  error
+}
+
+// runClass holds the pending runs of one priority class, grouped by tenant.
+// Tenants are served round-robin so a single busy tenant cannot starve the others.
+type runClass struct {
+	tenants []string
+	pending map[string][]*queuedRun
+}
+
+// RunQueue schedules CreateRun calls across many threads. It caps the number
+// of runs being created concurrently, dispatches higher priority classes first
+// and is fair between tenants inside a class.
+type RunQueue struct {
+	client        *Client
+	mu            sync.Mutex
+	maxConcurrent int
+	running       int
+	closed        bool
+	classes       [numRunPriorities]runClass
+}
+
+// NewRunQueue returns a queue that creates runs with the client, at most
+// maxConcurrent at a time
+func NewRunQueue(client *Client, maxConcurrent int) *RunQueue {
+	if maxConcurrent <= 0 {
+		maxConcurrent = 1
+	}
+	q := &RunQueue{client: client, maxConcurrent: maxConcurrent}
+	for i := range q.classes {
+		q.classes[i].pending = map[string][]*queuedRun{}
+	}
+	return q
+}
+
+// Submit queues the run and blocks until it has been created, failed, or ctx is
+// done. A run still waiting in the queue when ctx is done is dropped.
+func (q *RunQueue) Submit(ctx context.Context, req RunRequest) (*Run, error) {
+	if req.Priority < 0 || req.Priority >= numRunPriorities {
+		req.Priority = RunPriorityNormal
+	}
+
+	item := &queuedRun{ctx: ctx, req: req, done: make(chan struct{})}
+
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil, ErrRunQueueClosed
+	}
+	class := &q.classes[req.Priority]
+	if len(class.pending[req.Tenant]) == 0 {
+		class.tenants = append(class.tenants, req.Tenant)
+	}
+	class.pending[req.Tenant] = append(class.pending[req.Tenant], item)
+	q.dispatchLocked()
+	q.mu.Unlock()
+
+	select {
+	case <-item.done:
+		return item.run, item.err
+	case <-ctx.Done():
+		q.mu.Lock()
+		removed := q.removeLocked(item)
+		q.mu.Unlock()
+		if !removed {
+			// Already dispatched, wait for the outcome so the run is not lost
+			<-item.done
+			return item.run, item.err
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// Len returns the number of runs waiting to be dispatched
+func (q *RunQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	n := 0
+	for i := range q.classes {
+		for _, items := range q.classes[i].pending {
+			n += len(items)
+		}
+	}
+	return n
+}
+
+// Close rejects new submissions. Runs already queued are still dispatched.
+func (q *RunQueue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+}
+
+// dispatchLocked starts queued runs until the concurrency cap is reached
+func (q *RunQueue) dispatchLocked() {
+	for q.running < q.maxConcurrent {
+		item := q.nextLocked()
+		if item == nil {
+			return
+		}
+		q.running++
+		go q.execute(item)
+	}
+}
+
+// nextLocked pops the next run: highest priority class first, then the tenant
+// at the head of that class' round-robin.
+func (q *RunQueue) nextLocked() *queuedRun {
+	for i := range q.classes {
+		class := &q.classes[i]
+		if len(class.tenants) == 0 {
+			continue
+		}
+
+		tenant := class.tenants[0]
+		items := class.pending[tenant]
+		item := items[0]
+		class.tenants = class.tenants[1:]
+		if len(items) > 1 {
+			class.pending[tenant] = items[1:]
+			class.tenants = append(class.tenants, tenant)
+		} else {
+			delete(class.pending, tenant)
+		}
+		return item
+	}
+	return nil
+}
+
+// removeLocked drops a run that has not been dispatched yet
+func (q *RunQueue) removeLocked(item *queuedRun) bool {
+	class := &q.classes[item.req.Priority]
+	items := class.pending[item.req.Tenant]
+	for i, it := range items {
+		if it != item {
+			continue
+		}
+		items = append(items[:i:i], items[i+1:]...)
+		if len(items) > 0 {
+			class.pending[item.req.Tenant] = items
+			return true
+		}
+		delete(class.pending, item.req.Tenant)
+		for j, tenant := range class.tenants {
+			if tenant == item.req.Tenant {
+				class.tenants = append(class.tenants[:j:j], class.tenants[j+1:]...)
+				break
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (q *RunQueue) execute(item *queuedRun) {
+	item.run, item.err = q.client.CreateRun(item.ctx, item.req.ThreadID, item.req.Params, item.req.Include)
+	close(item.done)
+
+	q.mu.Lock()
+	q.running--
+	q.dispatchLocked()
+	q.mu.Unlock()
+}
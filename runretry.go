@@ -0,0 +1,204 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// RunRetryPolicy controls how RunWithRetry retries failed runs
+type RunRetryPolicy struct {
+	MaxAttempts  int           // Runs created in total, fallback included; 3 when 0
+	PollInterval time.Duration // Interval of WaitForRun, 1s when 0
+	Backoff      time.Duration // Wait before the first retry, doubled on each retry
+
+	// Retryable reports whether a failed run is worth retrying. By default,
+	// runs failing with a server error or a rate limit are retried, as well
+	// as context length failures when CompactAfter allows a fallback.
+	Retryable func(run *Run) bool
+
+	// CompactAfter is the number of context length failures after which the
+	// next attempt runs on a compacted clone of the thread, 0 to never fall
+	// back. The clone holds a summary of the older messages followed by the
+	// KeepMessages latest ones, 10 when 0. Only text content is carried over.
+	CompactAfter int
+	KeepMessages int
+	SummaryModel string // Model summarizing the older messages, gpt-4o-mini when empty
+}
+
+// RunAttempt is a run created by RunWithRetry
+type RunAttempt struct {
+	RunID     string    `json:"run_id"`
+	ThreadID  string    `json:"thread_id"`
+	Status    string    `json:"status"`
+	Error     *RunError `json:"error,omitempty"`
+	Reason    string    `json:"reason,omitempty"`    // Why the run was retried, empty for the last attempt
+	Compacted bool      `json:"compacted,omitempty"` // Whether the run was made on the compacted clone
+}
+
+// RunReport is the outcome of RunWithRetry
+type RunReport struct {
+	Run      *Run         `json:"run"`       // Last run, completed or not
+	ThreadID string       `json:"thread_id"` // Thread of the last run, the compacted clone after a fallback
+	Attempts []RunAttempt `json:"attempts"`
+}
+
+// Reasons of RunAttempt
+const (
+	RunRetryFailed        = "failed"         // The run failed with a retryable error
+	RunRetryContextLength = "context_length" // The prompt exceeded the context window of the model
+)
+
+// RunWithRetry creates a run on the thread and waits for it, creating a new
+// run when it fails with a retryable error. Runs requiring action are
+// returned as is for the caller to submit the tool outputs. The report lists
+// the runs made and why they were retried; the error is only set when a call
+// to the API fails.
+func (c *Client) RunWithRetry(ctx context.Context, threadID string, params *CreateRunParams, policy RunRetryPolicy) (*RunReport, error) {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = func(run *Run) bool {
+			return defaultRunRetryable(run) || (policy.CompactAfter > 0 && isContextLengthFailure(run))
+		}
+	}
+
+	report := &RunReport{ThreadID: threadID}
+	compacted := false
+	contextFailures := 0
+	for attempt := 0; ; attempt++ {
+		created, err := c.CreateRun(ctx, report.ThreadID, params, nil)
+		if err != nil {
+			return report, err
+		}
+		run, err := c.WaitForRun(ctx, report.ThreadID, created.ID, policy.PollInterval)
+		if err != nil {
+			return report, err
+		}
+		report.Run = run
+		report.Attempts = append(report.Attempts, RunAttempt{
+			RunID:     run.ID,
+			ThreadID:  report.ThreadID,
+			Status:    run.Status,
+			Error:     run.LastError,
+			Compacted: compacted,
+		})
+
+		if run.Status == "completed" || run.Status == "requires_action" || attempt+1 >= maxAttempts || !retryable(run) {
+			return report, nil
+		}
+
+		last := &report.Attempts[len(report.Attempts)-1]
+		last.Reason = RunRetryFailed
+		if isContextLengthFailure(run) {
+			last.Reason = RunRetryContextLength
+			contextFailures++
+		}
+		c.logger.WarnContext(ctx, "retrying run", "thread_id", report.ThreadID, "run_id", run.ID, "status", run.Status, "reason", last.Reason)
+
+		if !compacted && policy.CompactAfter > 0 && contextFailures >= policy.CompactAfter {
+			clone, err := c.CompactThread(ctx, report.ThreadID, policy.KeepMessages, policy.SummaryModel)
+			if err != nil {
+				return report, err
+			}
+			report.ThreadID = clone.ID
+			compacted = true
+			continue
+		}
+
+		if policy.Backoff > 0 {
+			if err := c.clock.Sleep(ctx, policy.Backoff<<attempt); err != nil {
+				return report, err
+			}
+		}
+	}
+}
+
+// CompactThread creates a new thread holding a summary of the older messages
+// of a thread followed by its keep latest messages, 10 when 0, so a
+// conversation outgrowing the context window of the model can go on. Only
+// text content is carried over. model summarizes the messages, gpt-4o-mini
+// when empty.
+func (c *Client) CompactThread(ctx context.Context, threadID string, keep int, model string) (*Thread, error) {
+	if keep <= 0 {
+		keep = 10
+	}
+	if model == "" {
+		model = openai.GPT4oMini
+	}
+
+	snap, err := c.SnapshotMessages(ctx, threadID)
+	if err != nil {
+		return nil, err
+	}
+	var messages []ThreadMessage
+	for _, msg := range snap.Items {
+		if text := messageText(msg); text != "" {
+			messages = append(messages, ThreadMessage{Role: msg.Role, Content: text})
+		}
+	}
+
+	params := &CreateThreadParams{}
+	older := messages[:max(len(messages)-keep, 0)]
+	if len(older) > 0 {
+		var transcript strings.Builder
+		for _, msg := range older {
+			fmt.Fprintf(&transcript, "%s: %s\n", msg.Role, msg.Content)
+		}
+		resp, err := c.createChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model: model,
+			Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: "Summarize the conversation below so it can be continued without it. " +
+					"Keep the facts, decisions, open questions and anything the user asked to remember. Be concise."},
+				{Role: openai.ChatMessageRoleUser, Content: transcript.String()},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error summarizing thread: %w", sdkError(err))
+		}
+		if len(resp.Choices) == 0 {
+			return nil, fmt.Errorf("no thread summary returned")
+		}
+		params.Messages = append(params.Messages, ThreadMessage{
+			Role:    "user",
+			Content: "Summary of the earlier conversation:\n\n" + resp.Choices[0].Message.Content,
+		})
+	}
+	params.Messages = append(params.Messages, messages[len(older):]...)
+
+	thread, err := c.CreateThread(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.InfoContext(ctx, "thread compacted", "thread_id", threadID, "clone_id", thread.ID, "summarized", len(older), "kept", len(messages)-len(older))
+	return thread, nil
+}
+
+// defaultRunRetryable reports whether a run failed with a transient error
+func defaultRunRetryable(run *Run) bool {
+	if run.Status != "failed" || run.LastError == nil {
+		return false
+	}
+	return run.LastError.Code == "server_error" || run.LastError.Code == "rate_limit_exceeded"
+}
+
+// isContextLengthFailure reports whether a run ended because its prompt did
+// not fit in the context window of the model
+func isContextLengthFailure(run *Run) bool {
+	if run.Status == "incomplete" && run.IncompleteDetails != nil {
+		return run.IncompleteDetails.Reason == "max_prompt_tokens"
+	}
+	if run.LastError == nil {
+		return false
+	}
+	message := strings.ToLower(run.LastError.Message)
+	return run.LastError.Code == "context_length_exceeded" ||
+		strings.Contains(message, "context length") ||
+		strings.Contains(message, "context window")
+}
@@ -0,0 +1,142 @@
+package openai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/url"
+	"strings"
+)
+
+// RunStreamEvent is a server-sent event of a streamed run, e.g.
+// "thread.run.created", "thread.message.delta" or "thread.run.completed"
+type RunStreamEvent struct {
+	Event string
+	Data  json.RawMessage
+}
+
+// Run decodes the run of "thread.run.*" events
+func (e RunStreamEvent) Run() (*Run, bool) {
+	if !strings.HasPrefix(e.Event, "thread.run.") || strings.HasPrefix(e.Event, "thread.run.step.") {
+		return nil, false
+	}
+	var run Run
+	if err := json.Unmarshal(e.Data, &run); err != nil {
+		return nil, false
+	}
+	return &run, true
+}
+
+// Text returns the text added by a "thread.message.delta" event
+func (e RunStreamEvent) Text() string {
+	if e.Event != "thread.message.delta" {
+		return ""
+	}
+	var delta struct {
+		Delta struct {
+			Content []struct {
+				Type string `json:"type"`
+				Text struct {
+					Value string `json:"value"`
+				} `json:"text"`
+			} `json:"content"`
+		} `json:"delta"`
+	}
+	if err := json.Unmarshal(e.Data, &delta); err != nil {
+		return ""
+	}
+	var text strings.Builder
+	for _, content := range delta.Delta.Content {
+		if content.Type == "text" {
+			text.WriteString(content.Text.Value)
+		}
+	}
+	return text.String()
+}
+
+// StreamRun creates a run like CreateRun and iterates over its events as the
+// API streams them, until the run ends or requires action. Stopping the
+// iteration or cancelling ctx closes the stream. Usage is recorded when the
+// run ends.
+func (c *Client) StreamRun(ctx context.Context, threadID string, params *CreateRunParams, include []string) iter.Seq2[RunStreamEvent, error] {
+	return func(yield func(RunStreamEvent, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		path := "/threads/" + threadID + "/runs"
+		if len(include) > 0 {
+			path += "?" + url.Values{"include": include}.Encode()
+		}
+		req, err := c.newRequest(ctx, "POST", path, c.routeRunModel(ctx, params))
+		if err == nil {
+			err = rewriteJSONBody(req, func(fields map[string]json.RawMessage) error {
+				fields["stream"] = json.RawMessage("true")
+				return nil
+			})
+		}
+		if err != nil {
+			yield(RunStreamEvent{}, err)
+			return
+		}
+		req.Header.Set("Accept", "text/event-stream")
+
+		resp, err := c.send(req)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				yield(RunStreamEvent{}, ctxErr)
+				return
+			}
+			yield(RunStreamEvent{}, fmt.Errorf("run stream failed: %w", err))
+			return
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			yield(RunStreamEvent{}, fmt.Errorf("run stream failed: %w", c.responseError(resp)))
+			return
+		}
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 16<<20)
+		var event RunStreamEvent
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event:"):
+				event.Event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+				continue
+			case strings.HasPrefix(line, "data:"):
+				event.Data = append(event.Data, strings.TrimSpace(strings.TrimPrefix(line, "data:"))...)
+				continue
+			case line != "" || event.Event == "":
+				continue
+			}
+
+			// A blank line ends the event
+			current := event
+			event = RunStreamEvent{}
+			switch current.Event {
+			case "done":
+				return
+			case "error":
+				yield(current, fmt.Errorf("run stream failed: %s", current.Data))
+				return
+			}
+			if run, ok := current.Run(); ok {
+				c.recordRunUsage(ctx, run)
+			}
+			if !yield(current, nil) {
+				return
+			}
+		}
+
+		if err := scanner.Err(); err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				yield(RunStreamEvent{}, ctxErr)
+				return
+			}
+			yield(RunStreamEvent{}, fmt.Errorf("failed to read run stream: %w", err))
+		}
+	}
+}
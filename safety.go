@@ -0,0 +1,189 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// ErrOutputVetoed is wrapped by the errors of calls whose output was rejected by
+// the safety policy
+var ErrOutputVetoed = errors.New("output vetoed by safety policy")
+
+// ContentFilterResult is the verdict of a content filter for one category
+type ContentFilterResult struct {
+	Filtered bool   `json:"filtered"`
+	Severity string `json:"severity,omitempty"` // "safe", "low", "medium" or "high"
+	Detected bool   `json:"detected,omitempty"` // For the detection categories, e.g. jailbreak
+}
+
+// ContentFilters holds the content filter results returned by Azure OpenAI, by
+// category, e.g. "hate", "self_harm", "sexual", "violence" or "jailbreak"
+type ContentFilters map[string]ContentFilterResult
+
+// Flagged reports whether a category was filtered or detected
+func (f ContentFilters) Flagged() bool {
+	for _, result := range f {
+		if result.Filtered || result.Detected {
+			return true
+		}
+	}
+	return false
+}
+
+// SafetyReport describes the safety attributes of an output before it is
+// returned to the caller
+type SafetyReport struct {
+	Endpoint       string // EndpointChat or EndpointImages
+	Model          string
+	Index          int            // Index of the choice or image
+	Content        string         // Text of the choice, empty for images
+	RevisedPrompt  string         // Prompt actually used to generate an image
+	PromptFilters  ContentFilters // Results of the filters applied to the prompt
+	ContentFilters ContentFilters // Results of the filters applied to the output
+}
+
+// WithSafetyPolicy calls policy with the report of each chat choice and
+// generated image. A non-nil error vetoes the output: the call fails with an
+// error wrapping ErrOutputVetoed and the policy error.
+func WithSafetyPolicy(policy func(ctx context.Context, report *SafetyReport) error) Option {
+	return func(c *Client) {
+		c.safetyPolicy = policy
+	}
+}
+
+// checkSafety applies the safety policy to a report
+func (c *Client) checkSafety(ctx context.Context, report *SafetyReport) error {
+	if err := c.safetyPolicy(ctx, report); err != nil {
+		c.logger.WarnContext(ctx, "output vetoed by safety policy", "endpoint", report.Endpoint, "model", report.Model, "error", err)
+		return fmt.Errorf("%w: %w", ErrOutputVetoed, err)
+	}
+	return nil
+}
+
+// checkChatSafety applies the safety policy to the choices of a chat completion
+func (c *Client) checkChatSafety(ctx context.Context, resp openai.ChatCompletionResponse) error {
+	if c.safetyPolicy == nil {
+		return nil
+	}
+
+	var promptFilters ContentFilters
+	for _, result := range resp.PromptFilterResults {
+		for category, filter := range sdkContentFilters(result.ContentFilterResults) {
+			if promptFilters == nil {
+				promptFilters = ContentFilters{}
+			}
+			promptFilters[category] = filter
+		}
+	}
+	for _, choice := range resp.Choices {
+		report := &SafetyReport{
+			Endpoint:       EndpointChat,
+			Model:          resp.Model,
+			Index:          choice.Index,
+			Content:        choice.Message.Content,
+			PromptFilters:  promptFilters,
+			ContentFilters: sdkContentFilters(choice.ContentFilterResults),
+		}
+		if err := c.checkSafety(ctx, report); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sdkContentFilters converts the content filter results of the go-openai
+// client, keeping the categories that were evaluated
+func sdkContentFilters(r openai.ContentFilterResults) ContentFilters {
+	filters := ContentFilters{}
+	add := func(category string, result ContentFilterResult) {
+		if result != (ContentFilterResult{}) {
+			filters[category] = result
+		}
+	}
+	add("hate", ContentFilterResult{Filtered: r.Hate.Filtered, Severity: r.Hate.Severity})
+	add("self_harm", ContentFilterResult{Filtered: r.SelfHarm.Filtered, Severity: r.SelfHarm.Severity})
+	add("sexual", ContentFilterResult{Filtered: r.Sexual.Filtered, Severity: r.Sexual.Severity})
+	add("violence", ContentFilterResult{Filtered: r.Violence.Filtered, Severity: r.Violence.Severity})
+	add("jailbreak", ContentFilterResult{Filtered: r.JailBreak.Filtered, Detected: r.JailBreak.Detected})
+	add("profanity", ContentFilterResult{Filtered: r.Profanity.Filtered, Detected: r.Profanity.Detected})
+	if len(filters) == 0 {
+		return nil
+	}
+	return filters
+}
+
+// ImageParams are the parameters of GenerateImage
+type ImageParams struct {
+	Model          string `json:"model,omitempty"` // e.g. "dall-e-3"
+	Prompt         string `json:"prompt"`
+	N              int    `json:"n,omitempty"`
+	Size           string `json:"size,omitempty"`            // e.g. "1024x1024"
+	Quality        string `json:"quality,omitempty"`         // e.g. "hd"
+	Style          string `json:"style,omitempty"`           // e.g. "natural"
+	ResponseFormat string `json:"response_format,omitempty"` // "url" or "b64_json"
+}
+
+// Image is a generated image
+type Image struct {
+	URL           string `json:"url,omitempty"`
+	B64JSON       string `json:"b64_json,omitempty"`
+	RevisedPrompt string `json:"revised_prompt,omitempty"` // Prompt rewritten by the model, when it did
+
+	ContentFilters ContentFilters `json:"content_filter_results,omitempty"` // Azure only
+	PromptFilters  ContentFilters `json:"prompt_filter_results,omitempty"`  // Azure only
+}
+
+// GenerateImage creates images from a prompt. Each image is checked by the
+// safety policy, if any, before being returned.
+func (c *Client) GenerateImage(ctx context.Context, params *ImageParams) ([]Image, error) {
+	var resp struct {
+		Data []Image `json:"data"`
+	}
+	if err := c.do(ctx, "POST", c.modelPath(params.Model, "/images/generations"), params, &resp); err != nil {
+		return nil, fmt.Errorf("image generation failed: %w", err)
+	}
+
+	if c.safetyPolicy != nil {
+		for i, image := range resp.Data {
+			report := &SafetyReport{
+				Endpoint:       EndpointImages,
+				Model:          params.Model,
+				Index:          i,
+				RevisedPrompt:  image.RevisedPrompt,
+				PromptFilters:  image.PromptFilters,
+				ContentFilters: image.ContentFilters,
+			}
+			if err := c.checkSafety(ctx, report); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return resp.Data, nil
+}
+
+// ModerationResult is the verdict of the moderation endpoint for one input
+type ModerationResult struct {
+	Flagged        bool               `json:"flagged"`
+	Categories     map[string]bool    `json:"categories"`      // e.g. "harassment" or "self-harm/intent"
+	CategoryScores map[string]float64 `json:"category_scores"` // Confidence from 0 to 1, by category
+}
+
+// Moderate classifies text with the moderation endpoint, e.g. to check a chat
+// answer from a safety policy. model may be empty for the API default.
+func (c *Client) Moderate(ctx context.Context, model string, inputs ...string) ([]ModerationResult, error) {
+	body := map[string]interface{}{"input": inputs}
+	if model != "" {
+		body["model"] = model
+	}
+
+	var resp struct {
+		Results []ModerationResult `json:"results"`
+	}
+	if err := c.do(ctx, "POST", "/moderations", body, &resp); err != nil {
+		return nil, fmt.Errorf("moderation failed: %w", err)
+	}
+	return resp.Results, nil
+}
@@ -0,0 +1,124 @@
+package openai
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SearchCache caches vector store search results for a limited time, so
+// repeated queries such as FAQ lookups skip the API. Entries are keyed by
+// vector store, normalized query (case and whitespace insensitive) and the
+// other search parameters. It is safe for concurrent use.
+//
+// Attaching files to or removing files from a vector store through the client
+// invalidates its entries; call Invalidate after changing a store by other means.
+type SearchCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]searchCacheEntry
+}
+
+type searchCacheEntry struct {
+	vectorStoreID string
+	results       []VectorStoreSearchResult
+	expiresAt     time.Time
+}
+
+// NewSearchCache returns a cache keeping results for ttl, holding at most
+// maxEntries results (0 for no limit)
+func NewSearchCache(ttl time.Duration, maxEntries int) *SearchCache {
+	return &SearchCache{ttl: ttl, maxEntries: maxEntries, entries: map[string]searchCacheEntry{}}
+}
+
+// WithSearchCache serves SearchVectorStore from the cache when possible
+func WithSearchCache(cache *SearchCache) Option {
+	return func(c *Client) {
+		c.searchCache = cache
+	}
+}
+
+// Invalidate drops the cached results of a vector store
+func (sc *SearchCache) Invalidate(vectorStoreID string) {
+	if sc == nil {
+		return
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for key, entry := range sc.entries {
+		if entry.vectorStoreID == vectorStoreID {
+			delete(sc.entries, key)
+		}
+	}
+}
+
+// Clear drops all cached results
+func (sc *SearchCache) Clear() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.entries = map[string]searchCacheEntry{}
+}
+
+// searchCacheKey identifies a search. It returns false when the parameters
+// cannot be encoded, in which case the search is not cached.
+func searchCacheKey(vectorStoreID string, params *SearchVectorStoreParams) (string, bool) {
+	normalized := *params
+	normalized.Query = strings.Join(strings.Fields(strings.ToLower(params.Query)), " ")
+
+	// Maps are encoded with sorted keys, so equal filters give equal keys
+	encoded, err := json.Marshal(normalized)
+	if err != nil {
+		return "", false
+	}
+	return vectorStoreID + "\x00" + string(encoded), true
+}
+
+func (sc *SearchCache) get(key string, now time.Time) ([]VectorStoreSearchResult, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	entry, ok := sc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(entry.expiresAt) {
+		delete(sc.entries, key)
+		return nil, false
+	}
+	return append([]VectorStoreSearchResult(nil), entry.results...), true
+}
+
+func (sc *SearchCache) put(key, vectorStoreID string, results []VectorStoreSearchResult, now time.Time) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if sc.maxEntries > 0 && len(sc.entries) >= sc.maxEntries {
+		sc.evictLocked(now)
+	}
+	sc.entries[key] = searchCacheEntry{
+		vectorStoreID: vectorStoreID,
+		results:       append([]VectorStoreSearchResult(nil), results...),
+		expiresAt:     now.Add(sc.ttl),
+	}
+}
+
+// evictLocked drops the expired entries, or the entry expiring first when none is
+func (sc *SearchCache) evictLocked(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range sc.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(sc.entries, key)
+			continue
+		}
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, entry.expiresAt
+		}
+	}
+	if len(sc.entries) >= sc.maxEntries {
+		delete(sc.entries, oldestKey)
+	}
+}
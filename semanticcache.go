@@ -0,0 +1,234 @@
+package openai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// SemanticCache answers chat completions whose last user message is close in
+// meaning to one answered before, e.g. rephrasings of the same search query.
+// Prompts are embedded and compared by cosine similarity; only requests
+// sharing the model, the earlier messages, every other parameter, the tenant
+// and the cache scope (see WithCacheScope) are compared. Requests streaming,
+// asking for several choices, offering tools or sending images are never
+// cached.
+//
+// Unlike ChatCache, answers are reused whatever the temperature, so a cached
+// answer is one sample of what the model could say. Cached responses do not
+// count as usage, but the embedding of each prompt does. It is safe for
+// concurrent use.
+type SemanticCache struct {
+	Model openai.EmbeddingModel // Model embedding the prompts, text-embedding-3-small when empty
+
+	threshold  float64
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries []semanticEntry
+}
+
+type semanticEntry struct {
+	scope     string
+	prompt    string
+	embedding []float32
+	response  []byte
+	expiresAt time.Time
+}
+
+// SemanticCacheHit reports that a response was served by the semantic cache,
+// see ReportSemanticCacheHit
+type SemanticCacheHit struct {
+	Hit        bool
+	Prompt     string  // Prompt of the cached answer
+	Similarity float64 // Cosine similarity between the prompts
+}
+
+// NewSemanticCache returns a cache answering prompts at least threshold
+// similar to a cached one, 0.95 when 0. Answers are kept for ttl, and at most
+// maxEntries are kept (0 for no limit), the oldest being dropped first.
+func NewSemanticCache(threshold float64, ttl time.Duration, maxEntries int) *SemanticCache {
+	if threshold == 0 {
+		threshold = 0.95
+	}
+	return &SemanticCache{threshold: threshold, ttl: ttl, maxEntries: maxEntries}
+}
+
+// WithSemanticCache serves the chat completions of ExpandQuery from the
+// semantic cache when possible. Exact matches are still served by the
+// ChatCache first. Helpers whose answers depend on private content, such as
+// thread summaries, memory extraction or eval grading, never use it, as a
+// similar prompt could be answered with another user's data.
+func WithSemanticCache(cache *SemanticCache) Option {
+	return func(c *Client) {
+		c.semanticCache = cache
+	}
+}
+
+// WithCacheScope partitions the semantic cache: calls only share answers with
+// calls of the same scope, e.g. the ID of the end user. The clients of a
+// TenantManager are partitioned by tenant already.
+func WithCacheScope(scope string) RequestOption {
+	return func(config *requestConfig) {
+		config.cacheScope = scope
+	}
+}
+
+// WithSemanticThreshold sets the similarity a cached prompt needs to answer
+// the call, overriding the threshold of the cache. A threshold above 1 never
+// matches, so the call always reaches the model; its answer is still cached.
+func WithSemanticThreshold(threshold float64) RequestOption {
+	return func(config *requestConfig) {
+		config.semanticThreshold = threshold
+	}
+}
+
+// ReportSemanticCacheHit makes the calls set hit when their response comes
+// from the semantic cache
+func ReportSemanticCacheHit(hit *SemanticCacheHit) RequestOption {
+	return func(config *requestConfig) {
+		config.semanticHit = hit
+	}
+}
+
+// semanticCacheKey returns the scope and prompt of a request. It returns false
+// when the request is not cacheable.
+func (c *Client) semanticCacheKey(ctx context.Context, req openai.ChatCompletionRequest) (scope, prompt string, ok bool) {
+	if req.Stream || req.N > 1 || len(req.Tools) > 0 || len(req.Functions) > 0 || len(req.Messages) == 0 {
+		return "", "", false
+	}
+	last := req.Messages[len(req.Messages)-1]
+	if last.Role != openai.ChatMessageRoleUser {
+		return "", "", false
+	}
+	prompt = last.Content
+	for _, part := range last.MultiContent {
+		if part.Type != openai.ChatMessagePartTypeText {
+			return "", "", false
+		}
+		prompt += part.Text
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return "", "", false
+	}
+
+	req.Messages = req.Messages[:len(req.Messages)-1]
+	encoded, err := json.Marshal(req)
+	if err != nil {
+		return "", "", false
+	}
+	partition := c.baseURL + "\x00" + c.tenant + "\x00"
+	if config, ok := ctx.Value(requestOptionsKey{}).(*requestConfig); ok {
+		partition += config.cacheScope
+	}
+	sum := sha256.Sum256(append([]byte(partition+"\x00"), encoded...))
+	return hex.EncodeToString(sum[:]), prompt, true
+}
+
+// lookup returns the cached response of the prompt most similar to embedding
+// within scope, if similar enough
+func (sc *SemanticCache) lookup(scope string, embedding []float32, threshold float64) (openai.ChatCompletionResponse, SemanticCacheHit, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	now := time.Now()
+	var best *semanticEntry
+	hit := SemanticCacheHit{}
+	for i := range sc.entries {
+		entry := &sc.entries[i]
+		if entry.scope != scope || (sc.ttl > 0 && !now.Before(entry.expiresAt)) {
+			continue
+		}
+		if similarity := cosineSimilarity(embedding, entry.embedding); similarity >= threshold && similarity > hit.Similarity {
+			best, hit = entry, SemanticCacheHit{Hit: true, Prompt: entry.prompt, Similarity: similarity}
+		}
+	}
+
+	var resp openai.ChatCompletionResponse
+	if best == nil || json.Unmarshal(best.response, &resp) != nil {
+		return resp, SemanticCacheHit{}, false
+	}
+	return resp, hit, true
+}
+
+func (sc *SemanticCache) put(scope, prompt string, embedding []float32, resp openai.ChatCompletionResponse) error {
+	value, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	now := time.Now()
+	if sc.ttl > 0 {
+		kept := sc.entries[:0]
+		for _, entry := range sc.entries {
+			if now.Before(entry.expiresAt) {
+				kept = append(kept, entry)
+			}
+		}
+		sc.entries = kept
+	}
+	if sc.maxEntries > 0 && len(sc.entries) >= sc.maxEntries {
+		sc.entries = append(sc.entries[:0], sc.entries[len(sc.entries)-sc.maxEntries+1:]...)
+	}
+	sc.entries = append(sc.entries, semanticEntry{
+		scope:     scope,
+		prompt:    prompt,
+		embedding: embedding,
+		response:  value,
+		expiresAt: now.Add(sc.ttl),
+	})
+	return nil
+}
+
+// semanticCached serves a request from the semantic cache. On a miss, it
+// returns a function storing the response of the request, nil when the
+// request is not cacheable.
+func (c *Client) semanticCached(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, bool, func(openai.ChatCompletionResponse)) {
+	var resp openai.ChatCompletionResponse
+	scope, prompt, ok := c.semanticCacheKey(ctx, req)
+	if !ok {
+		return resp, false, nil
+	}
+
+	cache := c.semanticCache
+	threshold := cache.threshold
+	config, _ := ctx.Value(requestOptionsKey{}).(*requestConfig)
+	if config != nil && config.semanticThreshold != 0 {
+		threshold = config.semanticThreshold
+	}
+	model := cache.Model
+	if model == "" {
+		model = memoryEmbeddingModel
+	}
+
+	vectors, err := c.embedTexts(ctx, []string{prompt}, model)
+	if err != nil {
+		c.logger.WarnContext(ctx, "failed to embed prompt for semantic cache", "error", err)
+		return resp, false, nil
+	}
+	embedding := vectors[0]
+
+	resp, hit, ok := cache.lookup(scope, embedding, threshold)
+	if ok {
+		if config != nil && config.semanticHit != nil {
+			*config.semanticHit = hit
+		}
+		c.logger.DebugContext(ctx, "chat completion served by semantic cache", "similarity", hit.Similarity)
+		return resp, true, nil
+	}
+	return resp, false, func(resp openai.ChatCompletionResponse) {
+		if err := cache.put(scope, prompt, embedding, resp); err != nil {
+			c.logger.WarnContext(ctx, "failed to write semantic cache", "error", err)
+		}
+	}
+}
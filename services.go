@@ -0,0 +1,74 @@
+package openai
+
+import (
+	"context"
+	"time"
+)
+
+// The services below group the methods of Client by resource, so code that
+// orchestrates runs and uploads can depend on the subset it uses and be tested
+// with mocks, e.g. generated with mockgen or mockery. Client implements them all.
+
+// AssistantsService manages assistants
+type AssistantsService interface {
+	ListAssistants(ctx context.Context, opts ...ListOption) ([]Assistant, error)
+	RetrieveAssistant(ctx context.Context, assistantID string) (*Assistant, error)
+	CreateAssistant(ctx context.Context, params *CreateAssistantParams) (*Assistant, error)
+	ModifyAssistant(ctx context.Context, assistantID string, params *CreateAssistantParams) error
+	DeleteAssistant(ctx context.Context, assistantID string) error
+	AttachVectorStore(ctx context.Context, assistantID, vectorStoreID string) (*Assistant, error)
+	DetachVectorStore(ctx context.Context, assistantID, vectorStoreID string) (*Assistant, error)
+	AttachCodeInterpreterFile(ctx context.Context, assistantID, fileID string) (*Assistant, error)
+	DetachCodeInterpreterFile(ctx context.Context, assistantID, fileID string) (*Assistant, error)
+}
+
+// ThreadsService manages threads and their messages
+type ThreadsService interface {
+	CreateThread(ctx context.Context, params *CreateThreadParams) (*Thread, error)
+	RetrieveThread(ctx context.Context, threadID string) (*Thread, error)
+	ModifyThread(ctx context.Context, threadID string, params *ModifyThreadParams) (*Thread, error)
+	DeleteThread(ctx context.Context, threadID string) (*DeletionStatus, error)
+	CreateMessage(ctx context.Context, params *CreateMessageParams) (*Message, error)
+	ListMessages(ctx context.Context, threadID string, opts ...ListOption) ([]Message, error)
+}
+
+// RunsService manages the runs of threads
+type RunsService interface {
+	CreateRun(ctx context.Context, threadID string, params *CreateRunParams, include []string) (*Run, error)
+	RetrieveRun(ctx context.Context, threadID, runID string) (*Run, error)
+	ListRuns(ctx context.Context, threadID string, opts ...ListOption) ([]Run, error)
+	SubmitToolOutputs(ctx context.Context, threadID, runID string, outputs []ToolOutput) (*Run, error)
+	WaitForRun(ctx context.Context, threadID, runID string, interval time.Duration) (*Run, error)
+	ListRunSteps(ctx context.Context, threadID, runID string, opts ...ListOption) ([]RunStep, error)
+}
+
+// FilesService manages uploaded files
+type FilesService interface {
+	UploadFile(ctx context.Context, path string) (string, error)
+	UploadContent(ctx context.Context, path string, content []byte) (string, error)
+	ListFiles(ctx context.Context, opts ...ListOption) ([]File, error)
+	RetrieveFile(ctx context.Context, fileID string) (*File, error)
+	DeleteFile(ctx context.Context, fileID string) error
+}
+
+// VectorStoresService manages vector stores and their files
+type VectorStoresService interface {
+	CreateVectorStore(ctx context.Context, params *CreateVectorStoreParams) (*VectorStore, error)
+	ListVectorStores(ctx context.Context, opts ...ListOption) ([]VectorStore, error)
+	RetrieveVectorStore(ctx context.Context, vectorStoreID string) (*VectorStore, error)
+	ModifyVectorStore(ctx context.Context, vectorStoreID string, params *ModifyVectorStoreParams) (*VectorStore, error)
+	DeleteVectorStore(ctx context.Context, vectorStoreID string) error
+	SearchVectorStore(ctx context.Context, vectorStoreID string, params *SearchVectorStoreParams) ([]VectorStoreSearchResult, error)
+	CreateVectorStoreFile(ctx context.Context, vectorStoreID, fileID string, chunkingStrategy map[string]interface{}) (*VectorStoreFile, error)
+	ListVectorStoreFiles(ctx context.Context, vectorStoreID string, opts ...ListOption) ([]VectorStoreFile, error)
+	RetrieveVectorStoreFile(ctx context.Context, vectorStoreID, fileID string) (*VectorStoreFile, error)
+	DeleteVectorStoreFile(ctx context.Context, vectorStoreID, fileID string) error
+}
+
+var (
+	_ AssistantsService   = (*Client)(nil)
+	_ ThreadsService      = (*Client)(nil)
+	_ RunsService         = (*Client)(nil)
+	_ FilesService        = (*Client)(nil)
+	_ VectorStoresService = (*Client)(nil)
+)
@@ -0,0 +1,121 @@
+package openai
+
+import "context"
+
+// snapshotPageSize is the page size used when walking list endpoints
+const snapshotPageSize = 100
+
+// Snapshot is a consistent copy of a list endpoint. Items are ordered from
+// oldest to newest and Cursor is the ID of the newest item, to be passed to the
+// matching Since function to fetch only what was created afterwards.
+type Snapshot[T any] struct {
+	Items  []T
+	Cursor string
+}
+
+// snapshotAll pages through a list endpoint in ascending order starting after
+// the given cursor, until a page reports it has no more items. Items seen twice
+// (e.g. when the list changes while paging) are only kept once.
+func snapshotAll[T any](after string, fetch func(opts ...ListOption) (*Page[T], error), id func(T) string) (*Snapshot[T], error) {
+	snap := &Snapshot[T]{Cursor: after}
+	seen := map[string]bool{}
+
+	for {
+		opts := []ListOption{WithLimit(snapshotPageSize), WithOrder("asc")}
+		if snap.Cursor != "" {
+			opts = append(opts, WithAfter(snap.Cursor))
+		}
+		page, err := fetch(opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range page.Data {
+			itemID := id(item)
+			if seen[itemID] {
+				continue
+			}
+			seen[itemID] = true
+			snap.Items = append(snap.Items, item)
+		}
+		if len(page.Data) == 0 {
+			return snap, nil
+		}
+		snap.Cursor = id(page.Data[len(page.Data)-1])
+		if !page.HasMore {
+			return snap, nil
+		}
+	}
+}
+
+// SnapshotVectorStores returns all vector stores
+func (c *Client) SnapshotVectorStores(ctx context.Context) (*Snapshot[VectorStore], error) {
+	return c.VectorStoresSince(ctx, "")
+}
+
+// VectorStoresSince returns the vector stores created after the given cursor
+func (c *Client) VectorStoresSince(ctx context.Context, cursor string) (*Snapshot[VectorStore], error) {
+	return snapshotAll(cursor, func(opts ...ListOption) (*Page[VectorStore], error) {
+		return c.ListVectorStoresPage(ctx, opts...)
+	}, func(vs VectorStore) string { return vs.ID })
+}
+
+// SnapshotVectorStoreFiles returns all files attached to a vector store
+func (c *Client) SnapshotVectorStoreFiles(ctx context.Context, vectorStoreID string) (*Snapshot[VectorStoreFile], error) {
+	return c.VectorStoreFilesSince(ctx, vectorStoreID, "")
+}
+
+// VectorStoreFilesSince returns the files attached to a vector store after the given cursor
+func (c *Client) VectorStoreFilesSince(ctx context.Context, vectorStoreID, cursor string) (*Snapshot[VectorStoreFile], error) {
+	return snapshotAll(cursor, func(opts ...ListOption) (*Page[VectorStoreFile], error) {
+		return c.ListVectorStoreFilesPage(ctx, vectorStoreID, opts...)
+	}, func(f VectorStoreFile) string { return f.ID })
+}
+
+// SnapshotMessages returns all messages of a thread
+func (c *Client) SnapshotMessages(ctx context.Context, threadID string) (*Snapshot[Message], error) {
+	return c.MessagesSince(ctx, threadID, "")
+}
+
+// MessagesSince returns the messages added to a thread after the given cursor
+func (c *Client) MessagesSince(ctx context.Context, threadID, cursor string) (*Snapshot[Message], error) {
+	return snapshotAll(cursor, func(opts ...ListOption) (*Page[Message], error) {
+		return c.ListMessagesPage(ctx, threadID, opts...)
+	}, func(m Message) string { return m.ID })
+}
+
+// SnapshotRuns returns all runs of a thread
+func (c *Client) SnapshotRuns(ctx context.Context, threadID string) (*Snapshot[Run], error) {
+	return c.RunsSince(ctx, threadID, "")
+}
+
+// RunsSince returns the runs created in a thread after the given cursor
+func (c *Client) RunsSince(ctx context.Context, threadID, cursor string) (*Snapshot[Run], error) {
+	return snapshotAll(cursor, func(opts ...ListOption) (*Page[Run], error) {
+		return c.ListRunsPage(ctx, threadID, opts...)
+	}, func(r Run) string { return r.ID })
+}
+
+// SnapshotFiles returns all uploaded files
+func (c *Client) SnapshotFiles(ctx context.Context) (*Snapshot[File], error) {
+	return c.FilesSince(ctx, "")
+}
+
+// FilesSince returns the files uploaded after the given cursor
+func (c *Client) FilesSince(ctx context.Context, cursor string) (*Snapshot[File], error) {
+	return snapshotAll(cursor, func(opts ...ListOption) (*Page[File], error) {
+		return c.ListFilesPage(ctx, opts...)
+	}, func(f File) string { return f.ID })
+}
+
+// SnapshotAssistants returns all assistants
+func (c *Client) SnapshotAssistants(ctx context.Context) (*Snapshot[Assistant], error) {
+	return c.AssistantsSince(ctx, "")
+}
+
+// AssistantsSince returns the assistants created after the given cursor
+func (c *Client) AssistantsSince(ctx context.Context, cursor string) (*Snapshot[Assistant], error) {
+	return snapshotAll(cursor, func(opts ...ListOption) (*Page[Assistant], error) {
+		return c.ListAssistantsPage(ctx, opts...)
+	}, func(a Assistant) string { return a.ID })
+}
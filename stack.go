@@ -0,0 +1,543 @@
+package openai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Metadata keys and vector store file attributes used to recognize the
+// resources managed by a stack
+const (
+	stackMetadataKey   = "stack"
+	stackResourceKey   = "stack_resource"
+	stackPathAttribute = "stack_path"
+	stackHashAttribute = "stack_sha256"
+)
+
+// StackConfig declares an assistant, its vector stores and the directories
+// synced into them. ApplyStack makes the live resources match it.
+type StackConfig struct {
+	// Name identifies the stack. It is stored in the metadata of every resource
+	// the stack manages.
+	Name         string             `json:"name"`
+	Assistant    StackAssistant     `json:"assistant"`
+	VectorStores []StackVectorStore `json:"vector_stores,omitempty"`
+}
+
+// StackAssistant declares the assistant of a stack
+type StackAssistant struct {
+	Name         string            `json:"name"`
+	Description  string            `json:"description,omitempty"`
+	Model        string            `json:"model"`
+	Instructions string            `json:"instructions,omitempty"`
+	Tools        []Tool            `json:"tools,omitempty"`
+	Temperature  *float64          `json:"temperature,omitempty"`
+	TopP         *float64          `json:"top_p,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+
+	// VectorStores lists the names of the stack vector stores searched by the
+	// assistant's file_search tool
+	VectorStores []string `json:"vector_stores,omitempty"`
+}
+
+// StackVectorStore declares a vector store and the files it must contain
+type StackVectorStore struct {
+	Name     string            `json:"name"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Sources  []StackSource     `json:"sources,omitempty"`
+}
+
+// StackSource is a local directory whose files are synced into a vector store.
+// Dir may also be a .zip, .tar.gz or .tgz archive, whose files are expanded in
+// memory and synced as if they were in a directory; archives expanding to
+// more than 10000 files or 1 GiB are rejected.
+type StackSource struct {
+	Dir string `json:"dir"`
+
+	// Include and Exclude are glob patterns (path.Match syntax) matched against
+	// the slash-separated path relative to Dir, and against the base name.
+	// All files are included when Include is empty.
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// StackState reports the live resources of a stack after ApplyStack
+type StackState struct {
+	AssistantID  string            `json:"assistant_id"`
+	VectorStores map[string]string `json:"vector_stores"` // Vector store IDs by name
+	Uploaded     []string          `json:"uploaded,omitempty"`
+	Removed      []string          `json:"removed,omitempty"`
+
+	// DeletedVectorStores lists the vector stores no longer in the config,
+	// deleted along with their files
+	DeletedVectorStores []string `json:"deleted_vector_stores,omitempty"`
+}
+
+// LoadStackConfig reads a stack configuration from a JSON file, or from a YAML
+// file when its extension is .yaml or .yml. YAML keys are the JSON ones.
+func LoadStackConfig(path string) (*StackConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stack config %s: %w", path, err)
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		if content, err = yamlToJSON(content); err != nil {
+			return nil, fmt.Errorf("failed to decode stack config %s: %w", path, err)
+		}
+	}
+
+	var config StackConfig
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode stack config %s: %w", path, err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid stack config %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+// yamlToJSON converts a YAML document to JSON, so it decodes with the json
+// tags of the config types
+func yamlToJSON(content []byte) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+func (config *StackConfig) validate() error {
+	if config.Name == "" {
+		return fmt.Errorf("stack name is required")
+	}
+	if config.Assistant.Name == "" || config.Assistant.Model == "" {
+		return fmt.Errorf("assistant name and model are required")
+	}
+
+	names := map[string]bool{}
+	for _, vs := range config.VectorStores {
+		if vs.Name == "" {
+			return fmt.Errorf("vector store name is required")
+		}
+		if names[vs.Name] {
+			return fmt.Errorf("duplicate vector store %q", vs.Name)
+		}
+		names[vs.Name] = true
+	}
+	for _, name := range config.Assistant.VectorStores {
+		if !names[name] {
+			return fmt.Errorf("assistant references unknown vector store %q", name)
+		}
+	}
+	return nil
+}
+
+// ApplyStack creates or updates the resources declared by the stack so they
+// match the config: vector stores are created when missing, their files are
+// synced with the source directories (new and changed files are uploaded,
+// files no longer present are removed), and the assistant is created or
+// updated to link them. Vector stores of the stack no longer in the config are
+// then deleted with their files. Applying the same config twice makes no
+// change. With WithIngestionObserver or WithProgress, it also waits for the
+// uploaded files to be processed.
+func (c *Client) ApplyStack(ctx context.Context, config *StackConfig) (*StackState, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	c.emitProgress(ProgressEvent{Event: "stack_apply_started", Name: config.Name, Total: len(config.VectorStores)})
+	state, err := c.applyStack(ctx, config)
+	c.emitProgress(progressOutcome("stack_apply", config.Name, err))
+	return state, err
+}
+
+func (c *Client) applyStack(ctx context.Context, config *StackConfig) (*StackState, error) {
+	state := &StackState{VectorStores: map[string]string{}}
+
+	stores, err := c.findStackVectorStores(ctx, config.Name)
+	if err != nil {
+		return nil, err
+	}
+	for i, decl := range config.VectorStores {
+		vs, ok := stores[decl.Name]
+		if !ok {
+			metadata := copyStringMap(decl.Metadata)
+			metadata[stackMetadataKey] = config.Name
+			metadata[stackResourceKey] = decl.Name
+			vs, err = c.CreateVectorStore(ctx, &CreateVectorStoreParams{Name: decl.Name, Metadata: metadata})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create vector store %q: %w", decl.Name, err)
+			}
+		}
+		state.VectorStores[decl.Name] = vs.ID
+
+		uploaded, removed, err := c.syncStackVectorStore(ctx, vs.ID, decl.Sources)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sync vector store %q: %w", decl.Name, err)
+		}
+		state.Uploaded = append(state.Uploaded, uploaded...)
+		state.Removed = append(state.Removed, removed...)
+		c.emitProgress(ProgressEvent{Event: "vector_store_synced", Name: decl.Name, ID: vs.ID, Done: i + 1, Total: len(config.VectorStores)})
+	}
+
+	params := config.assistantParams(state.VectorStores)
+	assistant, err := c.findStackAssistant(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if assistant == nil {
+		created, err := c.CreateAssistant(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create assistant %q: %w", config.Assistant.Name, err)
+		}
+		state.AssistantID = created.ID
+	} else {
+		state.AssistantID = assistant.ID
+		if err := c.ModifyAssistant(ctx, assistant.ID, params); err != nil {
+			return nil, fmt.Errorf("failed to update assistant %q: %w", config.Assistant.Name, err)
+		}
+	}
+	c.emitProgress(ProgressEvent{Event: "assistant_applied", Name: config.Assistant.Name, ID: state.AssistantID})
+
+	// The assistant no longer links the stores removed from the config
+	for _, name := range slices.Sorted(maps.Keys(stores)) {
+		if _, ok := state.VectorStores[name]; ok {
+			continue
+		}
+		if err := c.deleteStackVectorStore(ctx, name, stores[name]); err != nil {
+			return nil, err
+		}
+		state.DeletedVectorStores = append(state.DeletedVectorStores, name)
+	}
+	return state, nil
+}
+
+// DestroyStack deletes the assistant and the vector stores of the stack, along
+// with the files uploaded into them
+func (c *Client) DestroyStack(ctx context.Context, config *StackConfig) error {
+	c.emitProgress(ProgressEvent{Event: "stack_destroy_started", Name: config.Name})
+	err := c.destroyStack(ctx, config)
+	c.emitProgress(progressOutcome("stack_destroy", config.Name, err))
+	return err
+}
+
+func (c *Client) destroyStack(ctx context.Context, config *StackConfig) error {
+	assistant, err := c.findStackAssistant(ctx, config)
+	if err != nil {
+		return err
+	}
+	if assistant != nil {
+		if err := c.DeleteAssistant(ctx, assistant.ID); err != nil {
+			return fmt.Errorf("failed to delete assistant %q: %w", config.Assistant.Name, err)
+		}
+		c.emitProgress(ProgressEvent{Event: "assistant_deleted", Name: config.Assistant.Name, ID: assistant.ID})
+	}
+
+	stores, err := c.findStackVectorStores(ctx, config.Name)
+	if err != nil {
+		return err
+	}
+	for name, vs := range stores {
+		if err := c.deleteStackVectorStore(ctx, name, vs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteStackVectorStore deletes a vector store of a stack and its files
+func (c *Client) deleteStackVectorStore(ctx context.Context, name string, vs *VectorStore) error {
+	snap, err := c.SnapshotVectorStoreFiles(ctx, vs.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list files of vector store %q: %w", name, err)
+	}
+	for _, f := range snap.Items {
+		if err := c.DeleteFile(ctx, f.ID); err != nil {
+			return fmt.Errorf("failed to delete file %s of vector store %q: %w", f.ID, name, err)
+		}
+	}
+	if err := c.DeleteVectorStore(ctx, vs.ID); err != nil {
+		return fmt.Errorf("failed to delete vector store %q: %w", name, err)
+	}
+	c.emitProgress(ProgressEvent{Event: "vector_store_deleted", Name: name, ID: vs.ID})
+	return nil
+}
+
+// assistantParams builds the assistant payload, linking the declared vector stores
+func (config *StackConfig) assistantParams(storeIDs map[string]string) *CreateAssistantParams {
+	decl := config.Assistant
+
+	metadata := copyStringMap(decl.Metadata)
+	metadata[stackMetadataKey] = config.Name
+	metadata[stackResourceKey] = "assistant"
+
+	params := &CreateAssistantParams{
+		Name:         decl.Name,
+		Description:  decl.Description,
+		Model:        decl.Model,
+		Instructions: decl.Instructions,
+		Tools:        decl.Tools,
+		Temperature:  decl.Temperature,
+		TopP:         decl.TopP,
+		Metadata:     metadata,
+	}
+
+	if len(decl.VectorStores) > 0 {
+		ids := make([]string, len(decl.VectorStores))
+		for i, name := range decl.VectorStores {
+			ids[i] = storeIDs[name]
+		}
+		params.ToolResources = map[string]interface{}{
+			"file_search": map[string]interface{}{"vector_store_ids": ids},
+		}
+
+		hasFileSearch := false
+		for _, tool := range params.Tools {
+			hasFileSearch = hasFileSearch || tool.Type == "file_search"
+		}
+		if !hasFileSearch {
+			params.Tools = append(append([]Tool(nil), params.Tools...), Tool{Type: "file_search"})
+		}
+	}
+	return params
+}
+
+// findStackAssistant returns the assistant managed by the stack, or nil
+func (c *Client) findStackAssistant(ctx context.Context, config *StackConfig) (*Assistant, error) {
+	snap, err := c.SnapshotAssistants(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list assistants: %w", err)
+	}
+	for _, a := range snap.Items {
+		if a.Metadata[stackMetadataKey] == config.Name && a.Metadata[stackResourceKey] == "assistant" {
+			return &a, nil
+		}
+	}
+	return nil, nil
+}
+
+// findStackVectorStores returns the vector stores managed by the stack by name
+func (c *Client) findStackVectorStores(ctx context.Context, stackName string) (map[string]*VectorStore, error) {
+	snap, err := c.SnapshotVectorStores(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list vector stores: %w", err)
+	}
+
+	stores := map[string]*VectorStore{}
+	for i, vs := range snap.Items {
+		if vs.Metadata[stackMetadataKey] == stackName && vs.Metadata[stackResourceKey] != "" {
+			stores[vs.Metadata[stackResourceKey]] = &snap.Items[i]
+		}
+	}
+	return stores, nil
+}
+
+// stackFile is a local file to sync into a vector store
+type stackFile struct {
+	path    string // Path relative to its source directory, slash-separated
+	hash    string
+	content []byte
+}
+
+func newStackFile(rel string, content []byte) stackFile {
+	sum := sha256.Sum256(content)
+	return stackFile{path: rel, hash: hex.EncodeToString(sum[:]), content: content}
+}
+
+// syncStackVectorStore makes the files of a vector store match the sources.
+// It returns the paths uploaded and removed.
+func (c *Client) syncStackVectorStore(ctx context.Context, vectorStoreID string, sources []StackSource) ([]string, []string, error) {
+	local, err := readStackSources(sources)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snap, err := c.SnapshotVectorStoreFiles(ctx, vectorStoreID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Attached files that are up to date are kept, the others are removed
+	var uploaded, removed []string
+	watch := c.watchIngestion(ctx)
+	defer watch.stop()
+	upToDate, stale := diffStackFiles(local, snap.Items)
+	for _, f := range stale {
+		p := stackFilePath(f)
+		if err := c.DeleteVectorStoreFile(ctx, vectorStoreID, f.ID); err != nil {
+			return uploaded, removed, err
+		}
+		if err := c.DeleteFile(ctx, f.ID); err != nil {
+			return uploaded, removed, err
+		}
+		if _, ok := local[p]; !ok {
+			removed = append(removed, p)
+			c.emitProgress(ProgressEvent{Event: "file_removed", Name: p, ID: f.ID})
+		}
+	}
+
+	for _, p := range sortedStackPaths(local) {
+		if upToDate[p] {
+			continue
+		}
+
+		lf := local[p]
+		fileID, err := c.UploadContent(ctx, lf.path, lf.content)
+		if err != nil {
+			return uploaded, removed, err
+		}
+		attributes := map[string]interface{}{
+			stackPathAttribute: lf.path,
+			stackHashAttribute: lf.hash,
+		}
+		if _, err := c.createVectorStoreFile(ctx, vectorStoreID, fileID, nil, attributes); err != nil {
+			return uploaded, removed, err
+		}
+		uploaded = append(uploaded, p)
+		watch.queue(IngestionEvent{VectorStoreID: vectorStoreID, FileID: fileID, Path: p})
+	}
+	return uploaded, removed, watch.wait()
+}
+
+// diffStackFiles splits the files attached to a vector store into the paths
+// that match a local file and the attached files that are stale: changed,
+// duplicated or no longer present locally
+func diffStackFiles(local map[string]stackFile, attached []VectorStoreFile) (map[string]bool, []VectorStoreFile) {
+	upToDate := map[string]bool{}
+	var stale []VectorStoreFile
+	for _, f := range attached {
+		p := stackFilePath(f)
+		hash, _ := f.Attributes[stackHashAttribute].(string)
+		if lf, ok := local[p]; ok && lf.hash == hash && !upToDate[p] {
+			upToDate[p] = true
+			continue
+		}
+		stale = append(stale, f)
+	}
+	return upToDate, stale
+}
+
+// stackFilePath returns the source path an attached file was uploaded from
+func stackFilePath(f VectorStoreFile) string {
+	p, _ := f.Attributes[stackPathAttribute].(string)
+	return p
+}
+
+// sortedStackPaths returns the paths of the local files in order
+func sortedStackPaths(local map[string]stackFile) []string {
+	paths := make([]string, 0, len(local))
+	for p := range local {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// readStackSources reads the files selected by the sources, keyed by relative
+// path. Two sources providing the same path is an error, as only one of the
+// files could be synced.
+func readStackSources(sources []StackSource) (map[string]stackFile, error) {
+	files := map[string]stackFile{}
+	origins := map[string]string{} // Source directory of each path
+
+	add := func(src StackSource, rel string, content []byte) error {
+		if origin, ok := origins[rel]; ok {
+			return fmt.Errorf("duplicate path %s, found in %s and %s", rel, origin, src.Dir)
+		}
+		origins[rel] = src.Dir
+		files[rel] = newStackFile(rel, content)
+		return nil
+	}
+
+	for _, src := range sources {
+		if isArchive(src.Dir) {
+			err := walkArchive(src.Dir, func(rel string, content []byte) error {
+				if src.selects(rel) {
+					return add(src, rel, content)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to read source %s: %w", src.Dir, err)
+			}
+			continue
+		}
+
+		err := filepath.WalkDir(src.Dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// Skip hidden files and directories such as .git
+			if strings.HasPrefix(d.Name(), ".") && p != src.Dir {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(src.Dir, p)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if !src.selects(rel) {
+				return nil
+			}
+
+			content, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			return add(src, rel, content)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source %s: %w", src.Dir, err)
+		}
+	}
+	return files, nil
+}
+
+// selects reports whether the source includes the file at the relative path
+func (src StackSource) selects(rel string) bool {
+	matches := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, rel); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(src.Include) > 0 && !matches(src.Include) {
+		return false
+	}
+	return !matches(src.Exclude)
+}
+
+// copyStringMap returns a copy of m that is never nil
+func copyStringMap(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m)+2)
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
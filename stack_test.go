@@ -0,0 +1,92 @@
+package openai_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	openai "github.com/bhirbec/go-openai"
+	"github.com/bhirbec/go-openai/openaitest"
+)
+
+func TestLoadStackConfigYAML(t *testing.T) {
+	fromYAML, err := openai.LoadStackConfig("testdata/stack.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromJSON, err := openai.LoadStackConfig("testdata/stack.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("YAML config %+v differs from JSON config %+v", fromYAML, fromJSON)
+	}
+	if got := fromYAML.Assistant.Temperature; got == nil || *got != 0.2 {
+		t.Errorf("temperature %v, want 0.2", got)
+	}
+}
+
+func TestApplyStackDeletesRemovedVectorStores(t *testing.T) {
+	srv := openaitest.NewServer()
+	defer srv.Close()
+	client := openai.NewClient("test", openai.WithBaseURL(srv.URL))
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	for _, name := range []string{"docs", "faq"} {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name, "index.md"), []byte("# "+name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	config := &openai.StackConfig{
+		Name:      "support",
+		Assistant: openai.StackAssistant{Name: "Support", Model: "gpt-4o-mini", VectorStores: []string{"docs", "faq"}},
+		VectorStores: []openai.StackVectorStore{
+			{Name: "docs", Sources: []openai.StackSource{{Dir: filepath.Join(dir, "docs")}}},
+			{Name: "faq", Sources: []openai.StackSource{{Dir: filepath.Join(dir, "faq")}}},
+		},
+	}
+	first, err := client.ApplyStack(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.Assistant.VectorStores = []string{"docs"}
+	config.VectorStores = config.VectorStores[:1]
+	plan, err := client.PlanStack(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []openai.StackChange{
+		{Action: openai.StackDelete, Resource: "file", Name: "faq/index.md"},
+		{Action: openai.StackDelete, Resource: "vector_store", Name: "faq"},
+	} {
+		if !slices.ContainsFunc(plan.Changes, func(c openai.StackChange) bool { return reflect.DeepEqual(c, want) }) {
+			t.Errorf("plan %+v lacks %+v", plan.Changes, want)
+		}
+	}
+
+	state, err := client.ApplyStack(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(state.DeletedVectorStores, []string{"faq"}) {
+		t.Errorf("deleted vector stores %v, want [faq]", state.DeletedVectorStores)
+	}
+	if _, err := client.RetrieveVectorStore(ctx, first.VectorStores["faq"]); err == nil {
+		t.Error("removed vector store still exists")
+	}
+	plan, err = client.PlanStack(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.HasChanges() {
+		t.Errorf("plan after apply has changes: %+v", plan.Changes)
+	}
+}
@@ -0,0 +1,149 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// StackImport is the result of ImportStack
+type StackImport struct {
+	Config *StackConfig
+
+	// Files lists the names of the files attached to each vector store, by
+	// vector store name. The API does not allow downloading them, so they must
+	// be copied into the source directory of their store before the first
+	// ApplyStack, which otherwise removes them.
+	Files map[string][]string
+}
+
+// ImportStack adopts an assistant created outside of a stack, along with the
+// vector stores its file_search tool is linked to. It generates the matching
+// stack config and tags the resources with the stack name so ApplyStack,
+// PlanStack and DestroyStack manage them from then on.
+//
+// Each vector store gets a source directory named after it. Files attached
+// before the import carry no source path, so the first ApplyStack replaces
+// them with the files found in that directory.
+func (c *Client) ImportStack(ctx context.Context, stackName, assistantID string) (*StackImport, error) {
+	if stackName == "" {
+		return nil, fmt.Errorf("stack name is required")
+	}
+
+	assistant, err := c.RetrieveAssistant(ctx, assistantID)
+	if err != nil {
+		return nil, err
+	}
+	if owner := assistant.Metadata[stackMetadataKey]; owner != "" && owner != stackName {
+		return nil, fmt.Errorf("assistant %s already belongs to stack %q", assistantID, owner)
+	}
+
+	config := &StackConfig{
+		Name: stackName,
+		Assistant: StackAssistant{
+			Name:         assistant.Name,
+			Description:  assistant.Description,
+			Model:        assistant.Model,
+			Instructions: assistant.Instructions,
+			Tools:        assistant.Tools,
+			Temperature:  assistant.Temperature,
+			TopP:         assistant.TopP,
+			Metadata:     userMetadata(assistant.Metadata),
+		},
+	}
+	if config.Assistant.Name == "" {
+		config.Assistant.Name = assistant.ID
+	}
+
+	imported := &StackImport{Config: config, Files: map[string][]string{}}
+	stores := map[string]*VectorStore{}
+
+	var vectorStoreIDs []string
+	if assistant.ToolResources != nil && assistant.ToolResources.FileSearch != nil {
+		vectorStoreIDs = assistant.ToolResources.FileSearch.VectorStoreIDs
+	}
+	for _, vectorStoreID := range vectorStoreIDs {
+		vs, err := c.RetrieveVectorStore(ctx, vectorStoreID)
+		if err != nil {
+			return nil, err
+		}
+		if owner := vs.Metadata[stackMetadataKey]; owner != "" && owner != stackName {
+			return nil, fmt.Errorf("vector store %s already belongs to stack %q", vectorStoreID, owner)
+		}
+
+		// Names identify the stores inside the stack and must be unique
+		name := vs.Name
+		if name == "" || stores[name] != nil {
+			name = vs.ID
+		}
+		stores[name] = vs
+
+		config.VectorStores = append(config.VectorStores, StackVectorStore{
+			Name:     name,
+			Metadata: userMetadata(vs.Metadata),
+			Sources:  []StackSource{{Dir: name}},
+		})
+		config.Assistant.VectorStores = append(config.Assistant.VectorStores, name)
+
+		snap, err := c.SnapshotVectorStoreFiles(ctx, vs.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files of vector store %q: %w", name, err)
+		}
+		for _, f := range snap.Items {
+			file, err := c.RetrieveFile(ctx, f.ID)
+			if err != nil {
+				return nil, err
+			}
+			imported.Files[name] = append(imported.Files[name], file.FileName)
+		}
+		sort.Strings(imported.Files[name])
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	// Tag the resources only once the whole config could be generated
+	for name, vs := range stores {
+		metadata := copyStringMap(vs.Metadata)
+		metadata[stackMetadataKey] = stackName
+		metadata[stackResourceKey] = name
+		if _, err := c.ModifyVectorStore(ctx, vs.ID, &ModifyVectorStoreParams{Metadata: metadata}); err != nil {
+			return nil, fmt.Errorf("failed to tag vector store %q: %w", name, err)
+		}
+	}
+
+	metadata := copyStringMap(assistant.Metadata)
+	metadata[stackMetadataKey] = stackName
+	metadata[stackResourceKey] = "assistant"
+	if err := c.ModifyAssistant(ctx, assistant.ID, &CreateAssistantParams{Model: assistant.Model, Metadata: metadata}); err != nil {
+		return nil, fmt.Errorf("failed to tag assistant %q: %w", config.Assistant.Name, err)
+	}
+
+	return imported, nil
+}
+
+// WriteJSON writes the config as indented JSON, in the format read by
+// LoadStackConfig
+func (config *StackConfig) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(config)
+}
+
+// userMetadata returns the metadata without the keys managed by stacks, or nil
+func userMetadata(metadata map[string]string) map[string]string {
+	var out map[string]string
+	for k, v := range metadata {
+		if k == stackMetadataKey || k == stackResourceKey {
+			continue
+		}
+		if out == nil {
+			out = map[string]string{}
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,214 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Actions of a StackChange
+const (
+	StackCreate = "create"
+	StackUpdate = "update"
+	StackDelete = "delete"
+)
+
+// StackPlan lists the changes ApplyStack would make to bring the live
+// resources of a stack in line with its config
+type StackPlan struct {
+	Stack   string        `json:"stack"`
+	Changes []StackChange `json:"changes"`
+}
+
+// StackChange is a resource that differs from the config
+type StackChange struct {
+	Action   string `json:"action"`   // StackCreate, StackUpdate or StackDelete
+	Resource string `json:"resource"` // "assistant", "vector_store" or "file"
+	Name     string `json:"name"`     // Files are named "<vector store>/<path>"
+
+	// Fields lists the assistant fields that differ, for updates
+	Fields []StackFieldChange `json:"fields,omitempty"`
+}
+
+// StackFieldChange is a field whose live value differs from the declared one
+type StackFieldChange struct {
+	Field    string      `json:"field"`
+	Live     interface{} `json:"live"`
+	Declared interface{} `json:"declared"`
+}
+
+// HasChanges reports whether applying the stack would change anything
+func (p *StackPlan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// Write prints the plan as a diff: "+" for resources to create, "~" for
+// resources to update, followed by their changed fields, and "-" for
+// resources to delete
+func (p *StackPlan) Write(w io.Writer) error {
+	var b strings.Builder
+	if !p.HasChanges() {
+		fmt.Fprintf(&b, "Stack %q is up to date\n", p.Stack)
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	fmt.Fprintf(&b, "Stack %q: %d change(s)\n", p.Stack, len(p.Changes))
+	symbols := map[string]string{StackCreate: "+", StackUpdate: "~", StackDelete: "-"}
+	for _, change := range p.Changes {
+		fmt.Fprintf(&b, "  %s %s %q\n", symbols[change.Action], change.Resource, change.Name)
+		for _, field := range change.Fields {
+			fmt.Fprintf(&b, "      %s: %s => %s\n", field.Field, planValue(field.Live), planValue(field.Declared))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// planValue renders a field value for Write
+func planValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// PlanStack compares the config against the live resources of the stack and
+// returns the changes ApplyStack would make, without making any. It compares
+// the assistant fields and, for each vector store, the files attached against
+// the source directories. Vector stores no longer in the config are deleted
+// with their files.
+func (c *Client) PlanStack(ctx context.Context, config *StackConfig) (*StackPlan, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	plan := &StackPlan{Stack: config.Name}
+
+	stores, err := c.findStackVectorStores(ctx, config.Name)
+	if err != nil {
+		return nil, err
+	}
+	for _, decl := range config.VectorStores {
+		local, err := readStackSources(decl.Sources)
+		if err != nil {
+			return nil, err
+		}
+
+		vs, ok := stores[decl.Name]
+		if !ok {
+			plan.add(StackChange{Action: StackCreate, Resource: "vector_store", Name: decl.Name})
+			for _, p := range sortedStackPaths(local) {
+				plan.add(StackChange{Action: StackCreate, Resource: "file", Name: decl.Name + "/" + p})
+			}
+			continue
+		}
+
+		snap, err := c.SnapshotVectorStoreFiles(ctx, vs.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files of vector store %q: %w", decl.Name, err)
+		}
+		upToDate, stale := diffStackFiles(local, snap.Items)
+
+		// A stale file whose path is still present locally is replaced
+		replaced := map[string]bool{}
+		for _, f := range stale {
+			p := stackFilePath(f)
+			if _, ok := local[p]; ok {
+				replaced[p] = true
+				continue
+			}
+			plan.add(StackChange{Action: StackDelete, Resource: "file", Name: decl.Name + "/" + p})
+		}
+		for _, p := range sortedStackPaths(local) {
+			switch {
+			case upToDate[p]:
+			case replaced[p]:
+				plan.add(StackChange{Action: StackUpdate, Resource: "file", Name: decl.Name + "/" + p})
+			default:
+				plan.add(StackChange{Action: StackCreate, Resource: "file", Name: decl.Name + "/" + p})
+			}
+		}
+	}
+
+	assistant, err := c.findStackAssistant(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	if assistant == nil {
+		plan.add(StackChange{Action: StackCreate, Resource: "assistant", Name: config.Assistant.Name})
+	} else {
+		storeIDs := map[string]string{}
+		for name, vs := range stores {
+			storeIDs[name] = vs.ID
+		}
+		fields := diffAssistant(assistant, config.assistantParams(storeIDs))
+		if len(fields) > 0 {
+			plan.add(StackChange{Action: StackUpdate, Resource: "assistant", Name: config.Assistant.Name, Fields: fields})
+		}
+	}
+
+	declared := map[string]bool{}
+	for _, decl := range config.VectorStores {
+		declared[decl.Name] = true
+	}
+	for _, name := range slices.Sorted(maps.Keys(stores)) {
+		if declared[name] {
+			continue
+		}
+		snap, err := c.SnapshotVectorStoreFiles(ctx, stores[name].ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files of vector store %q: %w", name, err)
+		}
+		for _, f := range snap.Items {
+			plan.add(StackChange{Action: StackDelete, Resource: "file", Name: name + "/" + stackFilePath(f)})
+		}
+		plan.add(StackChange{Action: StackDelete, Resource: "vector_store", Name: name})
+	}
+	return plan, nil
+}
+
+func (p *StackPlan) add(change StackChange) {
+	p.Changes = append(p.Changes, change)
+}
+
+// diffAssistant returns the fields of the live assistant that differ from the
+// declared ones. Tools are compared by type since the API fills in defaults
+// for their configuration.
+func diffAssistant(live *Assistant, declared *CreateAssistantParams) []StackFieldChange {
+	var fields []StackFieldChange
+	compare := func(field string, liveValue, declaredValue interface{}) {
+		if !reflect.DeepEqual(liveValue, declaredValue) {
+			fields = append(fields, StackFieldChange{Field: field, Live: liveValue, Declared: declaredValue})
+		}
+	}
+
+	compare("name", live.Name, declared.Name)
+	compare("description", live.Description, declared.Description)
+	compare("model", live.Model, declared.Model)
+	compare("instructions", live.Instructions, declared.Instructions)
+	compare("tools", toolTypes(live.Tools), toolTypes(declared.Tools))
+	if declared.Temperature != nil {
+		compare("temperature", live.Temperature, declared.Temperature)
+	}
+	if declared.TopP != nil {
+		compare("top_p", live.TopP, declared.TopP)
+	}
+	compare("metadata", copyStringMap(live.Metadata), copyStringMap(declared.Metadata))
+	return fields
+}
+
+// toolTypes returns the types of the tools, never nil
+func toolTypes(tools []Tool) []string {
+	types := make([]string, len(tools))
+	for i, tool := range tools {
+		types[i] = tool.Type
+	}
+	return types
+}
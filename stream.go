@@ -0,0 +1,155 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Framings of StreamWriter
+const (
+	StreamSSE       = "sse"        // Server-sent events, "data: {...}\n\n"
+	StreamJSONLines = "json-lines" // One JSON object per line, sent chunked
+)
+
+// StreamWriter writes the events of a stream to an HTTP response, flushing
+// each one so it reaches the browser or client right away. Writes block while
+// the client is slow to read, which slows down the reading of the upstream
+// stream rather than buffering it.
+type StreamWriter struct {
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	framing string
+
+	// WriteTimeout bounds each write, so a client that stopped reading fails
+	// the stream instead of holding the upstream call open. 0 means no limit.
+	WriteTimeout time.Duration
+}
+
+// NewStreamWriter sets the headers of the response for the framing, StreamSSE
+// or StreamJSONLines
+func NewStreamWriter(w http.ResponseWriter, framing string) *StreamWriter {
+	switch framing {
+	case StreamJSONLines:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	default:
+		framing = StreamSSE
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+	}
+	// Stop proxies such as nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	return &StreamWriter{w: w, rc: http.NewResponseController(w), framing: framing}
+}
+
+// Write sends data as JSON. event names the SSE event and is ignored with
+// StreamJSONLines.
+func (s *StreamWriter) Write(event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode stream event: %w", err)
+	}
+
+	var frame []byte
+	if s.framing == StreamSSE {
+		if event != "" {
+			frame = append(frame, "event: "+event+"\n"...)
+		}
+		frame = append(frame, "data: "...)
+		frame = append(frame, payload...)
+		frame = append(frame, "\n\n"...)
+	} else {
+		frame = append(payload, '\n')
+	}
+	return s.write(frame)
+}
+
+// Done ends an SSE stream with the "[DONE]" sentinel used by OpenAI. It does
+// nothing with StreamJSONLines.
+func (s *StreamWriter) Done() error {
+	if s.framing != StreamSSE {
+		return nil
+	}
+	return s.write([]byte("data: [DONE]\n\n"))
+}
+
+func (s *StreamWriter) write(frame []byte) error {
+	if s.WriteTimeout > 0 {
+		// Not every ResponseWriter supports deadlines; the write is then unbounded
+		s.rc.SetWriteDeadline(time.Now().Add(s.WriteTimeout))
+	}
+	if _, err := s.w.Write(frame); err != nil {
+		return err
+	}
+	if err := s.rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// ServeChatStream streams a chat completion to the client of r, one chunk per
+// event. The upstream call is cancelled as soon as the client disconnects or a
+// write fails. Usage is recorded when the request asks for it with
+// StreamOptions.IncludeUsage.
+func (c *Client) ServeChatStream(sw *StreamWriter, r *http.Request, req openai.ChatCompletionRequest) error {
+	err := c.streamChat(r.Context(), req, func(chunk openai.ChatCompletionStreamResponse) error {
+		if err := sw.Write("", chunk); err != nil {
+			return fmt.Errorf("failed to write chat stream: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return sw.Done()
+}
+
+// streamChat streams a chat completion, passing each chunk to emit until the
+// stream ends. The upstream call is cancelled when ctx is done or emit fails.
+func (c *Client) streamChat(ctx context.Context, req openai.ChatCompletionRequest, emit func(openai.ChatCompletionStreamResponse) error) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req.Model = c.chatModel(ctx, req)
+	req.Stream = true
+	transcript := c.newTranscriptRecorder(req.Model)
+	stream, err := c.sdkClient().CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return fmt.Errorf("error creating chat stream: %w", sdkError(err))
+	}
+	defer stream.Close()
+	defer func() { transcript.finish(ctx, err) }()
+
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				c.logger.InfoContext(ctx, "chat stream client disconnected")
+				return ctx.Err()
+			}
+			return fmt.Errorf("error reading chat stream: %w", sdkError(err))
+		}
+
+		if chunk.Usage != nil {
+			c.recordUsage(ctx, Usage{
+				Endpoint:         EndpointChat,
+				Model:            chunk.Model,
+				PromptTokens:     chunk.Usage.PromptTokens,
+				CompletionTokens: chunk.Usage.CompletionTokens,
+			})
+		}
+		transcript.add(chunk)
+		if err := emit(chunk); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package openai
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cue is a subtitle shown between Start and End
+type Cue struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string // Lines separated by "\n"
+}
+
+// SegmentConfig bounds the cues produced by ResegmentCues. A zero field means
+// no limit.
+type SegmentConfig struct {
+	MaxLineChars int           // Characters per line, e.g. 42
+	MaxLines     int           // Lines per cue, e.g. 2
+	MaxDuration  time.Duration // Duration of a cue, e.g. 6s
+}
+
+// ParseSRT parses subtitles in SRT format, e.g. a transcription requested
+// with the srt response format
+func ParseSRT(data string) ([]Cue, error) {
+	return parseCues(data, ",")
+}
+
+// ParseVTT parses subtitles in WebVTT format. Cue settings, notes and styles
+// are dropped.
+func ParseVTT(data string) ([]Cue, error) {
+	data = strings.TrimPrefix(data, "\uFEFF")
+	if !strings.HasPrefix(data, "WEBVTT") {
+		return nil, fmt.Errorf("missing WEBVTT header")
+	}
+	return parseCues(data, ".")
+}
+
+// parseCues parses the blocks of a subtitle file, skipping the ones without a
+// timing line such as the WebVTT header
+func parseCues(data, fractionSep string) ([]Cue, error) {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+
+	var cues []Cue
+	for _, block := range strings.Split(data, "\n\n") {
+		lines := strings.Split(strings.Trim(block, "\n"), "\n")
+		timing := slices.IndexFunc(lines, func(line string) bool { return strings.Contains(line, "-->") })
+		if timing < 0 {
+			continue
+		}
+
+		start, end, ok := strings.Cut(lines[timing], "-->")
+		if !ok {
+			continue
+		}
+		// WebVTT settings follow the end time
+		end, _, _ = strings.Cut(strings.TrimSpace(end), " ")
+
+		cue := Cue{Text: strings.Join(lines[timing+1:], "\n")}
+		var err error
+		if cue.Start, err = parseTimestamp(strings.TrimSpace(start), fractionSep); err != nil {
+			return nil, err
+		}
+		if cue.End, err = parseTimestamp(end, fractionSep); err != nil {
+			return nil, err
+		}
+		cues = append(cues, cue)
+	}
+	return cues, nil
+}
+
+// parseTimestamp parses "hh:mm:ss,mmm", or "mm:ss.mmm" in WebVTT
+func parseTimestamp(s, fractionSep string) (time.Duration, error) {
+	clock, millis, ok := strings.Cut(s, fractionSep)
+	if !ok {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	var d time.Duration
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		d = d*60 + time.Duration(n)
+	}
+	ms, err := strconv.Atoi(millis)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+	return d*time.Second + time.Duration(ms)*time.Millisecond, nil
+}
+
+// FormatSRT returns the cues in SRT format
+func FormatSRT(cues []Cue) string {
+	var b strings.Builder
+	for i, cue := range cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, formatTimestamp(cue.Start, ","), formatTimestamp(cue.End, ","), cue.Text)
+	}
+	return b.String()
+}
+
+// FormatVTT returns the cues in WebVTT format
+func FormatVTT(cues []Cue) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, cue := range cues {
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", formatTimestamp(cue.Start, "."), formatTimestamp(cue.End, "."), cue.Text)
+	}
+	return b.String()
+}
+
+func formatTimestamp(d time.Duration, fractionSep string) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, fractionSep, ms%1000)
+}
+
+// OffsetCues returns the cues shifted by d, e.g. to place the transcription of
+// the second part of a recording after the first one
+func OffsetCues(cues []Cue, d time.Duration) []Cue {
+	out := make([]Cue, len(cues))
+	for i, cue := range cues {
+		out[i] = Cue{Start: cue.Start + d, End: cue.End + d, Text: cue.Text}
+	}
+	return out
+}
+
+// MergeCues combines several lists of cues into one ordered by start time
+func MergeCues(lists ...[]Cue) []Cue {
+	var out []Cue
+	for _, cues := range lists {
+		out = append(out, cues...)
+	}
+	slices.SortStableFunc(out, func(a, b Cue) int {
+		return int(a.Start - b.Start)
+	})
+	return out
+}
+
+// ResegmentCues splits the cues so each one fits config, wrapping lines at word
+// boundaries. The time of a cue is shared among its parts in proportion to
+// their length, since the transcription gives no timing within a cue.
+func ResegmentCues(cues []Cue, config SegmentConfig) []Cue {
+	var out []Cue
+	for _, cue := range cues {
+		parts := splitCueText(strings.Fields(cue.Text), config)
+		duration := cue.End - cue.Start
+		chars := 0
+		for _, part := range parts {
+			chars += wordChars(part)
+		}
+		if chars == 0 {
+			continue
+		}
+
+		// Split further the parts that would be shown for too long
+		if config.MaxDuration > 0 {
+			var split [][]string
+			for _, part := range parts {
+				split = append(split, splitByDuration(part, duration*time.Duration(wordChars(part))/time.Duration(chars), config.MaxDuration)...)
+			}
+			parts = split
+		}
+
+		start, done := cue.Start, 0
+		for _, part := range parts {
+			done += wordChars(part)
+			end := cue.Start + duration*time.Duration(done)/time.Duration(chars)
+			out = append(out, Cue{Start: start, End: end, Text: wrapWords(part, config.MaxLineChars)})
+			start = end
+		}
+	}
+	return out
+}
+
+// splitCueText groups words into the parts of a cue that fit MaxLines lines of
+// MaxLineChars characters
+func splitCueText(words []string, config SegmentConfig) [][]string {
+	if len(words) == 0 {
+		return nil
+	}
+	if config.MaxLineChars <= 0 || config.MaxLines <= 0 {
+		return [][]string{words}
+	}
+
+	var parts [][]string
+	var part []string
+	for _, word := range words {
+		candidate := append(slices.Clone(part), word)
+		if len(part) > 0 && strings.Count(wrapWords(candidate, config.MaxLineChars), "\n")+1 > config.MaxLines {
+			parts = append(parts, part)
+			candidate = []string{word}
+		}
+		part = candidate
+	}
+	return append(parts, part)
+}
+
+// wordChars returns the number of characters of words
+func wordChars(words []string) int {
+	n := 0
+	for _, word := range words {
+		n += len([]rune(word))
+	}
+	return n
+}
+
+// splitByDuration splits words shown for duration into parts of at most max
+func splitByDuration(words []string, duration, max time.Duration) [][]string {
+	n := int((duration + max - 1) / max)
+	if n <= 1 || len(words) <= 1 {
+		return [][]string{words}
+	}
+	n = min(n, len(words))
+
+	parts := make([][]string, 0, n)
+	for i := 0; i < n; i++ {
+		parts = append(parts, words[i*len(words)/n:(i+1)*len(words)/n])
+	}
+	return parts
+}
+
+// wrapWords joins words into lines of at most maxChars characters, 0 for a
+// single line. A word longer than maxChars gets a line of its own.
+func wrapWords(words []string, maxChars int) string {
+	var b strings.Builder
+	lineLen := 0
+	for i, word := range words {
+		switch {
+		case i == 0:
+		case maxChars > 0 && lineLen+1+len([]rune(word)) > maxChars:
+			b.WriteByte('\n')
+			lineLen = 0
+		default:
+			b.WriteByte(' ')
+			lineLen++
+		}
+		b.WriteString(word)
+		lineLen += len([]rune(word))
+	}
+	return b.String()
+}
@@ -0,0 +1,172 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// defaultRowsPerChunk is the number of rows per chunk document when none is given
+const defaultRowsPerChunk = 50
+
+// TabularChunk is a group of rows rendered as a standalone text document
+type TabularChunk struct {
+	Name     string // File name used for the upload, e.g. "sales.part003.txt"
+	FirstRow int    // 1-based index of the first data row in the chunk
+	LastRow  int
+	Content  []byte
+}
+
+// SplitTabular splits a CSV or JSONL document into chunk documents of rowsPerChunk
+// rows. Every row is rendered as "column: value" pairs under a header naming the
+// source and its columns, so each chunk stays meaningful once retrieved on its own.
+// The format is picked from the file extension (.csv, .jsonl or .ndjson).
+func SplitTabular(path string, content []byte, rowsPerChunk int) ([]TabularChunk, error) {
+	if rowsPerChunk <= 0 {
+		rowsPerChunk = defaultRowsPerChunk
+	}
+
+	var (
+		columns []string
+		rows    [][]string
+		err     error
+	)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		columns, rows, err = readCSVRows(content)
+	case ".jsonl", ".ndjson":
+		columns, rows, err = readJSONLRows(content)
+	default:
+		return nil, fmt.Errorf("unsupported tabular file: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	var chunks []TabularChunk
+	for start := 0; start < len(rows); start += rowsPerChunk {
+		end := min(start+rowsPerChunk, len(rows))
+
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "Source: %s (rows %d-%d of %d)\n", filepath.Base(path), start+1, end, len(rows))
+		fmt.Fprintf(&buf, "Columns: %s\n", strings.Join(columns, ", "))
+		for i, row := range rows[start:end] {
+			fmt.Fprintf(&buf, "\nRow %d\n", start+i+1)
+			for j, column := range columns {
+				if j < len(row) && row[j] != "" {
+					fmt.Fprintf(&buf, "%s: %s\n", column, row[j])
+				}
+			}
+		}
+
+		chunks = append(chunks, TabularChunk{
+			Name:     fmt.Sprintf("%s.part%03d.txt", base, len(chunks)+1),
+			FirstRow: start + 1,
+			LastRow:  end,
+			Content:  buf.Bytes(),
+		})
+	}
+	return chunks, nil
+}
+
+// UploadTabular splits a CSV or JSONL file with SplitTabular and uploads every
+// chunk. It returns the IDs of the uploaded chunk files in row order.
+func (c *Client) UploadTabular(ctx context.Context, path string, rowsPerChunk int) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	chunks, err := SplitTabular(path, content, rowsPerChunk)
+	if err != nil {
+		return nil, err
+	}
+
+	fileIDs := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		fileID, err := c.UploadContent(ctx, chunk.Name, chunk.Content)
+		if err != nil {
+			return fileIDs, fmt.Errorf("failed to upload rows %d-%d: %w", chunk.FirstRow, chunk.LastRow, err)
+		}
+		fileIDs = append(fileIDs, fileID)
+	}
+	return fileIDs, nil
+}
+
+// readCSVRows reads a CSV document whose first record holds the column names
+func readCSVRows(content []byte) ([]string, [][]string, error) {
+	r := csv.NewReader(bytes.NewReader(content))
+	r.FieldsPerRecord = -1
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+	return records[0], records[1:], nil
+}
+
+// readJSONLRows reads one JSON object per line. Columns are the union of all keys,
+// in order of first appearance with the keys of each object sorted.
+func readJSONLRows(content []byte) ([]string, [][]string, error) {
+	var (
+		columns []string
+		index   = map[string]int{}
+		objects []map[string]json.RawMessage
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(text, &obj); err != nil {
+			return nil, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if _, ok := index[key]; !ok {
+				index[key] = len(columns)
+				columns = append(columns, key)
+			}
+		}
+		objects = append(objects, obj)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([][]string, len(objects))
+	for i, obj := range objects {
+		row := make([]string, len(columns))
+		for key, raw := range obj {
+			var s string
+			if err := json.Unmarshal(raw, &s); err == nil {
+				row[index[key]] = s
+			} else if string(raw) != "null" {
+				row[index[key]] = string(raw)
+			}
+		}
+		rows[i] = row
+	}
+	return columns, rows, nil
+}
@@ -0,0 +1,196 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrUnknownTenant is returned by TenantManager.Client for tenants never configured
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// QuotaExceededError is returned by the calls of a tenant that has consumed its
+// token budget or reached its spend cap
+type QuotaExceededError struct {
+	Tenant string
+	Used   int64 // Tokens consumed by the tenant
+	Limit  int64 // Token budget, 0 when the spend cap was reached
+
+	Spent    float64 // Dollars spent by the tenant
+	SpendCap float64 // 0 when the token budget was exceeded
+}
+
+func (e *QuotaExceededError) Error() string {
+	if e.SpendCap > 0 {
+		return fmt.Sprintf("tenant %q reached its spend cap: $%.2f of $%.2f spent", e.Tenant, e.Spent, e.SpendCap)
+	}
+	return fmt.Sprintf("tenant %q exceeded its token budget: %d of %d tokens used", e.Tenant, e.Used, e.Limit)
+}
+
+// TenantConfig configures the client of a tenant
+type TenantConfig struct {
+	Project  string            // Sent as the OpenAI-Project header when set
+	Metadata map[string]string // Added to the resources created by the tenant, see WithDefaultMetadata
+
+	// Client-side limits of the tenant, see NewRateLimiter. 0 for no limit.
+	RequestsPerMinute int
+	TokensPerMinute   int
+
+	// TokenBudget is the number of tokens the tenant may consume before its
+	// calls fail with a QuotaExceededError. 0 for no limit. The call crossing
+	// the budget completes; the following ones are refused.
+	TokenBudget int64
+
+	// SpendCap is the spend in dollars after which the calls of the tenant
+	// fail with a QuotaExceededError, priced like a BudgetGuard. 0 for no cap.
+	// Usage of models without a price does not count toward it.
+	SpendCap float64
+	Pricing  Pricing // Prices used for the spend cap, DefaultPricing when nil
+
+	Options []Option // Additional options of the tenant's client
+}
+
+// TenantManager vends a preconfigured client per tenant, sharing the API key
+// and base options, and tracks the tokens each tenant consumes and the dollars
+// it spends. It is safe for concurrent use.
+type TenantManager struct {
+	apiKey string
+	opts   []Option
+
+	mu      sync.Mutex
+	tenants map[string]*tenant
+}
+
+type tenant struct {
+	name   string
+	config TenantConfig
+	client *Client
+	used   atomic.Int64
+
+	mu    sync.Mutex
+	spent float64 // Dollars
+}
+
+// NewTenantManager returns a manager whose tenant clients use the API key and
+// options, followed by the options of each tenant
+func NewTenantManager(apiKey string, opts ...Option) *TenantManager {
+	return &TenantManager{apiKey: apiKey, opts: opts, tenants: map[string]*tenant{}}
+}
+
+// SetTenant configures a tenant, replacing its previous configuration. The
+// tokens consumed and dollars spent so far still count toward the new limits.
+func (m *TenantManager) SetTenant(name string, config TenantConfig) {
+	if config.Pricing == nil {
+		config.Pricing = DefaultPricing
+	}
+	t := &tenant{name: name, config: config}
+
+	opts := append([]Option(nil), m.opts...)
+	if config.Project != "" {
+		opts = append(opts, WithHeader("OpenAI-Project", config.Project))
+	}
+	if len(config.Metadata) > 0 {
+		opts = append(opts, WithDefaultMetadata(config.Metadata))
+	}
+	if config.RequestsPerMinute > 0 || config.TokensPerMinute > 0 {
+		opts = append(opts, WithRateLimiter(NewRateLimiter(config.RequestsPerMinute, config.TokensPerMinute)))
+	}
+	opts = append(opts, withTenant(name), withRequestGuard(t.checkBudget), WithUsageHook(t.record))
+	opts = append(opts, config.Options...)
+	t.client = NewClient(m.apiKey, opts...)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if previous, ok := m.tenants[name]; ok {
+		t.used.Store(previous.used.Load())
+		t.spent = previous.spend()
+	}
+	m.tenants[name] = t
+}
+
+// withTenant names the tenant of the client, partitioning caches shared by
+// the clients of several tenants
+func withTenant(name string) Option {
+	return func(c *Client) {
+		c.tenant = name
+	}
+}
+
+// Client returns the client of a tenant
+func (m *TenantManager) Client(name string) (*Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tenants[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownTenant, name)
+	}
+	return t.client, nil
+}
+
+// Usage returns the tokens consumed by a tenant
+func (m *TenantManager) Usage(name string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.tenants[name]; ok {
+		return t.used.Load()
+	}
+	return 0
+}
+
+// Spend returns the dollars spent by a tenant
+func (m *TenantManager) Spend(name string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.tenants[name]; ok {
+		return t.spend()
+	}
+	return 0
+}
+
+// ResetUsage sets the tokens consumed and the dollars spent by a tenant back
+// to 0, e.g. at the start of a billing period
+func (m *TenantManager) ResetUsage(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.tenants[name]; ok {
+		t.used.Store(0)
+		t.mu.Lock()
+		t.spent = 0
+		t.mu.Unlock()
+	}
+}
+
+// record counts the usage of a call of the tenant
+func (t *tenant) record(_ context.Context, usage Usage) {
+	t.used.Add(int64(usage.TotalTokens()))
+	if cost, ok := t.config.Pricing.Cost(usage); ok {
+		t.mu.Lock()
+		t.spent += cost
+		t.mu.Unlock()
+	}
+}
+
+func (t *tenant) spend() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.spent
+}
+
+// checkBudget refuses the requests of a tenant over its token budget or spend
+// cap
+func (t *tenant) checkBudget(*http.Request) error {
+	if used := t.used.Load(); t.config.TokenBudget > 0 && used >= t.config.TokenBudget {
+		return &QuotaExceededError{Tenant: t.name, Used: used, Limit: t.config.TokenBudget, Spent: t.spend()}
+	}
+	if spent := t.spend(); t.config.SpendCap > 0 && spent >= t.config.SpendCap {
+		return &QuotaExceededError{Tenant: t.name, Used: t.used.Load(), Spent: spent, SpendCap: t.config.SpendCap}
+	}
+	return nil
+}
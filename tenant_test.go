@@ -0,0 +1,59 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	openai "github.com/bhirbec/go-openai"
+)
+
+func TestTenantSpendCap(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		// A million prompt tokens of gpt-4o-mini cost $0.15
+		io.WriteString(w, `{"model": "gpt-4o-mini", "choices": [{"message": {"role": "assistant", "content": "{\"queries\": [\"q\"]}"}}],
+			"usage": {"prompt_tokens": 1000000, "completion_tokens": 0, "total_tokens": 1000000}}`)
+	}))
+	defer srv.Close()
+
+	manager := openai.NewTenantManager("test", openai.WithBaseURL(srv.URL))
+	manager.SetTenant("acme", openai.TenantConfig{SpendCap: 0.2})
+	manager.SetTenant("globex", openai.TenantConfig{SpendCap: 1})
+	acme, _ := manager.Client("acme")
+	globex, _ := manager.Client("globex")
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := acme.ExpandQuery(ctx, "refund policy", 1); err != nil {
+			t.Fatalf("call %d under the cap failed: %v", i+1, err)
+		}
+	}
+	if spent := manager.Spend("acme"); math.Abs(spent-0.3) > 1e-9 {
+		t.Errorf("spent $%v, want $0.30", spent)
+	}
+
+	_, err := acme.ExpandQuery(ctx, "refund policy", 1)
+	var quota *openai.QuotaExceededError
+	if !errors.As(err, &quota) || quota.SpendCap != 0.2 {
+		t.Fatalf("got error %v, want a QuotaExceededError for the spend cap", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("%d requests sent, want 2: the call over the cap must not reach the API", got)
+	}
+
+	if _, err := globex.ExpandQuery(ctx, "refund policy", 1); err != nil {
+		t.Errorf("other tenant refused: %v", err)
+	}
+	manager.ResetUsage("acme")
+	if _, err := acme.ExpandQuery(ctx, "refund policy", 1); err != nil {
+		t.Errorf("call after reset failed: %v", err)
+	}
+}
@@ -1,26 +1,34 @@
 package openai
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 )
 
 // Thread represents the response from creating or retrieving a thread
 type Thread struct {
 	ID            string                 `json:"id"`
 	Object        string                 `json:"object"`
-	CreatedAt     int64                  `json:"created_at"`
-	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt     Timestamp              `json:"created_at"`
+	Metadata      Metadata               `json:"metadata,omitempty"`
 	ToolResources map[string]interface{} `json:"tool_resources,omitempty"`
 }
 
-// ThreadMessage represents the message structure in a thread
+// ThreadMessage represents the message structure in a thread. Attachments
+// seed the thread with files, each added to the tools it lists, e.g.
+// NewMessageAttachment(fileID, "file_search").
 type ThreadMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role        string              `json:"role"`
+	Content     string              `json:"content"`
+	Attachments []MessageAttachment `json:"attachments,omitempty"`
+	Metadata    Metadata            `json:"metadata,omitempty"`
+}
+
+// DeletionStatus is the response of the API to a deletion
+type DeletionStatus struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"` // e.g. "thread.deleted"
+	Deleted bool   `json:"deleted"`
 }
 
 // CreateThreadParams defines the parameters for creating a thread
@@ -29,41 +37,66 @@ type CreateThreadParams struct {
 	ToolResources  map[string]interface{}   `json:"tool_resources,omitempty"`
 	VectorStoreIDs []string                 `json:"vector_store_ids,omitempty"`
 	VectorStores   []map[string]interface{} `json:"vector_stores,omitempty"`
+	Metadata       Metadata                 `json:"metadata,omitempty"`
+}
+
+// ModifyThreadParams defines the parameters for modifying a thread. Nil fields
+// are left unchanged; a resource of ToolResources replaces the one of the
+// thread, an empty list detaching every file or vector store.
+type ModifyThreadParams struct {
+	Metadata      Metadata       `json:"metadata,omitempty"`
+	ToolResources *ToolResources `json:"tool_resources,omitempty"`
 }
 
 // CreateThread creates a new thread with the specified parameters
-func CreateThread(params *CreateThreadParams) (*Thread, error) {
-	payloadBytes, err := json.Marshal(params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal thread payload: %w", err)
+func (c *Client) CreateThread(ctx context.Context, params *CreateThreadParams) (*Thread, error) {
+	var response Thread
+	if err := c.do(ctx, "POST", "/threads", params, &response); err != nil {
+		return nil, fmt.Errorf("thread creation failed: %w", err)
 	}
 
-	url := "https://api.openai.com/v1/threads"
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create thread request: %w", err)
+	c.logger.InfoContext(ctx, "thread created", "thread_id", response.ID)
+	return &response, nil
+}
+
+// RetrieveThread retrieves a thread by its ID, e.g. to resume a stored
+// conversation
+func (c *Client) RetrieveThread(ctx context.Context, threadID string) (*Thread, error) {
+	var thread Thread
+	if err := c.do(ctx, "GET", "/threads/"+threadID, nil, &thread); err != nil {
+		return nil, fmt.Errorf("thread retrieval failed: %w", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
+	return &thread, nil
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("thread request failed: %w", err)
+// ModifyThread updates the metadata or tool resources of a thread, e.g. to tag
+// it with a user ID or swap the vector stores searched mid-conversation
+func (c *Client) ModifyThread(ctx context.Context, threadID string, params *ModifyThreadParams) (*Thread, error) {
+	// toolResourcesPayload keeps empty lists, which the typed resources omit
+	body := struct {
+		Metadata      Metadata               `json:"metadata,omitempty"`
+		ToolResources map[string]interface{} `json:"tool_resources,omitempty"`
+	}{Metadata: params.Metadata}
+	if params.ToolResources != nil {
+		body.ToolResources = toolResourcesPayload(params.ToolResources)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("thread creation failed with status %s: %s", resp.Status, string(body))
+	var thread Thread
+	if err := c.do(ctx, "POST", "/threads/"+threadID, body, &thread); err != nil {
+		return nil, fmt.Errorf("thread modification failed: %w", err)
 	}
 
-	var response Thread
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode thread response: %w", err)
+	c.logger.InfoContext(ctx, "thread modified", "thread_id", threadID)
+	return &thread, nil
+}
+
+// DeleteThread deletes a thread along with its messages and runs
+func (c *Client) DeleteThread(ctx context.Context, threadID string) (*DeletionStatus, error) {
+	var status DeletionStatus
+	if err := c.do(ctx, "DELETE", "/threads/"+threadID, nil, &status); err != nil {
+		return nil, fmt.Errorf("thread deletion failed: %w", err)
 	}
 
-	fmt.Printf("Thread created successfully with ID: %s\n", response.ID)
-	return &response, nil
+	c.logger.InfoContext(ctx, "thread deleted", "thread_id", threadID)
+	return &status, nil
 }
@@ -0,0 +1,225 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ThreadLog keeps a local copy of the messages of threads, one append-only
+// JSON lines file per thread in Dir, so the history of a conversation can be
+// read for analytics or a UI without listing the messages again. Syncs only
+// append the messages added since the last one; updates and deletions are
+// appended too and folded by Compact. It is safe for concurrent use within a
+// process.
+type ThreadLog struct {
+	Dir string
+
+	mu sync.Mutex
+}
+
+// threadLogEntry is a line of a thread log, a message or a deletion
+type threadLogEntry struct {
+	Message *Message `json:"message,omitempty"`
+	Deleted string   `json:"deleted,omitempty"` // ID of a deleted message
+}
+
+// Append adds messages to the log of the thread. A message already logged is
+// replaced by its new version when the log is read.
+func (l *ThreadLog) Append(threadID string, messages ...Message) error {
+	entries := make([]threadLogEntry, len(messages))
+	for i := range messages {
+		entries[i].Message = &messages[i]
+	}
+	return l.append(threadID, entries)
+}
+
+// Delete records that a message was deleted from the thread
+func (l *ThreadLog) Delete(threadID, messageID string) error {
+	return l.append(threadID, []threadLogEntry{{Deleted: messageID}})
+}
+
+// Messages returns the messages of the thread in the order they were first
+// logged, with their latest version. It returns nil when the thread has no log.
+func (l *ThreadLog) Messages(threadID string) ([]Message, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	messages, _, err := l.read(threadID)
+	return messages, err
+}
+
+// Cursor returns the ID of the last message logged, to pass to MessagesSince,
+// or "" when the thread has no log
+func (l *ThreadLog) Cursor(threadID string) (string, error) {
+	messages, err := l.Messages(threadID)
+	if err != nil || len(messages) == 0 {
+		return "", err
+	}
+	return messages[len(messages)-1].ID, nil
+}
+
+// Compact rewrites the log of the thread with one line per message, dropping
+// replaced versions and deleted messages. The file is replaced atomically, so
+// an interrupted compaction leaves the previous log.
+func (l *ThreadLog) Compact(threadID string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	messages, lines, err := l.read(threadID)
+	if err != nil || lines == len(messages) {
+		return err
+	}
+
+	var buf bytes.Buffer
+	for i := range messages {
+		line, err := json.Marshal(threadLogEntry{Message: &messages[i]})
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+
+	path := l.path(threadID)
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Threads returns the IDs of the logged threads
+func (l *ThreadLog) Threads() ([]string, error) {
+	entries, err := os.ReadDir(l.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var threadIDs []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".jsonl"); ok && !entry.IsDir() {
+			threadIDs = append(threadIDs, name)
+		}
+	}
+	return threadIDs, nil
+}
+
+func (l *ThreadLog) path(threadID string) string {
+	return filepath.Join(l.Dir, filepath.Base(threadID)+".jsonl")
+}
+
+func (l *ThreadLog) append(threadID string, entries []threadLogEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		buf.Write(append(line, '\n'))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := os.MkdirAll(l.Dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path(threadID), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// read replays the log of the thread, also returning its number of lines
+func (l *ThreadLog) read(threadID string) ([]Message, int, error) {
+	f, err := os.Open(l.path(threadID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	var messages []Message
+	index := map[string]int{}
+	lines := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		lines++
+		var entry threadLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A line cut by a crash mid-append is the last one; ignore it
+			continue
+		}
+		switch {
+		case entry.Message != nil:
+			if i, ok := index[entry.Message.ID]; ok {
+				messages[i] = *entry.Message
+			} else {
+				index[entry.Message.ID] = len(messages)
+				messages = append(messages, *entry.Message)
+			}
+		case entry.Deleted != "":
+			if i, ok := index[entry.Deleted]; ok {
+				messages = append(messages[:i], messages[i+1:]...)
+				delete(index, entry.Deleted)
+				for id, j := range index {
+					if j > i {
+						index[id] = j - 1
+					}
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read thread log: %w", err)
+	}
+	return messages, lines, nil
+}
+
+// SyncThreadLog appends the messages added to the thread since its last sync
+// to the log and returns how many were added
+func (c *Client) SyncThreadLog(ctx context.Context, log *ThreadLog, threadID string) (int, error) {
+	cursor, err := log.Cursor(threadID)
+	if err != nil {
+		return 0, err
+	}
+	snap, err := c.MessagesSince(ctx, threadID, cursor)
+	if err != nil {
+		return 0, err
+	}
+	if err := log.Append(threadID, snap.Items...); err != nil {
+		return 0, fmt.Errorf("failed to append to thread log: %w", err)
+	}
+	return len(snap.Items), nil
+}
@@ -0,0 +1,78 @@
+package openai
+
+import (
+	"context"
+	"slices"
+)
+
+// ThreadUsageReport is the token usage and estimated cost of the runs of a
+// thread, e.g. for per-conversation billing
+type ThreadUsageReport struct {
+	ThreadID         string    `json:"thread_id"`
+	Runs             []RunCost `json:"runs"` // Oldest first
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	ReasoningTokens  int64     `json:"reasoning_tokens"` // Part of the completion tokens
+	CachedTokens     int64     `json:"cached_tokens"`    // Part of the prompt tokens
+	TotalTokens      int64     `json:"total_tokens"`
+	Dollars          float64   `json:"dollars"`
+
+	// UnpricedModels lists the models of runs without a price in the pricing
+	// table. Their tokens are counted but cost nothing.
+	UnpricedModels []string `json:"unpriced_models,omitempty"`
+}
+
+// RunCost is the usage and estimated cost of a run
+type RunCost struct {
+	RunID   string   `json:"run_id"`
+	Model   string   `json:"model"`
+	Status  string   `json:"status"`
+	Usage   RunUsage `json:"usage"`
+	Dollars float64  `json:"dollars"`
+}
+
+// ThreadUsage lists the runs of a thread and adds up their usage, priced with
+// the client's pricing (see WithPricing). Runs still in progress have no usage
+// yet and count for nothing.
+func (c *Client) ThreadUsage(ctx context.Context, threadID string) (*ThreadUsageReport, error) {
+	pricing := c.costs.pricing
+	if pricing == nil {
+		pricing = DefaultPricing
+	}
+
+	report := &ThreadUsageReport{ThreadID: threadID}
+	for run, err := range c.AllRuns(ctx, threadID, WithLimit(100), WithOrder("asc")) {
+		if err != nil {
+			return nil, err
+		}
+
+		usage := run.Usage
+		cost := RunCost{RunID: run.ID, Model: run.Model, Status: run.Status, Usage: usage}
+		cached := 0
+		if usage.PromptTokensDetails != nil {
+			cached = usage.PromptTokensDetails.CachedTokens
+		}
+		if usage.CompletionTokensDetails != nil {
+			report.ReasoningTokens += int64(usage.CompletionTokensDetails.ReasoningTokens)
+		}
+		report.PromptTokens += int64(usage.PromptTokens)
+		report.CompletionTokens += int64(usage.CompletionTokens)
+		report.CachedTokens += int64(cached)
+		report.TotalTokens += int64(usage.TotalTokens)
+
+		if price, ok := pricing.Price(run.Model); ok {
+			cachedPrice := price.CachedInput
+			if cachedPrice == 0 {
+				cachedPrice = price.Input
+			}
+			cost.Dollars = (float64(usage.PromptTokens-cached)*price.Input +
+				float64(cached)*cachedPrice +
+				float64(usage.CompletionTokens)*price.Output) / 1e6
+			report.Dollars += cost.Dollars
+		} else if usage.TotalTokens > 0 && !slices.Contains(report.UnpricedModels, run.Model) {
+			report.UnpricedModels = append(report.UnpricedModels, run.Model)
+		}
+		report.Runs = append(report.Runs, cost)
+	}
+	return report, nil
+}
@@ -0,0 +1,169 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrTimeout is wrapped by the errors of requests cancelled by a client timeout
+var ErrTimeout = errors.New("request timed out")
+
+// Timeouts bounds the time spent on requests. A zero field means no timeout.
+// The request, upload and stream timeouts apply to each attempt, so a request
+// timing out is retried per the retry policy.
+type Timeouts struct {
+	Connect    time.Duration // Establishing the connection, TLS handshake included
+	Request    time.Duration // Sending a request and reading its response
+	Upload     time.Duration // Same as Request for file uploads, which are expected to be slower
+	StreamRead time.Duration // Longest wait for data on a streamed response, which has no overall timeout
+}
+
+// WithTimeout bounds the time of each request
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.timeouts.Request = d
+	}
+}
+
+// WithTimeouts sets all the timeouts of the client. The connect timeout
+// requires the client's transport to be an *http.Transport, which is the
+// default.
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(c *Client) {
+		c.timeouts = timeouts
+	}
+}
+
+// WithCallTimeout overrides the request or upload timeout of the client for
+// the calls made with the context, see WithRequestOptions
+func WithCallTimeout(d time.Duration) RequestOption {
+	return func(config *requestConfig) {
+		config.timeout = d
+	}
+}
+
+// applyConnectTimeout sets the connect timeout on a copy of the transport
+func (c *Client) applyConnectTimeout() {
+	if c.timeouts.Connect <= 0 {
+		return
+	}
+
+	var transport *http.Transport
+	switch t := c.httpClient.Transport.(type) {
+	case nil:
+		transport = newTransport()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return
+	}
+	dialer := &net.Dialer{Timeout: c.timeouts.Connect, KeepAlive: 30 * time.Second}
+	transport.DialContext = dialer.DialContext
+	transport.TLSHandshakeTimeout = c.timeouts.Connect
+
+	httpClient := *c.httpClient
+	httpClient.Transport = transport
+	c.httpClient = &httpClient
+}
+
+// attempt bounds a single try of a request
+type attempt struct {
+	timeout time.Duration
+	cancel  context.CancelCauseFunc
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+// newAttempt returns the request to send for one try, bound by the timeout
+// applying to it
+func (c *Client) newAttempt(req *http.Request) (*http.Request, *attempt) {
+	timeout := c.timeouts.Request
+	if c.timeouts.Upload > 0 && strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/") {
+		timeout = c.timeouts.Upload
+	}
+	if config, ok := req.Context().Value(requestOptionsKey{}).(*requestConfig); ok && config.timeout > 0 {
+		timeout = config.timeout
+	}
+
+	ctx, cancel := context.WithCancelCause(req.Context())
+	a := &attempt{timeout: timeout, cancel: cancel}
+	if timeout > 0 {
+		a.timer = time.AfterFunc(timeout, func() { cancel(ErrTimeout) })
+	}
+	return req.WithContext(ctx), a
+}
+
+// fail releases the attempt after an error, reporting timeouts as ErrTimeout
+func (a *attempt) fail(ctx context.Context, err error) error {
+	a.stop()
+	timedOut := errors.Is(context.Cause(ctx), ErrTimeout)
+	a.cancel(nil)
+	switch {
+	case !timedOut:
+		return err
+	case errors.Is(err, ErrTimeout):
+		return fmt.Errorf("%w after %s", err, a.timeout)
+	default:
+		return fmt.Errorf("%w after %s: %w", ErrTimeout, a.timeout, err)
+	}
+}
+
+// watch keeps the attempt alive until the response body is closed. Streamed
+// responses are bound by the stream read timeout instead of the request one.
+func (a *attempt) watch(resp *http.Response, streamRead time.Duration) {
+	body := &attemptBody{ReadCloser: resp.Body, attempt: a}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		a.stop()
+		if streamRead > 0 {
+			body.idle = streamRead
+			a.reset(streamRead)
+		}
+	}
+	resp.Body = body
+}
+
+func (a *attempt) stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+}
+
+func (a *attempt) reset(d time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.timer != nil {
+		a.timer.Stop()
+	}
+	a.timer = time.AfterFunc(d, func() { a.cancel(ErrTimeout) })
+}
+
+// attemptBody releases its attempt when closed
+type attemptBody struct {
+	io.ReadCloser
+	attempt *attempt
+	idle    time.Duration
+}
+
+func (b *attemptBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 && b.idle > 0 {
+		b.attempt.reset(b.idle)
+	}
+	return n, err
+}
+
+func (b *attemptBody) Close() error {
+	b.attempt.stop()
+	b.attempt.cancel(nil)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,51 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Timestamp is a time sent by the API as Unix seconds. The zero Timestamp
+// stands for 0, which the API uses for times not set yet.
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp returns the timestamp of t, truncated to the second
+func NewTimestamp(t time.Time) Timestamp {
+	if t.IsZero() {
+		return Timestamp{}
+	}
+	return Timestamp{Time: time.Unix(t.Unix(), 0)}
+}
+
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("0"), nil
+	}
+	return strconv.AppendInt(nil, t.Unix(), 10), nil
+}
+
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var seconds json.Number
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+	// Some compatible servers send fractional seconds
+	value, err := seconds.Float64()
+	if err != nil {
+		return err
+	}
+	if value == 0 {
+		*t = Timestamp{}
+		return nil
+	}
+	whole := int64(value)
+	t.Time = time.Unix(whole, int64((value-float64(whole))*1e9))
+	return nil
+}
@@ -0,0 +1,142 @@
+// Package tokenizer counts tokens the way the OpenAI models do, so inputs can be
+// validated and priced before calling the API. It implements the tiktoken
+// encodings with the vocabularies embedded in the binary, so counting never
+// downloads anything.
+package tokenizer
+
+import (
+	"strings"
+	"sync"
+
+	tiktoken "github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Encodings
+const (
+	O200kBase  = "o200k_base"  // gpt-4o, gpt-4.1, o-series
+	Cl100kBase = "cl100k_base" // gpt-4, gpt-3.5-turbo, embeddings
+)
+
+// MaxEmbeddingTokens is the input limit of the embedding models
+const MaxEmbeddingTokens = 8191
+
+// Tokens of formatting added by the chat format: per message, and to prime the
+// reply of the assistant
+const (
+	tokensPerMessage = 3
+	tokensPerReply   = 3
+)
+
+// oSeriesPrefixes lists the model families using o200k_base that the tiktoken
+// tables do not know yet
+var oSeriesPrefixes = []string{"o1", "o3", "o4", "gpt-5", "chatgpt-4o"}
+
+func init() {
+	tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+}
+
+var (
+	mu       sync.Mutex
+	encoders = map[string]*tiktoken.Tiktoken{}
+)
+
+// Encoding returns the name of the encoding of a model. Fine-tuned models, e.g.
+// "ft:gpt-4o-mini:org::id", use the encoding of their base model, and unknown
+// models the most recent encoding.
+func Encoding(model string) string {
+	if strings.HasPrefix(model, "ft:") {
+		model, _, _ = strings.Cut(strings.TrimPrefix(model, "ft:"), ":")
+	}
+	if encoding, ok := tiktoken.MODEL_TO_ENCODING[model]; ok {
+		return encoding
+	}
+	for prefix, encoding := range tiktoken.MODEL_PREFIX_TO_ENCODING {
+		if strings.HasPrefix(model, prefix) {
+			return encoding
+		}
+	}
+	for _, prefix := range oSeriesPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return O200kBase
+		}
+	}
+	return O200kBase
+}
+
+// encoder returns the encoder of a model, loading its vocabulary on first use
+func encoder(model string) (*tiktoken.Tiktoken, error) {
+	name := Encoding(model)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if enc, ok := encoders[name]; ok {
+		return enc, nil
+	}
+	enc, err := tiktoken.GetEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+	encoders[name] = enc
+	return enc, nil
+}
+
+// Count returns the number of tokens of text for model. Special tokens such as
+// "<|endoftext|>" are counted as plain text.
+func Count(model, text string) (int, error) {
+	enc, err := encoder(model)
+	if err != nil {
+		return 0, err
+	}
+	return len(enc.EncodeOrdinary(text)), nil
+}
+
+// CountMessages returns the number of prompt tokens of chat messages, including
+// the formatting tokens added around each message. Images and tool definitions
+// are not counted.
+func CountMessages(model string, messages []openai.ChatCompletionMessage) (int, error) {
+	enc, err := encoder(model)
+	if err != nil {
+		return 0, err
+	}
+
+	tokens := tokensPerReply
+	for _, msg := range messages {
+		tokens += tokensPerMessage
+		tokens += len(enc.EncodeOrdinary(msg.Role))
+		tokens += len(enc.EncodeOrdinary(msg.Content))
+		for _, part := range msg.MultiContent {
+			tokens += len(enc.EncodeOrdinary(part.Text))
+		}
+		if msg.Name != "" {
+			tokens += len(enc.EncodeOrdinary(msg.Name)) + 1
+		}
+	}
+	return tokens, nil
+}
+
+// Truncate returns the longest prefix of text fitting in maxTokens tokens, e.g.
+// MaxEmbeddingTokens for an embeddings input
+func Truncate(model, text string, maxTokens int) (string, error) {
+	enc, err := encoder(model)
+	if err != nil {
+		return "", err
+	}
+	tokens := enc.EncodeOrdinary(text)
+	if len(tokens) <= maxTokens {
+		return text, nil
+	}
+	return enc.Decode(tokens[:maxTokens]), nil
+}
+
+// Counter counts the tokens of text, falling back to an approximation of 4
+// bytes per token if the encoding cannot be loaded. It can be passed to
+// openai.EstimateBatch.
+func Counter(model, text string) int {
+	n, err := Count(model, text)
+	if err != nil {
+		return (len(text) + 3) / 4
+	}
+	return n
+}
@@ -0,0 +1,135 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// ToolCallHandler is called with a tool call whose arguments are fully assembled
+type ToolCallHandler func(call openai.ToolCall) error
+
+// ToolCallAccumulator assembles streamed tool call deltas. The model streams tool
+// calls one after the other, so a call is complete as soon as a delta for a later
+// call arrives; the handler then fires right away instead of waiting for the end
+// of the response. The last call is completed by the finish reason or Flush.
+type ToolCallAccumulator struct {
+	handler ToolCallHandler
+	calls   map[int]*openai.ToolCall
+	fired   map[int]bool
+	current int
+}
+
+// NewToolCallAccumulator returns an accumulator calling handler for each completed call
+func NewToolCallAccumulator(handler ToolCallHandler) *ToolCallAccumulator {
+	return &ToolCallAccumulator{
+		handler: handler,
+		calls:   map[int]*openai.ToolCall{},
+		fired:   map[int]bool{},
+		current: -1,
+	}
+}
+
+// AddChunk feeds a chat completion stream chunk to the accumulator
+func (a *ToolCallAccumulator) AddChunk(chunk openai.ChatCompletionStreamResponse) error {
+	for _, choice := range chunk.Choices {
+		for _, delta := range choice.Delta.ToolCalls {
+			if err := a.Add(delta); err != nil {
+				return err
+			}
+		}
+		if choice.FinishReason != "" {
+			if err := a.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Add merges a tool call delta. Deltas without an index belong to the call
+// currently being streamed.
+func (a *ToolCallAccumulator) Add(delta openai.ToolCall) error {
+	index := a.current
+	if delta.Index != nil {
+		index = *delta.Index
+	}
+	if index < 0 {
+		index = 0
+	}
+
+	// A delta for a new call completes the previous ones
+	if index != a.current {
+		if err := a.complete(func(i int) bool { return i < index }); err != nil {
+			return err
+		}
+		a.current = index
+	}
+
+	call, ok := a.calls[index]
+	if !ok {
+		call = &openai.ToolCall{Index: &index, Type: openai.ToolTypeFunction}
+		a.calls[index] = call
+	}
+	if delta.ID != "" {
+		call.ID = delta.ID
+	}
+	if delta.Type != "" {
+		call.Type = delta.Type
+	}
+	if delta.Function.Name != "" {
+		call.Function.Name = delta.Function.Name
+	}
+	call.Function.Arguments += delta.Function.Arguments
+	return nil
+}
+
+// Flush completes all the calls not handled yet. Call it when the stream ends
+// without a finish reason.
+func (a *ToolCallAccumulator) Flush() error {
+	return a.complete(func(int) bool { return true })
+}
+
+// Calls returns the assembled calls ordered by index
+func (a *ToolCallAccumulator) Calls() []openai.ToolCall {
+	calls := make([]openai.ToolCall, 0, len(a.calls))
+	for _, index := range a.indexes() {
+		calls = append(calls, *a.calls[index])
+	}
+	return calls
+}
+
+// complete fires the handler, in index order, for the calls matching the filter
+func (a *ToolCallAccumulator) complete(match func(index int) bool) error {
+	for _, index := range a.indexes() {
+		if a.fired[index] || !match(index) {
+			continue
+		}
+		a.fired[index] = true
+
+		call := a.calls[index]
+		if call.Function.Arguments == "" {
+			call.Function.Arguments = "{}"
+		}
+		if !json.Valid([]byte(call.Function.Arguments)) {
+			return fmt.Errorf("tool call %s (%s) has invalid JSON arguments: %s", call.ID, call.Function.Name, call.Function.Arguments)
+		}
+		if a.handler != nil {
+			if err := a.handler(*call); err != nil {
+				return fmt.Errorf("tool call %s (%s) failed: %w", call.ID, call.Function.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (a *ToolCallAccumulator) indexes() []int {
+	indexes := make([]int, 0, len(a.calls))
+	for index := range a.calls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
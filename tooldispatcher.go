@@ -0,0 +1,338 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ToolHandler executes a function call of a run given its JSON arguments, and
+// returns the output sent back to the model. Handlers should return when ctx
+// is done: a handler outliving its timeout keeps running in the background.
+type ToolHandler func(ctx context.Context, arguments string) (string, error)
+
+// ToolDispatcher executes the function calls of runs with registered Go
+// handlers and submits their outputs until the runs end, e.g.
+//
+//	dispatcher := NewToolDispatcher(client)
+//	dispatcher.Register("get_weather", getWeather, WithToolTimeout(5*time.Second))
+//	run, err := dispatcher.Run(ctx, threadID, runID)
+//
+// The calls a run requires at once are executed concurrently and their
+// outputs submitted together, in the order of the calls.
+//
+// A call that cannot be executed does not fail the run: the model gets a
+// structured error as output, e.g. {"error":{"type":"timeout","message":"..."}},
+// with the type "unknown_tool", "not_allowed", "denied", "timeout",
+// "cancelled", "panic" or "handler_error". It is safe for concurrent use.
+type ToolDispatcher struct {
+	Timeout      time.Duration // Time a handler may run, unless set by WithToolTimeout; no limit when 0
+	AllowedTools []string      // Tools allowed to run, all registered tools when empty
+	PollInterval time.Duration // Interval between run status checks, one second when 0
+	MaxParallel  int           // Calls of a run executed at once, 4 when 0
+
+	client *Client
+
+	mu    sync.RWMutex
+	tools map[string]*dispatchedTool
+}
+
+// dispatchedTool is a registered handler and its policy
+type dispatchedTool struct {
+	handler ToolHandler
+	timeout time.Duration
+	slots   chan struct{} // Executions in progress, nil for no limit
+	approve ApprovalFunc  // nil when calls run without approval
+}
+
+// Approval is the decision on a tool call requiring approval
+type Approval int
+
+const (
+	ApprovalPending Approval = iota // Decided later: the run is parked, see ToolDispatcher.Resume
+	Approved
+	Denied
+)
+
+// ApprovalRequest is a tool call awaiting approval
+type ApprovalRequest struct {
+	ThreadID string
+	RunID    string
+	Call     RunToolCall
+}
+
+// ApprovalFunc decides whether a tool call may run. It may block, e.g. until
+// a reviewer answers, or return ApprovalPending to park the run and free the
+// worker until the decision is made.
+type ApprovalFunc func(ctx context.Context, req ApprovalRequest) (Approval, error)
+
+// ErrRunParked is wrapped by the errors of runs parked until tool calls are
+// approved
+var ErrRunParked = errors.New("run parked awaiting approval")
+
+// ParkedRunError is returned when tool calls of a run await approval. None of
+// the calls of the run were executed; resume it with ToolDispatcher.Resume
+// once they are decided.
+type ParkedRunError struct {
+	ThreadID string
+	RunID    string
+	Pending  []RunToolCall
+}
+
+func (e *ParkedRunError) Error() string {
+	return fmt.Sprintf("run %s parked: %d tool calls awaiting approval", e.RunID, len(e.Pending))
+}
+
+// Is makes errors.Is(err, ErrRunParked) hold for ParkedRunError
+func (e *ParkedRunError) Is(target error) bool {
+	return target == ErrRunParked
+}
+
+// ToolOption configures a tool registered in a ToolDispatcher
+type ToolOption func(*dispatchedTool)
+
+// WithToolTimeout bounds the time the tool may run, overriding the timeout of
+// the dispatcher; 0 removes the limit. Waiting for a free slot counts.
+func WithToolTimeout(timeout time.Duration) ToolOption {
+	return func(t *dispatchedTool) {
+		t.timeout = timeout
+	}
+}
+
+// WithToolConcurrency caps the executions of the tool in progress at once,
+// across all the runs of the dispatcher, e.g. for a handler calling a
+// rate-limited backend
+func WithToolConcurrency(n int) ToolOption {
+	return func(t *dispatchedTool) {
+		if n > 0 {
+			t.slots = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithApproval makes the calls of the tool wait for approve before running,
+// e.g. for tools with side effects such as refunds. A denied call returns an
+// error to the model.
+func WithApproval(approve ApprovalFunc) ToolOption {
+	return func(t *dispatchedTool) {
+		t.approve = approve
+	}
+}
+
+// NewToolDispatcher returns a dispatcher without tools, calling the API with
+// client
+func NewToolDispatcher(client *Client) *ToolDispatcher {
+	return &ToolDispatcher{client: client, tools: map[string]*dispatchedTool{}}
+}
+
+// Register makes the dispatcher execute the calls of the function name with
+// handler, replacing any previous handler
+func (d *ToolDispatcher) Register(name string, handler ToolHandler, opts ...ToolOption) {
+	tool := &dispatchedTool{handler: handler, timeout: -1}
+	for _, opt := range opts {
+		opt(tool)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.tools[name] = tool
+}
+
+// Run waits for the run and executes its function calls each time it requires
+// action, until it completes, fails, expires, is cancelled or is incomplete.
+// When calls await approval, it returns a *ParkedRunError.
+func (d *ToolDispatcher) Run(ctx context.Context, threadID, runID string) (*Run, error) {
+	return d.Resume(ctx, threadID, runID, nil)
+}
+
+// Resume continues a parked run with the decisions on its calls, by tool call
+// ID, then runs it like Run. Calls requiring approval without a decision are
+// submitted to their ApprovalFunc again. A run requiring action expires after
+// ten minutes, so parked runs must be decided quickly.
+func (d *ToolDispatcher) Resume(ctx context.Context, threadID, runID string, decisions map[string]Approval) (*Run, error) {
+	for {
+		run, err := d.client.WaitForRun(ctx, threadID, runID, d.PollInterval)
+		if err != nil {
+			return nil, err
+		}
+		if run.Status != "requires_action" || run.RequiredAction == nil {
+			return run, nil
+		}
+
+		calls := run.RequiredAction.SubmitToolOutputs.ToolCalls
+		denied, err := d.approve(ctx, run, calls, decisions)
+		if err != nil {
+			return nil, err
+		}
+		outputs := d.execute(ctx, calls, denied)
+		if _, err := d.client.SubmitToolOutputs(ctx, threadID, runID, outputs); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// approve decides the calls requiring approval, returning the IDs of the denied
+// ones. It returns a *ParkedRunError when a decision is pending.
+func (d *ToolDispatcher) approve(ctx context.Context, run *Run, calls []RunToolCall, decisions map[string]Approval) (map[string]bool, error) {
+	denied := map[string]bool{}
+	var pending []RunToolCall
+	for _, call := range calls {
+		tool, ok := d.tool(call.Function.Name)
+		if !ok || tool.approve == nil || !d.allowed(call.Function.Name) {
+			continue
+		}
+
+		decision := decisions[call.ID]
+		if decision == ApprovalPending {
+			var err error
+			decision, err = tool.approve(ctx, ApprovalRequest{ThreadID: run.ThreadID, RunID: run.ID, Call: call})
+			if err != nil {
+				return nil, fmt.Errorf("approval of tool call %s failed: %w", call.ID, err)
+			}
+		}
+		switch decision {
+		case ApprovalPending:
+			pending = append(pending, call)
+		case Denied:
+			denied[call.ID] = true
+		}
+	}
+
+	if len(pending) > 0 {
+		d.client.logger.InfoContext(ctx, "run parked awaiting approval", "thread_id", run.ThreadID, "run_id", run.ID, "pending", len(pending))
+		return nil, &ParkedRunError{ThreadID: run.ThreadID, RunID: run.ID, Pending: pending}
+	}
+	return denied, nil
+}
+
+// execute runs the calls, except the denied ones, at most MaxParallel at once,
+// and returns their outputs in the order of the calls
+func (d *ToolDispatcher) execute(ctx context.Context, calls []RunToolCall, denied map[string]bool) []ToolOutput {
+	parallel := d.MaxParallel
+	if parallel <= 0 {
+		parallel = 4
+	}
+
+	outputs := make([]ToolOutput, len(calls))
+	slots := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		outputs[i].ToolCallID = call.ID
+		if denied[call.ID] {
+			outputs[i].Output = toolErrorOutput("denied", fmt.Sprintf("the call of tool %q was denied", call.Function.Name))
+			continue
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			outputs[i].Output = d.call(ctx, call)
+		}()
+	}
+	wg.Wait()
+	return outputs
+}
+
+// tool returns the registered tool called name
+func (d *ToolDispatcher) tool(name string) (*dispatchedTool, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	tool, ok := d.tools[name]
+	return tool, ok
+}
+
+// allowed reports whether the allowlist lets the tool run
+func (d *ToolDispatcher) allowed(name string) bool {
+	return len(d.AllowedTools) == 0 || slices.Contains(d.AllowedTools, name)
+}
+
+// call executes a call under the policy of its tool, returning the handler's
+// output or a structured error
+func (d *ToolDispatcher) call(ctx context.Context, call RunToolCall) string {
+	name := call.Function.Name
+	tool, ok := d.tool(name)
+	if !ok {
+		return toolErrorOutput("unknown_tool", fmt.Sprintf("no tool named %q", name))
+	}
+	if !d.allowed(name) {
+		d.client.logger.WarnContext(ctx, "tool call not allowed", "tool", name, "tool_call_id", call.ID)
+		return toolErrorOutput("not_allowed", fmt.Sprintf("tool %q is not allowed", name))
+	}
+
+	timeout := tool.timeout
+	if timeout < 0 {
+		timeout = d.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if tool.slots != nil {
+		select {
+		case tool.slots <- struct{}{}:
+		case <-ctx.Done():
+			return d.contextErrorOutput(ctx, call, "waiting for a free slot")
+		}
+	}
+
+	type result struct {
+		output   string
+		err      error
+		panicked interface{} // Value the handler panicked with
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer func() {
+			if tool.slots != nil {
+				<-tool.slots
+			}
+			if p := recover(); p != nil {
+				d.client.logger.ErrorContext(ctx, "tool handler panicked", "tool", name, "tool_call_id", call.ID, "panic", p, "stack", string(debug.Stack()))
+				done <- result{panicked: p}
+			}
+		}()
+		output, err := tool.handler(ctx, call.Function.Arguments)
+		done <- result{output: output, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		switch {
+		case r.panicked != nil:
+			return toolErrorOutput("panic", fmt.Sprintf("tool %q panicked: %v", name, r.panicked))
+		case r.err != nil:
+			d.client.logger.WarnContext(ctx, "tool handler failed", "tool", name, "tool_call_id", call.ID, "error", r.err)
+			return toolErrorOutput("handler_error", r.err.Error())
+		}
+		return r.output
+	case <-ctx.Done():
+		return d.contextErrorOutput(ctx, call, "running")
+	}
+}
+
+// contextErrorOutput reports a call whose context ended while it was waiting
+// or running
+func (d *ToolDispatcher) contextErrorOutput(ctx context.Context, call RunToolCall, doing string) string {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		d.client.logger.WarnContext(ctx, "tool call timed out", "tool", call.Function.Name, "tool_call_id", call.ID)
+		return toolErrorOutput("timeout", fmt.Sprintf("tool %q timed out %s", call.Function.Name, doing))
+	}
+	return toolErrorOutput("cancelled", fmt.Sprintf("tool %q was cancelled %s", call.Function.Name, doing))
+}
+
+// toolErrorOutput returns the output telling the model a call failed
+func toolErrorOutput(kind, message string) string {
+	output, _ := json.Marshal(map[string]interface{}{
+		"error": map[string]string{"type": kind, "message": message},
+	})
+	return string(output)
+}
@@ -0,0 +1,94 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"unicode/utf8"
+)
+
+// ToolOutputLimit caps the size of the tool outputs submitted to runs. An
+// output that does not fit in the context window of the model fails the run,
+// so oversized outputs are summarized or truncated before being submitted.
+type ToolOutputLimit struct {
+	MaxTokens int          // Largest output submitted, in tokens
+	Count     TokenCounter // Counts the tokens of an output, approximated from its length when nil
+
+	// Summarize shortens an output exceeding MaxTokens, e.g. with a chat
+	// completion. When nil or failing, the output is truncated instead.
+	Summarize func(ctx context.Context, output string, maxTokens int) (string, error)
+}
+
+// WithToolOutputLimit shortens the outputs passed to SubmitToolOutputs that
+// exceed limit.MaxTokens, marking them as summarized or truncated so the model
+// knows it does not see the whole result
+func WithToolOutputLimit(limit ToolOutputLimit) Option {
+	return func(c *Client) {
+		c.toolOutputLimit = limit
+	}
+}
+
+// TruncateToolOutput cuts output to at most maxTokens tokens, marker
+// included, ending it with a marker noting the truncation. Tokens are counted
+// with count, or approximated from the length of the text when nil.
+func TruncateToolOutput(output string, maxTokens int, count TokenCounter) string {
+	if count == nil {
+		count = approximateTokens
+	}
+	total := count("", output)
+	if maxTokens <= 0 || total <= maxTokens {
+		return output
+	}
+
+	marker := func(kept string) string {
+		return fmt.Sprintf("%s\n\n[truncated: showing the first %d of %d tokens]", kept, count("", kept), total)
+	}
+
+	// Longest prefix fitting with its marker, cut on a rune boundary
+	prefix := func(n int) string {
+		for n > 0 && n < len(output) && !utf8.RuneStart(output[n]) {
+			n--
+		}
+		return output[:n]
+	}
+	n := sort.Search(len(output)+1, func(n int) bool {
+		return count("", marker(prefix(n))) > maxTokens
+	})
+	return marker(prefix(max(n-1, 0)))
+}
+
+// limitToolOutputs shortens the outputs exceeding the tool output limit,
+// leaving outputs untouched
+func (c *Client) limitToolOutputs(ctx context.Context, outputs []ToolOutput) []ToolOutput {
+	limit := c.toolOutputLimit
+	if limit.MaxTokens <= 0 {
+		return outputs
+	}
+	count := limit.Count
+	if count == nil {
+		count = approximateTokens
+	}
+
+	limited := slices.Clone(outputs)
+	for i, output := range limited {
+		tokens := count("", output.Output)
+		if tokens <= limit.MaxTokens {
+			continue
+		}
+
+		if limit.Summarize != nil {
+			summary, err := limit.Summarize(ctx, output.Output, limit.MaxTokens)
+			if err == nil {
+				summary = fmt.Sprintf("[summarized: the output was %d tokens]\n\n%s", tokens, summary)
+				limited[i].Output = TruncateToolOutput(summary, limit.MaxTokens, count)
+				c.logger.InfoContext(ctx, "tool output summarized", "tool_call_id", output.ToolCallID, "tokens", tokens)
+				continue
+			}
+			c.logger.WarnContext(ctx, "failed to summarize tool output, truncating it", "tool_call_id", output.ToolCallID, "error", err)
+		}
+		limited[i].Output = TruncateToolOutput(output.Output, limit.MaxTokens, count)
+		c.logger.InfoContext(ctx, "tool output truncated", "tool_call_id", output.ToolCallID, "tokens", tokens, "max_tokens", limit.MaxTokens)
+	}
+	return limited
+}
@@ -0,0 +1,138 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// ErrToolResourcesConflict is returned when the tool resources of an assistant
+// keep changing between the read and the write of an attach or detach helper
+var ErrToolResourcesConflict = errors.New("tool resources modified concurrently")
+
+// toolResourcesAttempts is the number of read-modify-write cycles tried before
+// giving up with ErrToolResourcesConflict
+const toolResourcesAttempts = 3
+
+// AttachVectorStore makes the file_search tool of an assistant search a vector
+// store, keeping the vector stores already attached. The assistant must have the
+// file_search tool enabled for the store to be searched.
+func (c *Client) AttachVectorStore(ctx context.Context, assistantID, vectorStoreID string) (*Assistant, error) {
+	return c.updateToolResources(ctx, assistantID, func(r *ToolResources) {
+		if r.FileSearch == nil {
+			r.FileSearch = &FileSearchResources{}
+		}
+		r.FileSearch.VectorStoreIDs = appendMissing(r.FileSearch.VectorStoreIDs, vectorStoreID)
+	})
+}
+
+// DetachVectorStore stops the file_search tool of an assistant from searching a
+// vector store. The vector store itself is not deleted.
+func (c *Client) DetachVectorStore(ctx context.Context, assistantID, vectorStoreID string) (*Assistant, error) {
+	return c.updateToolResources(ctx, assistantID, func(r *ToolResources) {
+		if r.FileSearch != nil {
+			r.FileSearch.VectorStoreIDs = removeValue(r.FileSearch.VectorStoreIDs, vectorStoreID)
+		}
+	})
+}
+
+// AttachCodeInterpreterFile makes a file available to the code_interpreter tool
+// of an assistant, keeping the files already attached
+func (c *Client) AttachCodeInterpreterFile(ctx context.Context, assistantID, fileID string) (*Assistant, error) {
+	return c.updateToolResources(ctx, assistantID, func(r *ToolResources) {
+		if r.CodeInterpreter == nil {
+			r.CodeInterpreter = &CodeInterpreterResources{}
+		}
+		r.CodeInterpreter.FileIDs = appendMissing(r.CodeInterpreter.FileIDs, fileID)
+	})
+}
+
+// DetachCodeInterpreterFile removes a file from the code_interpreter tool of an
+// assistant. The file itself is not deleted.
+func (c *Client) DetachCodeInterpreterFile(ctx context.Context, assistantID, fileID string) (*Assistant, error) {
+	return c.updateToolResources(ctx, assistantID, func(r *ToolResources) {
+		if r.CodeInterpreter != nil {
+			r.CodeInterpreter.FileIDs = removeValue(r.CodeInterpreter.FileIDs, fileID)
+		}
+	})
+}
+
+// updateToolResources applies edit to the tool resources of an assistant. The
+// API has no conditional updates, so the assistant is read again right before
+// the write; if its tool resources changed in between, the edit is retried on
+// the new value to avoid overwriting a concurrent change.
+func (c *Client) updateToolResources(ctx context.Context, assistantID string, edit func(*ToolResources)) (*Assistant, error) {
+	assistant, err := c.RetrieveAssistant(ctx, assistantID)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < toolResourcesAttempts; attempt++ {
+		resources := copyToolResources(assistant.ToolResources)
+		edit(resources)
+		if reflect.DeepEqual(resources, copyToolResources(assistant.ToolResources)) {
+			return assistant, nil
+		}
+
+		current, err := c.RetrieveAssistant(ctx, assistantID)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(copyToolResources(current.ToolResources), copyToolResources(assistant.ToolResources)) {
+			assistant = current
+			continue
+		}
+
+		var updated Assistant
+		body := map[string]interface{}{"tool_resources": toolResourcesPayload(resources)}
+		if err := c.do(ctx, "POST", "/assistants/"+assistantID, body, &updated); err != nil {
+			return nil, fmt.Errorf("tool resources update failed: %w", err)
+		}
+		c.logger.InfoContext(ctx, "assistant tool resources updated", "assistant_id", assistantID)
+		return &updated, nil
+	}
+	return nil, fmt.Errorf("%w: assistant %s", ErrToolResourcesConflict, assistantID)
+}
+
+// copyToolResources returns a deep copy of r, never nil
+func copyToolResources(r *ToolResources) *ToolResources {
+	out := &ToolResources{}
+	if r == nil {
+		return out
+	}
+	if r.CodeInterpreter != nil {
+		out.CodeInterpreter = &CodeInterpreterResources{FileIDs: slices.Clone(r.CodeInterpreter.FileIDs)}
+	}
+	if r.FileSearch != nil {
+		out.FileSearch = &FileSearchResources{VectorStoreIDs: slices.Clone(r.FileSearch.VectorStoreIDs)}
+	}
+	return out
+}
+
+// toolResourcesPayload encodes r for an update. Unlike ToolResources, empty
+// lists are kept so detaching the last resource clears it.
+func toolResourcesPayload(r *ToolResources) map[string]interface{} {
+	payload := map[string]interface{}{}
+	if r.CodeInterpreter != nil {
+		payload["code_interpreter"] = map[string][]string{"file_ids": append([]string{}, r.CodeInterpreter.FileIDs...)}
+	}
+	if r.FileSearch != nil {
+		payload["file_search"] = map[string][]string{"vector_store_ids": append([]string{}, r.FileSearch.VectorStoreIDs...)}
+	}
+	return payload
+}
+
+// appendMissing appends value to values unless already present
+func appendMissing(values []string, value string) []string {
+	if slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+// removeValue returns values without value
+func removeValue(values []string, value string) []string {
+	return slices.DeleteFunc(values, func(v string) bool { return v == value })
+}
@@ -0,0 +1,108 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// Usage is the number of tokens consumed by a request
+type Usage struct {
+	Endpoint         string // EndpointChat, EndpointRun or EndpointEmbeddings
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// TotalTokens returns the prompt and completion tokens
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// WithUsageHook calls fn with the token usage of every chat and embeddings
+// request, and of every run once WaitForRun sees it finish, once per run.
+// Several hooks can be installed; they may be called from several goroutines
+// at once.
+func WithUsageHook(fn func(ctx context.Context, usage Usage)) Option {
+	return func(c *Client) {
+		c.usageHooks = append(c.usageHooks, fn)
+	}
+}
+
+// recordUsage reports the usage of a request to the hooks
+func (c *Client) recordUsage(ctx context.Context, usage Usage) {
+	if usage.TotalTokens() == 0 {
+		return
+	}
+	c.costs.record(usage)
+	for _, fn := range c.usageHooks {
+		fn(ctx, usage)
+	}
+}
+
+// recordRunUsage reports the usage of a run that ended. Runs requiring action
+// are not done yet, and a run waited for several times is recorded once.
+func (c *Client) recordRunUsage(ctx context.Context, run *Run) {
+	switch run.Status {
+	case "completed", "failed", "cancelled", "expired", "incomplete":
+	default:
+		return
+	}
+	if !c.recordedRuns.add(run.ID) {
+		return
+	}
+	c.recordUsage(ctx, Usage{
+		Endpoint:         EndpointRun,
+		Model:            run.Model,
+		PromptTokens:     run.Usage.PromptTokens,
+		CompletionTokens: run.Usage.CompletionTokens,
+	})
+}
+
+// maxRecordedRuns bounds the run IDs remembered to record each run once
+const maxRecordedRuns = 10000
+
+// runSet remembers the most recent run IDs, forgetting the oldest past
+// maxRecordedRuns
+type runSet struct {
+	mu    sync.Mutex
+	ids   map[string]bool
+	order []string
+}
+
+// add reports whether id was not in the set, adding it
+func (s *runSet) add(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ids[id] {
+		return false
+	}
+	if s.ids == nil {
+		s.ids = map[string]bool{}
+	}
+	if len(s.order) == maxRecordedRuns {
+		delete(s.ids, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.ids[id] = true
+	s.order = append(s.order, id)
+	return true
+}
+
+// withRequestGuard makes the client call guard before sending any request; a
+// non-nil error aborts the request
+func withRequestGuard(guard func(req *http.Request) error) Option {
+	return func(c *Client) {
+		c.requestGuards = append(c.requestGuards, guard)
+	}
+}
+
+// checkGuards runs the request guards
+func (c *Client) checkGuards(req *http.Request) error {
+	for _, guard := range c.requestGuards {
+		if err := guard(req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
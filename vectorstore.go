@@ -1,13 +1,8 @@
 package openai
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"net/url"
-	"strconv"
 )
 
 // ExpirationPolicy represents the expiration policy for a vector store
@@ -27,7 +22,7 @@ type ChunkingStrategy struct {
 type CreateVectorStoreParams struct {
 	Name             string            `json:"name,omitempty"`
 	FileIDs          []string          `json:"file_ids,omitempty"`
-	Metadata         map[string]string `json:"metadata,omitempty"`
+	Metadata         Metadata          `json:"metadata,omitempty"`
 	ExpiresAfter     *ExpirationPolicy `json:"expires_after,omitempty"`
 	ChunkingStrategy *ChunkingStrategy `json:"chunking_strategy,omitempty"`
 }
@@ -36,54 +31,25 @@ type CreateVectorStoreParams struct {
 type VectorStore struct {
 	ID           string            `json:"id"`
 	Object       string            `json:"object"`
-	CreatedAt    int64             `json:"created_at"`
+	CreatedAt    Timestamp         `json:"created_at"`
 	Name         string            `json:"name"`
 	UsageBytes   int64             `json:"usage_bytes"`
 	Status       string            `json:"status"`
 	ExpiresAfter *ExpirationPolicy `json:"expires_after,omitempty"`
-	Metadata     map[string]string `json:"metadata,omitempty"`
+	Metadata     Metadata          `json:"metadata,omitempty"`
 	FileCounts   map[string]int    `json:"file_counts,omitempty"`
-	ExpiresAt    *int64            `json:"expires_at,omitempty"`
-	LastActiveAt *int64            `json:"last_active_at,omitempty"`
+	ExpiresAt    *Timestamp        `json:"expires_at,omitempty"`
+	LastActiveAt *Timestamp        `json:"last_active_at,omitempty"`
 }
 
 // CreateVectorStore creates a new vector store in OpenAI’s storage
-func CreateVectorStore(params *CreateVectorStoreParams) (*VectorStore, error) {
-	// Marshal the parameters to JSON
-	payloadBytes, err := json.Marshal(params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal vector store payload: %w", err)
-	}
-
-	// Send request to vector store API
-	url := "https://api.openai.com/v1/vector_stores"
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create vector store request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("vector store request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("vector store creation failed with status %s: %s", resp.Status, string(body))
-	}
-
-	// Decode response to get vector store information
+func (c *Client) CreateVectorStore(ctx context.Context, params *CreateVectorStoreParams) (*VectorStore, error) {
 	var vectorStoreResp VectorStore
-	if err := json.NewDecoder(resp.Body).Decode(&vectorStoreResp); err != nil {
-		return nil, fmt.Errorf("failed to decode vector store response: %w", err)
+	if err := c.do(ctx, "POST", "/vector_stores", params, &vectorStoreResp); err != nil {
+		return nil, fmt.Errorf("vector store creation failed: %w", err)
 	}
 
-	fmt.Printf("Vector store created successfully with ID: %s\n", vectorStoreResp.ID)
+	c.logger.InfoContext(ctx, "vector store created", "vector_store_id", vectorStoreResp.ID)
 	return &vectorStoreResp, nil
 }
 
@@ -92,121 +58,99 @@ type VectorStoreListResponse struct {
 	Data []VectorStore `json:"data"`
 }
 
-// ListVectorStores lists vector stores with optional parameters for pagination and sorting
-func ListVectorStores(limit int, order, after, before string) ([]VectorStore, error) {
-	// Prepare query parameters
-	params := url.Values{}
-	if limit > 0 {
-		params.Add("limit", strconv.Itoa(limit))
-	}
-	if order != "" {
-		params.Add("order", order)
-	}
-	if after != "" {
-		params.Add("after", after)
-	}
-	if before != "" {
-		params.Add("before", before)
-	}
-
-	// Build the request URL
-	baseURL := "https://api.openai.com/v1/vector_stores"
-	requestURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
-
-	// Create the request
-	req, err := http.NewRequest("GET", requestURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create list vector stores request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey) // Authorization header
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
-
-	// Execute the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("list vector stores request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("list vector stores failed with status %s: %s", resp.Status, string(body))
-	}
-
-	// Parse the response
+// ListVectorStores lists vector stores, e.g.
+// ListVectorStores(ctx, WithLimit(20), WithOrder("desc"))
+func (c *Client) ListVectorStores(ctx context.Context, opts ...ListOption) ([]VectorStore, error) {
 	var vectorStoreList VectorStoreListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&vectorStoreList); err != nil {
-		return nil, fmt.Errorf("failed to decode list vector stores response: %w", err)
+	if err := c.do(ctx, "GET", listPath("/vector_stores", opts), nil, &vectorStoreList); err != nil {
+		return nil, fmt.Errorf("list vector stores failed: %w", err)
 	}
-
 	return vectorStoreList.Data, nil
 }
 
 // RetrieveVectorStore retrieves details of a specific vector store
-func RetrieveVectorStore(vectorStoreID string) (*VectorStore, error) {
-	// Build the request URL
-	url := fmt.Sprintf("https://api.openai.com/v1/vector_stores/%s", vectorStoreID)
-
-	// Create the request
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create retrieve vector store request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
-
-	// Execute the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("retrieve vector store request failed: %w", err)
+func (c *Client) RetrieveVectorStore(ctx context.Context, vectorStoreID string) (*VectorStore, error) {
+	var vectorStore VectorStore
+	if err := c.do(ctx, "GET", "/vector_stores/"+vectorStoreID, nil, &vectorStore); err != nil {
+		return nil, fmt.Errorf("retrieve vector store failed: %w", err)
 	}
-	defer resp.Body.Close()
+	return &vectorStore, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("retrieve vector store failed with status %s: %s", resp.Status, string(body))
-	}
+// ModifyVectorStoreParams defines the vector store fields to update. Fields left
+// empty are not changed; Metadata replaces the existing metadata.
+type ModifyVectorStoreParams struct {
+	Name         string            `json:"name,omitempty"`
+	ExpiresAfter *ExpirationPolicy `json:"expires_after,omitempty"`
+	Metadata     Metadata          `json:"metadata,omitempty"`
+}
 
-	// Parse the response
+// ModifyVectorStore updates the name, expiration policy or metadata of a vector store
+func (c *Client) ModifyVectorStore(ctx context.Context, vectorStoreID string, params *ModifyVectorStoreParams) (*VectorStore, error) {
 	var vectorStore VectorStore
-	if err := json.NewDecoder(resp.Body).Decode(&vectorStore); err != nil {
-		return nil, fmt.Errorf("failed to decode retrieve vector store response: %w", err)
+	if err := c.do(ctx, "POST", "/vector_stores/"+vectorStoreID, params, &vectorStore); err != nil {
+		return nil, fmt.Errorf("modify vector store failed: %w", err)
 	}
-
 	return &vectorStore, nil
 }
 
 // DeleteVectorStore deletes a specific vector store
-func DeleteVectorStore(vectorStoreID string) error {
-	// Build the request URL
-	url := fmt.Sprintf("https://api.openai.com/v1/vector_stores/%s", vectorStoreID)
-
-	// Create the request
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create delete vector store request: %w", err)
+func (c *Client) DeleteVectorStore(ctx context.Context, vectorStoreID string) error {
+	if err := c.do(ctx, "DELETE", "/vector_stores/"+vectorStoreID, nil, nil); err != nil {
+		return fmt.Errorf("delete vector store failed: %w", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
-
-	// Execute the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("delete vector store request failed: %w", err)
+
+	c.searchCache.Invalidate(vectorStoreID)
+	c.logger.InfoContext(ctx, "vector store deleted", "vector_store_id", vectorStoreID)
+	return nil
+}
+
+// SearchVectorStoreParams defines parameters for searching a vector store
+type SearchVectorStoreParams struct {
+	Query          string                 `json:"query"`
+	MaxNumResults  int                    `json:"max_num_results,omitempty"`
+	Filters        map[string]interface{} `json:"filters,omitempty"`
+	RankingOptions *RankingOptions        `json:"ranking_options,omitempty"`
+	RewriteQuery   bool                   `json:"rewrite_query,omitempty"`
+}
+
+// VectorStoreSearchResult represents a chunk returned by a vector store search
+type VectorStoreSearchResult struct {
+	FileID     string                     `json:"file_id"`
+	FileName   string                     `json:"filename"`
+	Score      float64                    `json:"score"`
+	Attributes map[string]interface{}     `json:"attributes,omitempty"`
+	Content    []VectorStoreSearchContent `json:"content"`
+}
+
+// VectorStoreSearchContent holds the text of a search result chunk
+type VectorStoreSearchContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// SearchVectorStore searches a vector store for the chunks most relevant to a query
+func (c *Client) SearchVectorStore(ctx context.Context, vectorStoreID string, params *SearchVectorStoreParams) ([]VectorStoreSearchResult, error) {
+	// Serve repeated searches from the cache
+	cacheKey, cacheable := "", false
+	if c.searchCache != nil {
+		cacheKey, cacheable = searchCacheKey(vectorStoreID, params)
+		if cacheable {
+			if results, ok := c.searchCache.get(cacheKey, c.clock.Now()); ok {
+				return results, nil
+			}
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("delete vector store failed with status %s: %s", resp.Status, string(body))
+	var searchResp struct {
+		Data []VectorStoreSearchResult `json:"data"`
+	}
+	if err := c.do(ctx, "POST", "/vector_stores/"+vectorStoreID+"/search", params, &searchResp); err != nil {
+		return nil, fmt.Errorf("vector store search failed: %w", err)
 	}
 
-	fmt.Printf("Vector store with ID %s deleted successfully\n", vectorStoreID)
-	return nil
+	if cacheable {
+		c.searchCache.put(cacheKey, vectorStoreID, searchResp.Data, c.clock.Now())
+	}
+	return searchResp.Data, nil
 }
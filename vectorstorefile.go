@@ -1,12 +1,9 @@
 package openai
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"io/ioutil"
-	"net/http"
 )
 
 type ErrorResponse struct {
@@ -27,69 +24,58 @@ func (e *UnsupportedFileTypeError) Error() string {
 	return fmt.Sprintf("unsupported file type for file: %s", e.FileName)
 }
 
+// Is makes errors.Is(err, ErrUnsupportedFile) hold for UnsupportedFileTypeError
+func (e *UnsupportedFileTypeError) Is(target error) bool {
+	return target == ErrUnsupportedFile
+}
+
 // VectorStoreFile represents the response for attaching a file to a vector store
 type VectorStoreFile struct {
 	ID               string                  `json:"id"`
 	Object           string                  `json:"object"`
 	UsageBytes       int64                   `json:"usage_bytes"`
-	CreatedAt        int64                   `json:"created_at"`
+	CreatedAt        Timestamp               `json:"created_at"`
 	VectorStoreID    string                  `json:"vector_store_id"`
 	Status           string                  `json:"status"`
 	LastError        *map[string]interface{} `json:"last_error,omitempty"`
 	ChunkingStrategy map[string]interface{}  `json:"chunking_strategy,omitempty"`
+	Attributes       map[string]interface{}  `json:"attributes,omitempty"`
+}
+
+// IsTerminal reports whether the vector store is done processing the file:
+// completed, failed or cancelled
+func (f *VectorStoreFile) IsTerminal() bool {
+	return f.Status != "in_progress"
 }
 
 // CreateVectorStoreFile attaches a file to a vector store
-func CreateVectorStoreFile(vectorStoreID, fileID string, chunkingStrategy map[string]interface{}) (*VectorStoreFile, error) {
+func (c *Client) CreateVectorStoreFile(ctx context.Context, vectorStoreID, fileID string, chunkingStrategy map[string]interface{}) (*VectorStoreFile, error) {
+	return c.createVectorStoreFile(ctx, vectorStoreID, fileID, chunkingStrategy, nil)
+}
+
+// createVectorStoreFile attaches a file to a vector store, tagging it with the given attributes
+func (c *Client) createVectorStoreFile(ctx context.Context, vectorStoreID, fileID string, chunkingStrategy map[string]interface{}, attributes map[string]interface{}) (*VectorStoreFile, error) {
 	// Prepare payload for attaching file
 	payload := map[string]interface{}{
-		"file_id":           fileID,
-		"chunking_strategy": chunkingStrategy,
+		"file_id": fileID,
 	}
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal vector store file payload: %w", err)
-	}
-
-	// Set up request to attach file to vector store
-	url := fmt.Sprintf("https://api.openai.com/v1/vector_stores/%s/files", vectorStoreID)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadBytes))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create vector store file request: %w", err)
+	if chunkingStrategy != nil {
+		payload["chunking_strategy"] = chunkingStrategy
 	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("vector store file request failed: %w", err)
+	if len(attributes) > 0 {
+		payload["attributes"] = attributes
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		var errorResp ErrorResponse
-		body, _ := io.ReadAll(resp.Body)
-
-		if err := json.Unmarshal(body, &errorResp); err != nil {
-			return nil, fmt.Errorf("vector store file creation failed with status %s: %s", resp.Status, string(body))
-		}
-
-		if errorResp.Error.Code == "unsupported_file" {
+	var vectorStoreFileResp VectorStoreFile
+	if err := c.do(ctx, "POST", "/vector_stores/"+vectorStoreID+"/files", payload, &vectorStoreFileResp); err != nil {
+		if errors.Is(err, ErrUnsupportedFile) {
 			return nil, &UnsupportedFileTypeError{FileName: fileID}
 		}
-
-		return nil, fmt.Errorf("vector store file creation failed: %s", errorResp.Error.Message)
+		return nil, fmt.Errorf("vector store file creation failed: %w", err)
 	}
 
-	// Decode response to get file attachment details
-	var vectorStoreFileResp VectorStoreFile
-	if err := json.NewDecoder(resp.Body).Decode(&vectorStoreFileResp); err != nil {
-		return nil, fmt.Errorf("failed to decode vector store file response: %w", err)
-	}
-
-	fmt.Printf("File attached successfully to vector store with ID: %s\n", vectorStoreFileResp.ID)
+	c.searchCache.Invalidate(vectorStoreID)
+	c.logger.InfoContext(ctx, "file attached to vector store", "vector_store_id", vectorStoreID, "file_id", vectorStoreFileResp.ID)
 	return &vectorStoreFileResp, nil
 }
 
@@ -98,105 +84,32 @@ type VectorStoreFileListResponse struct {
 	Data []VectorStoreFile `json:"data"`
 }
 
-// ListVectorStoreFiles lists files attached to a specific vector store
-func ListVectorStoreFiles(vectorStoreID string) ([]VectorStoreFile, error) {
-	// Build the request URL
-	url := fmt.Sprintf("https://api.openai.com/v1/vector_stores/%s/files?limit=100", vectorStoreID)
-
-	// Create the request
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create list vector store files request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
-
-	// Execute the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// ListVectorStoreFiles lists files attached to a specific vector store, 100 at
+// most unless opts set another limit
+func (c *Client) ListVectorStoreFiles(ctx context.Context, vectorStoreID string, opts ...ListOption) ([]VectorStoreFile, error) {
+	page, err := c.ListVectorStoreFilesPage(ctx, vectorStoreID, append([]ListOption{WithLimit(100)}, opts...)...)
 	if err != nil {
-		return nil, fmt.Errorf("list vector store files request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("list vector store files failed with status %s: %s", resp.Status, string(body))
+		return nil, err
 	}
-
-	// Parse the response
-	var vectorStoreFileList VectorStoreFileListResponse
-	if err := json.NewDecoder(resp.Body).Decode(&vectorStoreFileList); err != nil {
-		return nil, fmt.Errorf("failed to decode list vector store files response: %w", err)
-	}
-
-	return vectorStoreFileList.Data, nil
+	return page.Data, nil
 }
 
 // RetrieveVectorStoreFile retrieves details of a specific file attached to a vector store
-func RetrieveVectorStoreFile(vectorStoreID, fileID string) (*VectorStoreFile, error) {
-	// Build the request URL
-	url := fmt.Sprintf("https://api.openai.com/v1/vector_stores/%s/files/%s", vectorStoreID, fileID)
-
-	// Create the request
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create retrieve vector store file request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
-
-	// Execute the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("retrieve vector store file request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("retrieve vector store file failed with status %s: %s", resp.Status, string(body))
-	}
-
-	// Parse the response
+func (c *Client) RetrieveVectorStoreFile(ctx context.Context, vectorStoreID, fileID string) (*VectorStoreFile, error) {
 	var vectorStoreFile VectorStoreFile
-	if err := json.NewDecoder(resp.Body).Decode(&vectorStoreFile); err != nil {
-		return nil, fmt.Errorf("failed to decode retrieve vector store file response: %w", err)
+	if err := c.do(ctx, "GET", "/vector_stores/"+vectorStoreID+"/files/"+fileID, nil, &vectorStoreFile); err != nil {
+		return nil, fmt.Errorf("retrieve vector store file failed: %w", err)
 	}
-
 	return &vectorStoreFile, nil
 }
 
 // DeleteVectorStoreFile deletes a specific file from a vector store
-func DeleteVectorStoreFile(vectorStoreID, fileID string) error {
-	// Build the request URL
-	url := fmt.Sprintf("https://api.openai.com/v1/vector_stores/%s/files/%s", vectorStoreID, fileID)
-
-	// Create the request
-	req, err := http.NewRequest("DELETE", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create delete vector store file request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+openaiAPIKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("OpenAI-Beta", "assistants=v2")
-
-	// Execute the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("delete vector store file request failed: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return fmt.Errorf("delete vector store file failed with status %s: %s", resp.Status, string(body))
+func (c *Client) DeleteVectorStoreFile(ctx context.Context, vectorStoreID, fileID string) error {
+	if err := c.do(ctx, "DELETE", "/vector_stores/"+vectorStoreID+"/files/"+fileID, nil, nil); err != nil {
+		return fmt.Errorf("delete vector store file failed: %w", err)
 	}
 
-	fmt.Printf("File with ID %s deleted successfully from vector store %s\n", fileID, vectorStoreID)
+	c.searchCache.Invalidate(vectorStoreID)
+	c.logger.InfoContext(ctx, "file removed from vector store", "vector_store_id", vectorStoreID, "file_id", fileID)
 	return nil
 }
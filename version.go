@@ -0,0 +1,14 @@
+package openai
+
+import "runtime"
+
+// version is the version of this package, updated on each release
+const version = "0.1.0"
+
+// userAgent identifies the client to the API and to gateways
+var userAgent = "go-openai/" + version + " (github.com/bhirbec/go-openai; " + runtime.Version() + ")"
+
+// Version returns the version of this package
+func Version() string {
+	return version
+}
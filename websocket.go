@@ -0,0 +1,155 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// WebSocketConn is the side of a WebSocket connection used by WebSocketRelay,
+// so any WebSocket library can be adapted, e.g. with coder/websocket:
+//
+//	func (a adapter) WriteText(ctx context.Context, data []byte) error {
+//		return a.conn.Write(ctx, websocket.MessageText, data)
+//	}
+//
+//	func (a adapter) Ping(ctx context.Context) error {
+//		return a.conn.Ping(ctx)
+//	}
+//
+// The relay never calls WriteText and Ping concurrently.
+type WebSocketConn interface {
+	// WriteText sends a text message, returning once it is written or ctx is done
+	WriteText(ctx context.Context, data []byte) error
+
+	// Ping sends a ping, returning once the pong is received or ctx is done.
+	// The caller must keep reading the connection for the pong to arrive.
+	Ping(ctx context.Context) error
+}
+
+// WebSocketRelay relays streams over a WebSocket connection, one JSON text
+// message per chunk followed by a "[DONE]" message, for clients that don't
+// use SSE. Chunks are queued up to Buffer messages ahead of the socket; when
+// the queue is full, the upstream stream is not read until the client catches
+// up.
+type WebSocketRelay struct {
+	conn WebSocketConn
+
+	Buffer       int           // Messages queued ahead of the socket, 16 when 0
+	WriteTimeout time.Duration // Bounds each write, so a client that stopped reading fails the stream. 0 means no limit.
+	PingInterval time.Duration // Time between keepalive pings while the stream is idle, 30s when 0
+	PongTimeout  time.Duration // Longest wait for a pong before failing the stream, 10s when 0
+}
+
+// NewWebSocketRelay returns a relay writing to conn
+func NewWebSocketRelay(conn WebSocketConn) *WebSocketRelay {
+	return &WebSocketRelay{conn: conn}
+}
+
+// RelayChatStream streams a chat completion over the WebSocket of relay. The
+// upstream call is cancelled as soon as ctx is done, a write fails or a pong
+// is missed. The connection is left open for the caller to close, with a
+// status reflecting the returned error. Usage is recorded like with
+// ServeChatStream.
+func (c *Client) RelayChatStream(ctx context.Context, relay *WebSocketRelay, req openai.ChatCompletionRequest) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	buffer := relay.Buffer
+	if buffer <= 0 {
+		buffer = 16
+	}
+	messages := make(chan []byte, buffer)
+	written := make(chan error, 1)
+	go func() {
+		err := relay.writeLoop(ctx, messages)
+		if err != nil {
+			cancel(err)
+		}
+		written <- err
+	}()
+
+	send := func(message []byte) error {
+		select {
+		case messages <- message:
+			return nil
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+	}
+	err := c.streamChat(ctx, req, func(chunk openai.ChatCompletionStreamResponse) error {
+		payload, err := json.Marshal(chunk)
+		if err != nil {
+			return fmt.Errorf("failed to encode stream event: %w", err)
+		}
+		return send(payload)
+	})
+	if err == nil {
+		err = send([]byte("[DONE]"))
+	}
+	close(messages)
+
+	// A failed write is the cause of the upstream error, report it instead
+	if writeErr := <-written; writeErr != nil {
+		return fmt.Errorf("failed to write chat stream: %w", writeErr)
+	}
+	return err
+}
+
+// writeLoop writes the queued messages until the queue is closed, pinging the
+// client while no message is sent
+func (r *WebSocketRelay) writeLoop(ctx context.Context, messages <-chan []byte) error {
+	interval := r.PingInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	pongTimeout := r.PongTimeout
+	if pongTimeout <= 0 {
+		pongTimeout = 10 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			if err := r.write(ctx, message); err != nil {
+				return err
+			}
+			ticker.Reset(interval)
+		case <-ticker.C:
+			pingCtx, cancel := context.WithTimeout(ctx, pongTimeout)
+			err := r.conn.Ping(pingCtx)
+			cancel()
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("websocket keepalive failed: %w", err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (r *WebSocketRelay) write(ctx context.Context, message []byte) error {
+	writeCtx := ctx
+	if r.WriteTimeout > 0 {
+		var cancel context.CancelFunc
+		writeCtx, cancel = context.WithTimeout(ctx, r.WriteTimeout)
+		defer cancel()
+	}
+	err := r.conn.WriteText(writeCtx, message)
+	if err != nil && ctx.Err() != nil {
+		// Cancelled by the caller, not a failure of the socket
+		return nil
+	}
+	return err
+}